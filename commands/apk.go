@@ -0,0 +1,79 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import "github.com/juju/packaging/v3"
+
+const (
+	// ApkRepositoriesFile is the file which lists all the repositories
+	// apk installs packages from.
+	ApkRepositoriesFile = "/etc/apk/repositories"
+
+	// ApkKeysDir is the directory in which the keys of apk repositories
+	// are located.
+	ApkKeysDir = "/etc/apk/keys"
+
+	// ApkProxyFilePath is the file in which the proxy environment variables
+	// honoured by apk are persisted.
+	ApkProxyFilePath = "/etc/profile.d/apk-proxy.sh"
+)
+
+const (
+	// the basic command for all apk calls
+	//		--no-progress to not clutter the output with progress bars
+	apk = "apk --no-progress"
+
+	// the basic format for specifying a proxy setting for apk, which only
+	// honours the proxy environment variables. The values are quoted for
+	// the shell which sources the file.
+	apkProxySettingFormat = "export %s_proxy=%s"
+
+	// the format for specifying the hosts reached without a proxy, as
+	// honoured by apk, separated by commas.
+	apkNoProxySettingFormat = "export no_proxy=%s"
+)
+
+// apkCmder is the packageCommander instantiation for apk-based systems.
+var apkCmder = packageCommander{
+	prereq:              makeNopCmd(),
+	update:              buildCommand(apk, "update"),
+	upgrade:             buildCommand(apk, "upgrade"),
+	install:             buildCommand(apk, "add"),
+	remove:              buildCommand(apk, "del"),
+	purge:               buildCommand(apk, "del --purge"),
+	search:              buildCommand(apk, "search --exact %s"),
+	isInstalled:         buildCommand(apk, "info --installed %s"),
+	packageVersion:      buildCommand(apk, "list --installed %s"),
+	candidateVersion:    buildCommand(apk, "policy %s"),
+	listAvailable:       buildCommand(apk, "list --available"),
+	listInstalled:       buildCommand(apk, "list --installed"),
+	listRepositories:    buildCommand(`grep -v -e "^#" -e "^$"`, ApkRepositoriesFile),
+	addRepository:       buildCommand("echo %s >>", ApkRepositoriesFile),
+	removeRepository:    buildCommand(`sed -i "\|^%s$|d"`, ApkRepositoriesFile),
+	cleanup:             buildCommand(apk, "cache clean"),
+	getProxy:            buildCommand("grep -R \".*_proxy=\"", ApkProxyFilePath),
+	proxySettingsFormat: apkProxySettingFormat,
+	noProxyListFormat:   apkNoProxySettingFormat,
+	quoteProxy:          ShellQuote,
+	setProxy:            buildCommand("echo %s >>", ApkProxyFilePath),
+	proxyFile:           ApkProxyFilePath,
+	renderSpec:          renderApkSpec,
+	validatePackage:     validateApkPackage,
+	validateAddRepo:     validateApkRepository,
+	validateRemoveRepo:  validateApkRepository,
+}
+
+// renderApkSpec renders a package spec in the name[@tag][=version] syntax
+// of apk, where the target release is the tag of the repository to install
+// the package from.
+func renderApkSpec(spec packaging.PackageSpec) []string {
+	pack := spec.Name
+	if spec.TargetRelease != "" {
+		pack += "@" + spec.TargetRelease
+	}
+	if spec.Version != "" {
+		pack += "=" + spec.Version
+	}
+	return []string{pack}
+}
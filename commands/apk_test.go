@@ -0,0 +1,66 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands_test
+
+import (
+	"github.com/juju/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+)
+
+var _ = gc.Suite(&ApkSuite{})
+
+type ApkSuite struct {
+	paccmder commands.PackageCommander
+}
+
+func (s *ApkSuite) SetUpSuite(c *gc.C) {
+	s.paccmder = commands.NewApkPackageCommander()
+}
+
+func (s *ApkSuite) TestProxyConfigContents(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		Https:   "https://much-security.com",
+		NoProxy: "localhost,.example.com",
+	}
+	expected := `export http_proxy=dat-proxy.zone:8080
+export https_proxy=https://much-security.com
+export no_proxy=localhost,.example.com`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *ApkSuite) TestProxyConfigContentsQuotesValues(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "http://proxy.local:3128/$(reboot)",
+		NoProxy: "localhost;reboot",
+	}
+	expected := `export http_proxy='http://proxy.local:3128/$(reboot)'
+export no_proxy='localhost;reboot'`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *ApkSuite) TestRepositoryCmds(c *gc.C) {
+	const repo = "https://dl-cdn.alpinelinux.org/alpine/v3.18/community"
+	c.Assert(s.paccmder.AddRepositoryCmd(repo), gc.Equals,
+		"echo "+repo+" >> /etc/apk/repositories")
+	c.Assert(s.paccmder.RemoveRepositoryCmd(repo), gc.Equals,
+		`sed -i "\|^`+repo+`$|d" /etc/apk/repositories`)
+}
+
+func (s *ApkSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "curl", Version: "8.4.0-r0"},
+		{Name: "lxd", TargetRelease: "testing"},
+		{Name: "lxc", TargetRelease: "edge", Version: "5.0.3-r1"},
+	}...)
+	c.Assert(cmd, gc.Equals, "apk --no-progress add bash curl=8.4.0-r0 lxd@testing lxc@edge=5.0.3-r1")
+}
@@ -4,7 +4,10 @@
 
 package commands
 
-import "github.com/juju/packaging/v3/config"
+import (
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+)
 
 // Constants for apt-based basic commands
 const (
@@ -58,8 +61,11 @@ var aptCmder = packageCommander{
 	purge:                 buildCommand(aptget, "purge"),
 	search:                buildCommand(aptcache, "search --names-only ^%s$"),
 	isInstalled:           buildCommand(dpkgquery, "-s %s"),
+	packageVersion:        buildCommand(dpkgquery, "--status %s"),
+	candidateVersion:      buildCommand(aptcache, "policy %s"),
 	listAvailable:         buildCommand(aptcache, "pkgnames"),
 	listInstalled:         buildCommand(dpkg, "--get-selections"),
+	listInstalledVersions: buildCommand(dpkgquery, "--list"),
 	addRepository:         buildCommand(addaptrepo, "%q"),
 	listRepositories:      buildCommand(`sed -r -n "s|^deb(-src)? (.*)|\2|p"`, "/etc/apt/sources.list"),
 	removeRepository:      buildCommand(addaptrepo, "--remove ppa:%s"),
@@ -69,6 +75,8 @@ var aptCmder = packageCommander{
 	setProxy:              buildCommand("echo %s >> ", AptConfFilePath),
 	noProxySettingsFormat: aptNoProxySettingFormat,
 	setNoProxy:            buildCommand("echo %s >> ", AptConfFilePath),
+	proxyFile:             AptConfFilePath,
+	noProxyHost:           aptNoProxyHost,
 	setMirrorCommands: func(newArchiveMirror, newSecurityMirror string) []string {
 		var cmds []string
 		if newArchiveMirror != "" {
@@ -85,6 +93,27 @@ var aptCmder = packageCommander{
 		}
 		return cmds
 	},
+	renderSpec:         renderAptSpec,
+	validatePackage:    ValidateDebianPackage,
+	validateAddRepo:    ValidateAptRepository,
+	validateRemoveRepo: ValidatePPA,
+}
+
+// renderAptSpec renders a package spec in the name[:arch][=version] syntax
+// of apt-get. When no version is given, a target release is requested with
+// the name[:arch]/release syntax instead.
+func renderAptSpec(spec packaging.PackageSpec) []string {
+	pack := spec.Name
+	if spec.Architecture != "" {
+		pack += ":" + spec.Architecture
+	}
+	switch {
+	case spec.Version != "":
+		pack += "=" + spec.Version
+	case spec.TargetRelease != "":
+		pack += "/" + spec.TargetRelease
+	}
+	return []string{pack}
 }
 
 // renameAptListFilesCommands takes a new and old mirror string,
@@ -10,6 +10,7 @@ import (
 	"github.com/juju/proxy"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 )
 
@@ -58,6 +59,29 @@ Acquire::ftp::Proxy::"local2" "DIRECT";`
 	c.Assert(output, gc.Equals, expected)
 }
 
+func (s *AptSuite) TestProxyConfigContentsNoProxyExactHostsOnly(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		NoProxy: "local1,.example.com,*.example.com,10.0.0.0/8,10.0.0.1",
+	}
+	expected := `Acquire::http::Proxy "dat-proxy.zone:8080";
+Acquire::http::Proxy::"local1" "DIRECT";
+Acquire::https::Proxy::"local1" "DIRECT";
+Acquire::ftp::Proxy::"local1" "DIRECT";
+Acquire::http::Proxy::"10.0.0.1" "DIRECT";
+Acquire::https::Proxy::"10.0.0.1" "DIRECT";
+Acquire::ftp::Proxy::"10.0.0.1" "DIRECT";`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *AptSuite) TestListInstalled(c *gc.C) {
+	c.Assert(s.paccmder.ListInstalledCmd(), gc.Equals, "dpkg --get-selections")
+	// The Commander lists the versions of the packages as well.
+	c.Assert(commands.NewAptCommander().ListInstalled().Argv(), gc.DeepEquals, []string{"dpkg-query", "--list"})
+}
+
 func (s *AptSuite) TestSetMirrorCommands(c *gc.C) {
 	expected := `
 old_archive_mirror=$(awk "/^deb .* $(awk -F= '/DISTRIB_CODENAME=/ {gsub(/"/,""); print $2}' /etc/lsb-release) .*main.*\$/{print \$2;exit}" /etc/apt/sources.list)
@@ -88,3 +112,13 @@ done`[1:]
 	output := strings.Join(cmds, "\n")
 	c.Assert(output, gc.Equals, expected)
 }
+
+func (s *AptSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "bash", Version: "5.0-6ubuntu1.2"},
+		{Name: "libc6", Architecture: "i386", Version: "2.31-0ubuntu9"},
+		{Name: "lxd", TargetRelease: "focal-backports"},
+	}...)
+	c.Assert(cmd, gc.Equals, "apt-get --option=Dpkg::Options::=--force-confold --option=Dpkg::Options::=--force-unsafe-io --assume-yes --quiet install bash bash=5.0-6ubuntu1.2 libc6:i386=2.31-0ubuntu9 lxd/focal-backports")
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Command is a command of a Commander, as an argument vector which is run
+// directly, without a shell, unless it RequiresShell.
+type Command struct {
+	// Args are the program to run and its arguments. For a command which
+	// RequiresShell, Args hold the shell script as their only element.
+	// A command without Args does nothing.
+	Args []string
+
+	// Env are environment variables, as "key=value" pairs, which are set
+	// for the command.
+	Env []string
+
+	// Stdin is the standard input of the command, which is empty if nil.
+	Stdin []byte
+
+	// RequiresShell is true for commands which are shell scripts, such as
+	// pipelines or redirections, rather than argument vectors.
+	RequiresShell bool
+}
+
+// IsNop returns whether the command does nothing, and so need not be run.
+func (c Command) IsNop() bool {
+	return len(c.Args) == 0
+}
+
+// Argv returns the argument vector which runs the command, which runs the
+// shell script of a command which RequiresShell with sh(1).
+func (c Command) Argv() []string {
+	if c.RequiresShell && len(c.Args) > 0 {
+		return []string{"sh", "-c", c.Args[0]}
+	}
+	return c.Args
+}
+
+// Render returns the command as a line of a POSIX shell script, such as a
+// cloud-init runcmd, with every argument and environment variable quoted,
+// so that the package names and other arguments of the command are never
+// interpreted by the shell. A command which does nothing is rendered as ":".
+func (c Command) Render() string {
+	if c.IsNop() {
+		return ":"
+	}
+	if c.RequiresShell && len(c.Env) == 0 && c.Stdin == nil {
+		return c.Args[0]
+	}
+
+	var words []string
+	for _, env := range c.Env {
+		key, value, _ := strings.Cut(env, "=")
+		words = append(words, key+"="+ShellQuote(value))
+	}
+	for _, arg := range c.Argv() {
+		words = append(words, ShellQuote(arg))
+	}
+	line := strings.Join(words, " ")
+	if c.Stdin != nil {
+		line = "printf '%s' " + ShellQuote(string(c.Stdin)) + " | " + line
+	}
+	return line
+}
+
+// String returns the arguments of the command separated by spaces, for
+// logging.
+func (c Command) String() string {
+	return strings.Join(c.Argv(), " ")
+}
+
+// shellSafe matches the words which need no quoting in a shell script.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote returns the given word quoted for a POSIX shell, if it needs
+// quoting.
+func ShellQuote(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// splitCommand splits the given command line, as written for a POSIX
+// shell, into its words, removing the quoting. It also returns whether the
+// line needs a shell to be run, as it contains pipelines, redirections,
+// lists, substitutions or comments outside of quotes.
+func splitCommand(line string) (words []string, requiresShell bool) {
+	var (
+		word    strings.Builder
+		inWord  bool
+		squoted bool
+		dquoted bool
+	)
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case squoted:
+			if ch == '\'' {
+				squoted = false
+			} else {
+				word.WriteByte(ch)
+			}
+		case dquoted:
+			switch {
+			case ch == '"':
+				dquoted = false
+			case ch == '\\' && i+1 < len(line) && strings.IndexByte("$`\"\\\n", line[i+1]) >= 0:
+				i++
+				word.WriteByte(line[i])
+			case ch == '`' || ch == '$' && isSubstitution(line[i+1:]):
+				requiresShell = true
+				word.WriteByte(ch)
+			default:
+				word.WriteByte(ch)
+			}
+		case ch == ' ' || ch == '\t':
+			endWord()
+		case ch == '\'':
+			squoted, inWord = true, true
+		case ch == '"':
+			dquoted, inWord = true, true
+		case ch == '\\' && i+1 < len(line):
+			i++
+			word.WriteByte(line[i])
+			inWord = true
+		case strings.IndexByte("|&;<>()`\n", ch) >= 0,
+			ch == '$' && isSubstitution(line[i+1:]),
+			ch == '#' && !inWord:
+			requiresShell = true
+			word.WriteByte(ch)
+			inWord = true
+		default:
+			word.WriteByte(ch)
+			inWord = true
+		}
+	}
+	endWord()
+	return words, requiresShell
+}
+
+// isSubstitution returns whether a "$" followed by the given text starts a
+// parameter, command or arithmetic substitution.
+func isSubstitution(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	ch := rest[0]
+	return ch == '{' || ch == '(' || ch == '_' || ch == '?' || ch == '@' || ch == '*' || ch == '#' ||
+		ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
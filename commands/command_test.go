@@ -0,0 +1,96 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands_test
+
+import (
+	"github.com/juju/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/commands"
+)
+
+var _ = gc.Suite(&CommandSuite{})
+
+type CommandSuite struct{}
+
+func (s *CommandSuite) TestShellQuote(c *gc.C) {
+	c.Check(commands.ShellQuote("bash=5.1-6"), gc.Equals, "bash=5.1-6")
+	c.Check(commands.ShellQuote("foo bar"), gc.Equals, "'foo bar'")
+	c.Check(commands.ShellQuote("it's"), gc.Equals, `'it'\''s'`)
+	c.Check(commands.ShellQuote(""), gc.Equals, "''")
+}
+
+func (s *CommandSuite) TestArgv(c *gc.C) {
+	cmd := commands.Command{Args: []string{"apt-get", "install", "bash"}}
+	c.Check(cmd.Argv(), gc.DeepEquals, []string{"apt-get", "install", "bash"})
+	c.Check(cmd.String(), gc.Equals, "apt-get install bash")
+	c.Check(cmd.IsNop(), gc.Equals, false)
+
+	cmd = commands.Command{Args: []string{"echo foo >> /etc/foo"}, RequiresShell: true}
+	c.Check(cmd.Argv(), gc.DeepEquals, []string{"sh", "-c", "echo foo >> /etc/foo"})
+
+	c.Check(commands.Command{}.IsNop(), gc.Equals, true)
+}
+
+func (s *CommandSuite) TestRender(c *gc.C) {
+	c.Check(commands.Command{}.Render(), gc.Equals, ":")
+	c.Check(commands.Command{
+		Args: []string{"apt-get", "install", "foo; rm -rf /"},
+		Env:  []string{"DEBIAN_FRONTEND=noninteractive", "FOO=foo bar"},
+	}.Render(), gc.Equals, `DEBIAN_FRONTEND=noninteractive FOO='foo bar' apt-get install 'foo; rm -rf /'`)
+	c.Check(commands.Command{
+		Args:  []string{"tee", "-a", "/etc/yum.conf"},
+		Stdin: []byte("proxy=http://proxy\n"),
+	}.Render(), gc.Equals, "printf '%s' 'proxy=http://proxy\n' | tee -a /etc/yum.conf")
+	c.Check(commands.Command{
+		Args:          []string{"echo foo >> /etc/foo"},
+		RequiresShell: true,
+	}.Render(), gc.Equals, "echo foo >> /etc/foo")
+}
+
+func (s *CommandSuite) TestArgumentsAreNotSplit(c *gc.C) {
+	apt := commands.NewAptCommander()
+	argv := apt.Install("foo bar", "baz;qux").Argv()
+	c.Check(argv[len(argv)-2:], gc.DeepEquals, []string{"foo bar", "baz;qux"})
+	c.Check(apt.AddRepository("deb http://archive.ubuntu.com/ubuntu jammy main").Argv(), gc.DeepEquals,
+		[]string{"add-apt-repository", "--yes", "deb http://archive.ubuntu.com/ubuntu jammy main"})
+	c.Check(apt.Search("foo$(reboot)").Argv(), gc.DeepEquals,
+		[]string{"apt-cache", "search", "--names-only", "^foo$(reboot)$"})
+}
+
+func (s *CommandSuite) TestQuotedTemplates(c *gc.C) {
+	c.Check(commands.NewYumCommander().GetProxy().Argv(), gc.DeepEquals,
+		[]string{"grep", "-R", ".*_proxy=", "/etc/yum.conf"})
+	c.Check(commands.NewSnapCommander().AddRepository("foo").IsNop(), gc.Equals, true)
+}
+
+func (s *CommandSuite) TestShellTemplatesQuoteArguments(c *gc.C) {
+	cmd := commands.NewApkCommander().AddRepository("http://example.com/alpine; reboot")
+	c.Check(cmd.RequiresShell, gc.Equals, true)
+	c.Check(cmd.Args, gc.DeepEquals,
+		[]string{"echo 'http://example.com/alpine; reboot' >> " + commands.ApkRepositoriesFile})
+}
+
+func (s *CommandSuite) TestSetProxy(c *gc.C) {
+	settings := proxy.Settings{Http: "http://proxy:3128"}
+
+	// The configuration files of these are written by the proxyconfig
+	// package.
+	c.Check(commands.NewYumCommander().SetProxy(settings), gc.HasLen, 0)
+	c.Check(commands.NewZypperCommander().SetProxy(settings), gc.HasLen, 0)
+
+	cmds := commands.NewSnapCommander().SetProxy(settings)
+	c.Assert(cmds, gc.HasLen, 1)
+	c.Check(cmds[0].Argv(), gc.DeepEquals, []string{"snap", "set", "system", "proxy.http=http://proxy:3128"})
+
+	c.Check(commands.NewAptCommander().SetProxy(proxy.Settings{}), gc.HasLen, 0)
+}
+
+func (s *CommandSuite) TestSetMirrors(c *gc.C) {
+	cmd := commands.NewAptCommander().SetMirrors("http://mirror/ubuntu", "")
+	c.Check(cmd.RequiresShell, gc.Equals, true)
+	c.Check(cmd.Args[0], gc.Matches, `(?s).*http://mirror/ubuntu.*`)
+
+	c.Check(commands.NewSnapCommander().SetMirrors("http://mirror", "").IsNop(), gc.Equals, true)
+}
@@ -9,33 +9,46 @@ import (
 	"strings"
 
 	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3"
 )
 
 // packageCommander is a struct which returns system-specific commands for all
 // the operations that may be required of a package management system.
 // It implements the PackageCommander interface.
 type packageCommander struct {
-	prereq                string                        // installs prerequisite repo management package
-	update                string                        // updates the local package list
-	upgrade               string                        // upgrades all packages
-	install               string                        // installs the given packages
-	remove                string                        // removes the given packages
-	purge                 string                        // removes the given packages along with all data
-	search                string                        // searches for the given package
-	isInstalled           string                        // checks if a given package is installed
-	listAvailable         string                        // lists all packes available
-	listInstalled         string                        // lists all installed packages
-	listRepositories      string                        // lists all currently configured repositories
-	addRepository         string                        // adds the given repository
-	removeRepository      string                        // removes the given repository
-	cleanup               string                        // cleans up orhaned packages and the package cache
-	getProxy              string                        // command for getting the currently set packagemanager proxy
-	proxySettingsFormat   string                        // format for proxy setting in package manager config file
-	setProxy              string                        // command for adding a proxy setting to the config file
-	setNoProxy            string                        // command for adding a no-proxy setting to the config file
-	noProxySettingsFormat string                        // format for no-proxy setting in package manager config file
-	proxyLabelInCapital   bool                          // true: proxy labels are in capital letter (e.g. HTTP_PROXY)
-	setMirrorCommands     func(string, string) []string // updates archive and security package manager to use the given mirrors
+	prereq                string                               // installs prerequisite repo management package
+	update                string                               // updates the local package list
+	upgrade               string                               // upgrades all packages
+	install               string                               // installs the given packages
+	remove                string                               // removes the given packages
+	purge                 string                               // removes the given packages along with all data
+	search                string                               // searches for the given package
+	isInstalled           string                               // checks if a given package is installed
+	packageVersion        string                               // outputs the installed version of a given package
+	candidateVersion      string                               // outputs the candidate version of a given package
+	listAvailable         string                               // lists all packes available
+	listInstalled         string                               // lists all installed packages
+	listInstalledVersions string                               // lists all installed packages along with their versions for ListInstalled, if listInstalled does not
+	listRepositories      string                               // lists all currently configured repositories
+	addRepository         string                               // adds the given repository
+	removeRepository      string                               // removes the given repository
+	cleanup               string                               // cleans up orhaned packages and the package cache
+	getProxy              string                               // command for getting the currently set packagemanager proxy
+	proxySettingsFormat   string                               // format for proxy setting in package manager config file
+	setProxy              string                               // command for adding a proxy setting to the config file
+	setNoProxy            string                               // command for adding a no-proxy setting to the config file
+	noProxySettingsFormat string                               // format for no-proxy setting per protocol and host in package manager config file
+	noProxyListFormat     string                               // format for a single no-proxy setting listing all hosts, separated by commas
+	noProxyHost           func(string) (string, bool)          // translates a no-proxy host into the package manager syntax, if it can be expressed
+	quoteProxy            func(string) string                  // quotes the proxy URLs and no-proxy list written with the formats, if they need it
+	proxyLabelInCapital   bool                                 // true: proxy labels are in capital letter (e.g. HTTP_PROXY)
+	setMirrorCommands     func(string, string) []string        // updates archive and security package manager to use the given mirrors
+	renderSpec            func(packaging.PackageSpec) []string // renders a package spec as install command arguments
+	proxyFile             string                               // config file holding the proxy settings, written by the proxyconfig package rather than by SetProxy, if any
+	validatePackage       func(string) error                   // validates the package names given to the commands
+	validateAddRepo       func(string) error                   // validates the repositories given to AddRepository, if it takes any
+	validateRemoveRepo    func(string) error                   // validates the repositories given to RemoveRepository, if it takes any
 }
 
 // InstallPrerequisiteCmd is defined on the PackageCommander interface.
@@ -58,6 +71,19 @@ func (p *packageCommander) InstallCmd(packs ...string) string {
 	return addArgsToCommand(p.install, packs)
 }
 
+// InstallSpecCmd is defined on the PackageCommander interface.
+func (p *packageCommander) InstallSpecCmd(specs ...packaging.PackageSpec) string {
+	packs := make([]string, len(specs))
+	for i, spec := range specs {
+		if p.renderSpec == nil {
+			packs[i] = spec.Name
+			continue
+		}
+		packs[i] = strings.Join(p.renderSpec(spec), " ")
+	}
+	return addArgsToCommand(p.install, packs)
+}
+
 // RemoveCmd is defined on the PackageCommander interface.
 func (p *packageCommander) RemoveCmd(packs ...string) string {
 	return addArgsToCommand(p.remove, packs)
@@ -78,6 +104,16 @@ func (p *packageCommander) IsInstalledCmd(pack string) string {
 	return fmt.Sprintf(p.isInstalled, pack)
 }
 
+// PackageVersionCmd is defined on the PackageCommander interface.
+func (p *packageCommander) PackageVersionCmd(pack string) string {
+	return fmt.Sprintf(p.packageVersion, pack)
+}
+
+// CandidateVersionCmd is defined on the PackageCommander interface.
+func (p *packageCommander) CandidateVersionCmd(pack string) string {
+	return fmt.Sprintf(p.candidateVersion, pack)
+}
+
 // ListAvailableCmd is defined on the PackageCommander interface.
 func (p *packageCommander) ListAvailableCmd() string {
 	return p.listAvailable
@@ -116,7 +152,16 @@ func (p *packageCommander) GetProxyCmd() string {
 // giveProxyOptions is a helper function which takes a possible proxy setting
 // and its value and returns the formatted option for it.
 func (p *packageCommander) giveProxyOption(setting, proxy string) string {
-	return fmt.Sprintf(p.proxySettingsFormat, setting, proxy)
+	return fmt.Sprintf(p.proxySettingsFormat, setting, p.quote(proxy))
+}
+
+// quote returns the given proxy URL or no-proxy list quoted for the
+// configuration file of the package manager.
+func (p *packageCommander) quote(value string) string {
+	if p.quoteProxy == nil {
+		return value
+	}
+	return p.quoteProxy(value)
 }
 
 // giveNoProxyOptions is a helper function which takes protocol and hostname
@@ -129,9 +174,18 @@ func (p *packageCommander) proxyConfigLines(settings proxy.Settings) []string {
 	options := []string{}
 
 	addOption := func(setting, proxy string) {
-		if proxy != "" {
-			options = append(options, p.giveProxyOption(setting, proxy))
+		if proxy == "" {
+			return
+		}
+		// Package managers which use a single proxy for all protocols
+		// would otherwise get the same option repeatedly.
+		option := p.giveProxyOption(setting, proxy)
+		for _, existing := range options {
+			if existing == option {
+				return
+			}
 		}
+		options = append(options, option)
 	}
 
 	// OpenSUSE uses proxy labels in capital letter (e.g HTTP_PROXY)
@@ -154,18 +208,36 @@ func (p *packageCommander) proxyConfigLines(settings proxy.Settings) []string {
 		options = append(options, p.giveNoProxyOption(protocol, host))
 	}
 
+	hosts := p.noProxyHosts(settings.NoProxy)
 	if p.noProxySettingsFormat != "" {
-		for _, host := range strings.Split(settings.NoProxy, ",") {
-			if host != "" {
-				addNoProxyCmd(http_label, host)
-				addNoProxyCmd(https_label, host)
-				addNoProxyCmd(ftp_label, host)
-			}
+		for _, host := range hosts {
+			addNoProxyCmd(http_label, host)
+			addNoProxyCmd(https_label, host)
+			addNoProxyCmd(ftp_label, host)
 		}
 	}
+	if p.noProxyListFormat != "" && len(hosts) > 0 {
+		options = append(options, fmt.Sprintf(p.noProxyListFormat, p.quote(strings.Join(hosts, ","))))
+	}
 	return options
 }
 
+// noProxyHosts returns the hosts of the given NoProxy setting which can be
+// expressed by the package manager, translated into its syntax.
+func (p *packageCommander) noProxyHosts(noProxy string) []string {
+	var hosts []string
+	for _, host := range NoProxyHosts(noProxy) {
+		if p.noProxyHost != nil {
+			var ok bool
+			if host, ok = p.noProxyHost(host); !ok {
+				continue
+			}
+		}
+		hosts = append(hosts, host)
+	}
+	return NoProxyHosts(strings.Join(hosts, ","))
+}
+
 // ProxyConfigContents is defined on the PackageCommander interface.
 func (p *packageCommander) ProxyConfigContents(settings proxy.Settings) string {
 	return strings.Join(p.proxyConfigLines(settings), "\n")
@@ -0,0 +1,254 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"strings"
+
+	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3"
+)
+
+// command returns the Command of the given command line template of the
+// commander, whose "%s" and "%q" placeholders are replaced by the given
+// arguments in turn. The extra arguments are appended to the command.
+//
+// The template is split into its arguments before the placeholders are
+// replaced, so that each argument remains a single argument whatever its
+// contents. Templates which are shell scripts give commands which
+// RequiresShell, with the arguments quoted.
+func command(template string, args []string, extra ...string) Command {
+	if template == makeNopCmd() {
+		return Command{}
+	}
+	words, requiresShell := splitCommand(template)
+	if requiresShell {
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = ShellQuote(arg)
+		}
+		script := []string{replacePlaceholders(template, quoted)}
+		for _, arg := range extra {
+			script = append(script, ShellQuote(arg))
+		}
+		return Command{Args: []string{strings.Join(script, " ")}, RequiresShell: true}
+	}
+
+	res := Command{Args: make([]string, 0, len(words)+len(extra))}
+	for _, word := range words {
+		res.Args = append(res.Args, replacePlaceholders(word, args))
+		args = args[placeholders(word):]
+	}
+	res.Args = append(res.Args, extra...)
+	return res
+}
+
+// replacePlaceholders replaces the "%s" and "%q" placeholders of the
+// given template by the given arguments in turn, and "%%" by "%".
+func replacePlaceholders(template string, args []string) string {
+	var res strings.Builder
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' || i+1 == len(template) {
+			res.WriteByte(template[i])
+			continue
+		}
+		switch template[i+1] {
+		case 's', 'q':
+			if len(args) > 0 {
+				res.WriteString(args[0])
+				args = args[1:]
+			}
+			i++
+		case '%':
+			res.WriteByte('%')
+			i++
+		default:
+			res.WriteByte('%')
+		}
+	}
+	return res.String()
+}
+
+// placeholders returns the number of "%s" and "%q" placeholders of the
+// given template.
+func placeholders(template string) int {
+	n := 0
+	for i := 0; i+1 < len(template); i++ {
+		if template[i] != '%' {
+			continue
+		}
+		switch template[i+1] {
+		case 's', 'q':
+			n++
+		}
+		i++
+	}
+	return n
+}
+
+// InstallPrerequisite is defined on the Commander interface.
+func (p *packageCommander) InstallPrerequisite() Command {
+	return command(p.prereq, nil)
+}
+
+// Update is defined on the Commander interface.
+func (p *packageCommander) Update() Command {
+	return command(p.update, nil)
+}
+
+// Upgrade is defined on the Commander interface.
+func (p *packageCommander) Upgrade() Command {
+	return command(p.upgrade, nil)
+}
+
+// Install is defined on the Commander interface.
+func (p *packageCommander) Install(packs ...string) Command {
+	return command(p.install, nil, packs...)
+}
+
+// InstallSpec is defined on the Commander interface.
+func (p *packageCommander) InstallSpec(specs ...packaging.PackageSpec) Command {
+	var args []string
+	for _, spec := range specs {
+		args = append(args, p.specArgs(spec)...)
+	}
+	return command(p.install, nil, args...)
+}
+
+// Remove is defined on the Commander interface.
+func (p *packageCommander) Remove(packs ...string) Command {
+	return command(p.remove, nil, packs...)
+}
+
+// Purge is defined on the Commander interface.
+func (p *packageCommander) Purge(packs ...string) Command {
+	return command(p.purge, nil, packs...)
+}
+
+// Search is defined on the Commander interface.
+func (p *packageCommander) Search(pack string) Command {
+	return command(p.search, []string{pack})
+}
+
+// IsInstalled is defined on the Commander interface.
+func (p *packageCommander) IsInstalled(pack string) Command {
+	return command(p.isInstalled, []string{pack})
+}
+
+// PackageVersion is defined on the Commander interface.
+func (p *packageCommander) PackageVersion(pack string) Command {
+	return command(p.packageVersion, []string{pack})
+}
+
+// CandidateVersion is defined on the Commander interface.
+func (p *packageCommander) CandidateVersion(pack string) Command {
+	return command(p.candidateVersion, []string{pack})
+}
+
+// ListAvailable is defined on the Commander interface.
+func (p *packageCommander) ListAvailable() Command {
+	return command(p.listAvailable, nil)
+}
+
+// ListInstalled is defined on the Commander interface.
+func (p *packageCommander) ListInstalled() Command {
+	if p.listInstalledVersions != "" {
+		return command(p.listInstalledVersions, nil)
+	}
+	return command(p.listInstalled, nil)
+}
+
+// ListRepositories is defined on the Commander interface.
+func (p *packageCommander) ListRepositories() Command {
+	return command(p.listRepositories, nil)
+}
+
+// AddRepository is defined on the Commander interface.
+func (p *packageCommander) AddRepository(repo string) Command {
+	return command(p.addRepository, []string{repo})
+}
+
+// RemoveRepository is defined on the Commander interface.
+func (p *packageCommander) RemoveRepository(repo string) Command {
+	return command(p.removeRepository, []string{repo})
+}
+
+// Cleanup is defined on the Commander interface.
+func (p *packageCommander) Cleanup() Command {
+	return command(p.cleanup, nil)
+}
+
+// GetProxy is defined on the Commander interface.
+func (p *packageCommander) GetProxy() Command {
+	return command(p.getProxy, nil)
+}
+
+// SetProxy is defined on the Commander interface.
+func (p *packageCommander) SetProxy(settings proxy.Settings) []Command {
+	if p.proxyFile != "" {
+		return nil
+	}
+	// The options are arguments of the command setting them, as written
+	// in the syntax of the shell.
+	template, _ := splitCommand(p.setProxy)
+	var cmds []Command
+	for _, option := range p.proxyConfigLines(settings) {
+		words, _ := splitCommand(option)
+		var cmd Command
+		for _, word := range template {
+			if word == "%s" {
+				cmd.Args = append(cmd.Args, words...)
+			} else {
+				cmd.Args = append(cmd.Args, word)
+			}
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+// SetMirrors is defined on the Commander interface.
+func (p *packageCommander) SetMirrors(archiveMirror, securityMirror string) Command {
+	quote := func(mirror string) string {
+		if mirror == "" {
+			return ""
+		}
+		return ShellQuote(mirror)
+	}
+	lines := p.SetMirrorCommands(quote(archiveMirror), quote(securityMirror))
+	if len(lines) == 0 {
+		return Command{}
+	}
+	return Command{Args: []string{strings.Join(lines, "\n")}, RequiresShell: true}
+}
+
+// specArgs returns the install command arguments of the given spec.
+func (p *packageCommander) specArgs(spec packaging.PackageSpec) []string {
+	if p.renderSpec == nil {
+		return []string{spec.Name}
+	}
+	return p.renderSpec(spec)
+}
+
+// ValidatePackage is defined on the Commander interface.
+func (p *packageCommander) ValidatePackage(pack string) error {
+	return p.validatePackage(pack)
+}
+
+// ValidateAddRepository is defined on the Commander interface.
+func (p *packageCommander) ValidateAddRepository(repo string) error {
+	if p.validateAddRepo == nil {
+		return nil
+	}
+	return p.validateAddRepo(repo)
+}
+
+// ValidateRemoveRepository is defined on the Commander interface.
+func (p *packageCommander) ValidateRemoveRepository(repo string) error {
+	if p.validateRemoveRepo == nil {
+		return nil
+	}
+	return p.validateRemoveRepo(repo)
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+const (
+	// DnfConfigFilePath is the default configuration file for dnf settings.
+	DnfConfigFilePath = "/etc/dnf/dnf.conf"
+)
+
+const (
+	// the basic command for all dnf calls
+	//		--assumeyes to never prompt for confirmation
+	//		--quiet to limit output verbosity
+	dnf = "dnf --assumeyes --quiet"
+
+	// the basic command for all dnf repository configuration operations.
+	dnfconf = "dnf config-manager"
+
+	// the basic command for querying the dnf repositories; the query
+	// format outputs the epoch:version-release of the latest version.
+	dnfRepoQuery = "dnf repoquery --quiet --latest-limit=1 --queryformat %%{epoch}:%%{version}-%%{release}"
+
+	// the basic format for specifying a proxy setting for dnf.
+	// NOTE: dnf uses a single proxy for all protocols, so if the http and
+	// https proxies differ the last one written takes effect. dnf.conf has
+	// no setting for the hosts which bypass the proxy, so NoProxy is not
+	// written; dnf honours the no_proxy environment variable instead.
+	dnfProxySettingFormat = "proxy=%[2]s"
+)
+
+// dnfCmder is the packageCommander instantiation for dnf-based systems.
+var dnfCmder = packageCommander{
+	prereq:              buildCommand(dnf, "install dnf-plugins-core"),
+	update:              buildCommand(dnf, "makecache"),
+	upgrade:             buildCommand(dnf, "upgrade"),
+	install:             buildCommand(dnf, "install"),
+	remove:              buildCommand(dnf, "remove"),
+	purge:               buildCommand(dnf, "remove"), // purges by default
+	search:              buildCommand(dnf, "list %s"),
+	isInstalled:         buildCommand(dnf, "list installed %s"),
+	packageVersion:      buildCommand(rpmQuery, "%s"),
+	candidateVersion:    buildCommand(dnfRepoQuery, "%s"),
+	listAvailable:       buildCommand(dnf, "list all"),
+	listInstalled:       buildCommand(dnf, "list installed"),
+	listRepositories:    buildCommand(dnf, "repolist --all"),
+	addRepository:       buildCommand(dnfconf, "--add-repo %s"),
+	removeRepository:    buildCommand(dnfconf, "--set-disabled %s"),
+	cleanup:             buildCommand(dnf, "clean all"),
+	getProxy:            buildCommand("grep -R \"^proxy=\"", DnfConfigFilePath),
+	proxySettingsFormat: dnfProxySettingFormat,
+	setProxy:            buildCommand("echo %s >>", DnfConfigFilePath),
+	proxyFile:           DnfConfigFilePath,
+	renderSpec:          renderRPMSpec,
+	validatePackage:     ValidateRPMPackage,
+	validateAddRepo:     validateRPMRepository,
+	validateRemoveRepo:  validateRPMRepository,
+}
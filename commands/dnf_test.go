@@ -0,0 +1,55 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands_test
+
+import (
+	"github.com/juju/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+)
+
+var _ = gc.Suite(&DnfSuite{})
+
+type DnfSuite struct {
+	paccmder commands.PackageCommander
+}
+
+func (s *DnfSuite) SetUpSuite(c *gc.C) {
+	s.paccmder = commands.NewDnfPackageCommander()
+}
+
+func (s *DnfSuite) TestProxyConfigContentsEmpty(c *gc.C) {
+	out := s.paccmder.ProxyConfigContents(proxy.Settings{})
+	c.Assert(out, gc.Equals, "")
+}
+
+func (s *DnfSuite) TestProxyConfigContentsSingleProxy(c *gc.C) {
+	sets := proxy.Settings{
+		Http:  "dat-proxy.zone:8080",
+		Https: "dat-proxy.zone:8080",
+	}
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, "proxy=dat-proxy.zone:8080")
+}
+
+func (s *DnfSuite) TestSetProxyCmds(c *gc.C) {
+	cmds := s.paccmder.SetProxyCmds(proxy.Settings{Http: "dat-proxy.zone:8080"})
+	c.Assert(cmds, gc.DeepEquals, []string{"echo proxy=dat-proxy.zone:8080 >> /etc/dnf/dnf.conf"})
+}
+
+func (s *DnfSuite) TestCandidateVersionCmd(c *gc.C) {
+	cmd := s.paccmder.CandidateVersionCmd("bash")
+	c.Assert(cmd, gc.Equals, "dnf repoquery --quiet --latest-limit=1 --queryformat %{epoch}:%{version}-%{release} bash")
+}
+
+func (s *DnfSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "glibc", Architecture: "i686", Version: "2.34-60.el9"},
+	}...)
+	c.Assert(cmd, gc.Equals, "dnf --assumeyes --quiet install bash glibc-2.34-60.el9.i686")
+}
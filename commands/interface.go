@@ -9,6 +9,8 @@ package commands
 
 import (
 	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3"
 )
 
 // PackageCommander is the interface which provides runnable shell
@@ -28,6 +30,11 @@ type PackageCommander interface {
 	// InstallCmd returns a *single* command that installs the given package(s).
 	InstallCmd(...string) string
 
+	// InstallSpecCmd returns a *single* command that installs the given
+	// package(s), constrained to the version, architecture, channel and
+	// target release of each spec in the syntax of the package manager.
+	InstallSpecCmd(...packaging.PackageSpec) string
+
 	// RemoveCmd returns a *single* command that removes the given package(s).
 	RemoveCmd(...string) string
 
@@ -43,6 +50,15 @@ type PackageCommander interface {
 	// available for installation from the currently configured repositories.
 	SearchCmd(string) string
 
+	// PackageVersionCmd returns the command which outputs the version of
+	// the given package which is currently installed on the system.
+	PackageVersionCmd(string) string
+
+	// CandidateVersionCmd returns the command which outputs information
+	// about the version of the given package which would be installed
+	// from the currently configured repositories.
+	CandidateVersionCmd(string) string
+
 	// ListAvailableCmd returns the command which will list all packages
 	// available for installation from the currently configured repositories.
 	// NOTE: includes already installed packages.
@@ -87,18 +103,136 @@ type PackageCommander interface {
 	ProxyConfigContents(proxy.Settings) string
 
 	// SetProxyCmds returns the commands which write the proxy configuration
-	// to the configuration file of the package manager.
+	// to the configuration file of the package manager. The commands append
+	// to the file; see the proxyconfig package for replacing and removing
+	// the settings instead.
 	SetProxyCmds(proxy.Settings) []string
 }
 
+// Commander is the second version of the PackageCommander API, which
+// returns the commands as argument vectors rather than as command lines.
+// The managers run the commands directly, with Command.Argv, so that
+// package names and other arguments are never split or interpreted by a
+// shell; scripts, such as cloud-init configuration, embed them safely with
+// Command.Render.
+//
+// The arguments are not validated when the commands are built; untrusted
+// ones are validated first with ValidatePackage, ValidateAddRepository and
+// ValidateRemoveRepository, as the managers do.
+//
+// All the PackageCommander implementations provided by this package also
+// implement Commander, and the commands of both are the same.
+type Commander interface {
+	// InstallPrerequisite returns the command that installs the
+	// prerequisite package for repository-handling operations.
+	InstallPrerequisite() Command
+
+	// Update returns the command to update the local package list.
+	Update() Command
+
+	// Upgrade returns the command which issues an upgrade on all packages
+	// with available newer versions.
+	Upgrade() Command
+
+	// Install returns a *single* command that installs the given
+	// package(s).
+	Install(packs ...string) Command
+
+	// InstallSpec returns a *single* command that installs the given
+	// package(s), constrained to the version, architecture, channel and
+	// target release of each spec in the syntax of the package manager.
+	InstallSpec(specs ...packaging.PackageSpec) Command
+
+	// Remove returns a *single* command that removes the given package(s).
+	Remove(packs ...string) Command
+
+	// Purge returns the command that removes the given package(s) along
+	// with any associated config files.
+	Purge(packs ...string) Command
+
+	// IsInstalled returns the command which determines whether or not a
+	// package is currently installed on the system.
+	IsInstalled(pack string) Command
+
+	// Search returns the command that determines whether the given
+	// package is available for installation from the currently
+	// configured repositories.
+	Search(pack string) Command
+
+	// PackageVersion returns the command which outputs the version of the
+	// given package which is currently installed on the system.
+	PackageVersion(pack string) Command
+
+	// CandidateVersion returns the command which outputs information
+	// about the version of the given package which would be installed
+	// from the currently configured repositories.
+	CandidateVersion(pack string) Command
+
+	// ListAvailable returns the command which will list all packages
+	// available for installation from the currently configured
+	// repositories.
+	ListAvailable() Command
+
+	// ListInstalled returns the command which will list all packages
+	// currently installed on the system.
+	ListInstalled() Command
+
+	// ListRepositories returns the command that lists all repositories
+	// currently configured on the system.
+	ListRepositories() Command
+
+	// AddRepository returns the command that adds a repository to the
+	// list of available repositories.
+	AddRepository(repo string) Command
+
+	// RemoveRepository returns the command that removes a given
+	// repository from the list of available repositories.
+	RemoveRepository(repo string) Command
+
+	// Cleanup returns the command that cleans up all orphaned packages,
+	// left-over files and previously-cached packages.
+	Cleanup() Command
+
+	// GetProxy returns the command which outputs the proxies set for the
+	// package management system.
+	GetProxy() Command
+
+	// SetProxy returns the commands which set the proxy configuration of
+	// package managers configured with commands, such as snap. There are
+	// none for package managers configured with a file, which the
+	// proxyconfig package writes instead.
+	SetProxy(settings proxy.Settings) []Command
+
+	// SetMirrors returns the command which updates the package archive
+	// and security mirrors, which RequiresShell.
+	SetMirrors(archiveMirror, securityMirror string) Command
+
+	// ValidatePackage returns a *ValidationError if the given package,
+	// as given to the commands above, is malformed for the package
+	// manager or could be taken for one of its options.
+	ValidatePackage(pack string) error
+
+	// ValidateAddRepository returns a *ValidationError if the given
+	// repository is malformed for AddRepository.
+	ValidateAddRepository(repo string) error
+
+	// ValidateRemoveRepository returns a *ValidationError if the given
+	// repository is malformed for RemoveRepository.
+	ValidateRemoveRepository(repo string) error
+}
+
 // NewPackageCommander returns a new PackageCommander instance based on the
 // given series.
+// Unknown series fall back to apt; use distro.Detect to select the
+// PackageCommander from the os-release file of the system instead.
 func NewPackageCommander(series string) (PackageCommander, error) {
 	// TODO (aznashwan): find a more deterministic way of selection here which
 	// does not imply importing version from core.
 	switch series {
 	case "centos7":
 		return NewYumPackageCommander(), nil
+	case "centos8", "centos9", "rocky8", "rocky9", "almalinux8", "almalinux9":
+		return NewDnfPackageCommander(), nil
 	case "opensuseleap":
 		return NewZypperPackageCommander(), nil
 	default:
@@ -121,7 +255,57 @@ func NewYumPackageCommander() PackageCommander {
 	return &yumCmder
 }
 
+// NewApkPackageCommander returns a PackageCommander for apk-based systems.
+func NewApkPackageCommander() PackageCommander {
+	return &apkCmder
+}
+
+// NewDnfPackageCommander returns a PackageCommander for dnf-based systems.
+func NewDnfPackageCommander() PackageCommander {
+	return &dnfCmder
+}
+
+// NewPacmanPackageCommander returns a PackageCommander for pacman-based systems.
+func NewPacmanPackageCommander() PackageCommander {
+	return &pacmanCmder
+}
+
 // NewZypperPackageCommander returns a PackageCommander for zypper-based systems.
 func NewZypperPackageCommander() PackageCommander {
 	return &zypperCmder
 }
+
+// NewAptCommander returns a Commander for apt-based systems.
+func NewAptCommander() Commander {
+	return &aptCmder
+}
+
+// NewSnapCommander returns a Commander for snap-based systems.
+func NewSnapCommander() Commander {
+	return &snapCmder
+}
+
+// NewYumCommander returns a Commander for yum-based systems.
+func NewYumCommander() Commander {
+	return &yumCmder
+}
+
+// NewApkCommander returns a Commander for apk-based systems.
+func NewApkCommander() Commander {
+	return &apkCmder
+}
+
+// NewDnfCommander returns a Commander for dnf-based systems.
+func NewDnfCommander() Commander {
+	return &dnfCmder
+}
+
+// NewPacmanCommander returns a Commander for pacman-based systems.
+func NewPacmanCommander() Commander {
+	return &pacmanCmder
+}
+
+// NewZypperCommander returns a Commander for zypper-based systems.
+func NewZypperCommander() Commander {
+	return &zypperCmder
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"net"
+	"strings"
+)
+
+// NoProxyHosts splits the given comma separated NoProxy setting of
+// proxy.Settings into its hosts, dropping empty entries and duplicates.
+func NoProxyHosts(noProxy string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, host := range strings.Split(noProxy, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// aptNoProxyHost keeps the hosts apt can bypass the proxy for, which are
+// exact host names and addresses. Domain suffixes, wildcards and CIDR
+// ranges cannot be expressed with apt and are dropped.
+func aptNoProxyHost(host string) (string, bool) {
+	if strings.ContainsAny(host, "*/") || strings.HasPrefix(host, ".") {
+		return "", false
+	}
+	return host, true
+}
+
+// curlNoProxyHost translates a host into the no_proxy syntax of curl, which
+// yum and zypper use: "*" bypasses the proxy for all hosts, a leading dot
+// for all the subdomains of a domain, and CIDR ranges for all the addresses
+// within them. Wildcards for subdomains, such as "*.example.com", are
+// translated into the leading dot syntax; other wildcards are dropped.
+func curlNoProxyHost(host string) (string, bool) {
+	if host == "*" {
+		return host, true
+	}
+	if strings.HasPrefix(host, "*.") {
+		host = host[1:]
+	}
+	if strings.Contains(host, "*") {
+		return "", false
+	}
+	if strings.Contains(host, "/") {
+		if _, _, err := net.ParseCIDR(host); err != nil {
+			return "", false
+		}
+	}
+	return host, true
+}
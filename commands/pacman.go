@@ -0,0 +1,81 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import "github.com/juju/packaging/v3"
+
+const (
+	// PacmanConfigFilePath is the default configuration file for pacman
+	// settings and repositories.
+	PacmanConfigFilePath = "/etc/pacman.conf"
+
+	// PacmanProxyFilePath is the file in which the proxy environment
+	// variables honoured by pacman are persisted.
+	PacmanProxyFilePath = "/etc/profile.d/pacman-proxy.sh"
+)
+
+const (
+	// the basic command for all pacman calls
+	//		--noconfirm to never prompt for confirmation
+	pacman = "pacman --noconfirm"
+
+	// the basic format for specifying a proxy setting for pacman, which
+	// only honours the proxy environment variables. The values are quoted
+	// for the shell which sources the file.
+	pacmanProxySettingFormat = "export %s_proxy=%s"
+
+	// the format for specifying the hosts reached without a proxy, as
+	// honoured by pacman, separated by commas.
+	pacmanNoProxySettingFormat = "export no_proxy=%s"
+)
+
+// pacmanCmder is the packageCommander instantiation for pacman-based systems.
+//
+// Arch Linux does not support partial upgrades: installing packages from
+// package databases refreshed by update, without upgrading the installed
+// packages to them, can break the libraries they share. Packages are
+// therefore installed along with a full upgrade of the system, whereas
+// update only refreshes the package databases used to query packages.
+var pacmanCmder = packageCommander{
+	prereq:           makeNopCmd(),
+	update:           buildCommand(pacman, "-Sy"),
+	upgrade:          buildCommand(pacman, "-Syu"),
+	install:          buildCommand(pacman, "-Syu --needed"),
+	remove:           buildCommand(pacman, "-R"),
+	purge:            buildCommand(pacman, "-Rns"),
+	search:           buildCommand(pacman, "-Ss ^%s$"),
+	isInstalled:      buildCommand(pacman, "-Qi %s"),
+	packageVersion:   buildCommand(pacman, "-Qi %s"),
+	candidateVersion: buildCommand(pacman, "-Si %s"),
+	listAvailable:    buildCommand(pacman, "-Sl"),
+	listInstalled:    buildCommand(pacman, "-Q"),
+	listRepositories: buildCommand("pacman-conf", "--repo-list"),
+	// Repositories are sections of the pacman configuration file, which
+	// are rendered by the PackagingConfigurer.
+	addRepository:       makeNopCmd(),
+	removeRepository:    makeNopCmd(),
+	cleanup:             buildCommand(pacman, "-Sc"),
+	getProxy:            buildCommand("grep -R \".*_proxy=\"", PacmanProxyFilePath),
+	proxySettingsFormat: pacmanProxySettingFormat,
+	noProxyListFormat:   pacmanNoProxySettingFormat,
+	quoteProxy:          ShellQuote,
+	setProxy:            buildCommand("echo %s >>", PacmanProxyFilePath),
+	proxyFile:           PacmanProxyFilePath,
+	renderSpec:          renderPacmanSpec,
+	validatePackage:     validatePacmanPackage,
+}
+
+// renderPacmanSpec renders a package spec in the [repo/]name[=version]
+// syntax of pacman, where the target release is the repository to install
+// the package from.
+func renderPacmanSpec(spec packaging.PackageSpec) []string {
+	pack := spec.Name
+	if spec.TargetRelease != "" {
+		pack = spec.TargetRelease + "/" + pack
+	}
+	if spec.Version != "" {
+		pack += "=" + spec.Version
+	}
+	return []string{pack}
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands_test
+
+import (
+	"github.com/juju/proxy"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+)
+
+var _ = gc.Suite(&PacmanSuite{})
+
+type PacmanSuite struct {
+	paccmder commands.PackageCommander
+}
+
+func (s *PacmanSuite) SetUpSuite(c *gc.C) {
+	s.paccmder = commands.NewPacmanPackageCommander()
+}
+
+func (s *PacmanSuite) TestProxyConfigContents(c *gc.C) {
+	sets := proxy.Settings{
+		Http:  "dat-proxy.zone:8080",
+		Https: "https://much-security.com",
+	}
+	expected := `export http_proxy=dat-proxy.zone:8080
+export https_proxy=https://much-security.com`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *PacmanSuite) TestProxyConfigContentsQuotesValues(c *gc.C) {
+	sets := proxy.Settings{Http: "http://proxy.local:3128/`reboot`"}
+	expected := "export http_proxy='http://proxy.local:3128/`reboot`'"
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *PacmanSuite) TestProxyConfigContentsNoProxy(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		NoProxy: "localhost,.example.com",
+	}
+	expected := `export http_proxy=dat-proxy.zone:8080
+export no_proxy=localhost,.example.com`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *PacmanSuite) TestCmds(c *gc.C) {
+	c.Assert(s.paccmder.UpgradeCmd(), gc.Equals, "pacman --noconfirm -Syu")
+	// Packages are never installed without upgrading the system.
+	c.Assert(s.paccmder.InstallCmd("bash"), gc.Equals, "pacman --noconfirm -Syu --needed bash")
+	c.Assert(s.paccmder.PackageVersionCmd("bash"), gc.Equals, "pacman --noconfirm -Qi bash")
+	c.Assert(s.paccmder.SearchCmd("bash"), gc.Equals, "pacman --noconfirm -Ss ^bash$")
+	c.Assert(s.paccmder.PurgeCmd("bash", "zsh"), gc.Equals, "pacman --noconfirm -Rns bash zsh")
+}
+
+func (s *PacmanSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "curl", Version: "8.4.0-1"},
+		{Name: "lxd", TargetRelease: "extra"},
+		{Name: "linux", TargetRelease: "core-testing", Version: "6.6.1.arch1-1"},
+	}...)
+	c.Assert(cmd, gc.Equals, "pacman --noconfirm -Syu --needed bash curl=8.4.0-1 extra/lxd core-testing/linux=6.6.1.arch1-1")
+}
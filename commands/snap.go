@@ -3,11 +3,17 @@
 
 package commands
 
+import (
+	"github.com/juju/packaging/v3"
+)
+
 const (
 	// snap binary name.
 	snapBinary = "snap"
 
-	snapNoProxySettingFormat = `proxy.%s=""`
+	// snapd passes the hosts which bypass the proxy on to the no_proxy
+	// environment variable as they are.
+	snapNoProxySettingFormat = `proxy.no-proxy=%q`
 	snapProxySettingFormat   = `proxy.%s=%q`
 )
 
@@ -16,11 +22,13 @@ var snapCmder = packageCommander{
 	prereq:           makeNopCmd(),
 	update:           makeNopCmd(),
 	upgrade:          buildCommand(snapBinary, "refresh"),
-	install:          buildCommand(snapBinary, "install "),
+	install:          buildCommand(snapBinary, "install"),
 	remove:           buildCommand(snapBinary, "remove"),
 	purge:            buildCommand(snapBinary, "remove"),
 	search:           buildCommand(snapBinary, "info %s"),
 	isInstalled:      buildCommand(snapBinary, "list %s"),
+	packageVersion:   buildCommand(snapBinary, "list %s"),
+	candidateVersion: buildCommand(snapBinary, "info %s"),
 	listAvailable:    makeNopCmd(),
 	listInstalled:    buildCommand(snapBinary, "list"),
 	addRepository:    makeNopCmd(),
@@ -28,10 +36,27 @@ var snapCmder = packageCommander{
 	removeRepository: makeNopCmd(),
 	cleanup:          makeNopCmd(),
 	// Note: proxy.{http,https} available since snapd 2.28
-	getProxy:              buildCommand(snapBinary, "get system proxy"),
-	proxySettingsFormat:   snapProxySettingFormat,
-	noProxySettingsFormat: snapNoProxySettingFormat,
-	setProxy:              buildCommand(snapBinary, "set system %s"),
+	getProxy:            buildCommand(snapBinary, "get system proxy"),
+	proxySettingsFormat: snapProxySettingFormat,
+	noProxyListFormat:   snapNoProxySettingFormat,
+	setProxy:            buildCommand(snapBinary, "set system %s"),
+	renderSpec:          renderSnapSpec,
+	validatePackage:     ValidateSnapName,
+}
+
+// renderSnapSpec renders a package spec as the arguments of snap install,
+// with the version being requested as a revision.
+// NOTE: snap only accepts the --channel and --revision options when a
+// single snap is being installed.
+func renderSnapSpec(spec packaging.PackageSpec) []string {
+	args := []string{spec.Name}
+	if spec.Channel != "" {
+		args = append(args, "--channel="+spec.Channel)
+	}
+	if spec.Version != "" {
+		args = append(args, "--revision="+spec.Version)
+	}
+	return args
 }
 
 func makeNopCmd() string {
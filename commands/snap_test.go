@@ -7,6 +7,7 @@ import (
 	"github.com/juju/proxy"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 )
 
@@ -63,3 +64,25 @@ func (s *SnapSuite) TestSetProxyCommands(c *gc.C) {
 	output := s.paccmder.SetProxyCmds(sets)
 	c.Assert(output, gc.DeepEquals, expected)
 }
+
+func (s *SnapSuite) TestSetProxyCommandsNoProxy(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		NoProxy: "localhost,*.example.com,10.0.0.0/8",
+	}
+	expected := []string{
+		`snap set system proxy.http="dat-proxy.zone:8080"`,
+		`snap set system proxy.no-proxy="localhost,*.example.com,10.0.0.0/8"`,
+	}
+
+	output := s.paccmder.SetProxyCmds(sets)
+	c.Assert(output, gc.DeepEquals, expected)
+}
+
+func (s *SnapSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd(packaging.PackageSpec{Name: "lxd", Channel: "4.0/stable", Version: "22753"})
+	c.Assert(cmd, gc.Equals, "snap install lxd --channel=4.0/stable --revision=22753")
+
+	cmd = s.paccmder.InstallSpecCmd(packaging.PackageSpec{Name: "jq"}, packaging.PackageSpec{Name: "yq"})
+	c.Assert(cmd, gc.Equals, "snap install jq yq")
+}
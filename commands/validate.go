@@ -0,0 +1,486 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/juju/errors"
+)
+
+// ValidationError is the error returned when a package name, snap channel
+// or repository is malformed, or could be taken for an option of the
+// package manager or for shell syntax.
+type ValidationError struct {
+	// Kind is the kind of the value, such as "debian package".
+	Kind string
+
+	// Value is the value which is not valid.
+	Value string
+
+	// Reason describes why the value is not valid.
+	Reason string
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %q not valid: %s", e.Kind, e.Value, e.Reason)
+}
+
+// IsValidationError reports whether the cause of the given error is a
+// *ValidationError.
+func IsValidationError(err error) bool {
+	_, ok := errors.Cause(err).(*ValidationError)
+	return ok
+}
+
+// invalid returns a *ValidationError of the given kind and value, whose
+// reason is formatted from the given arguments.
+func invalid(kind, value, format string, args ...interface{}) error {
+	return &ValidationError{Kind: kind, Value: value, Reason: fmt.Sprintf(format, args...)}
+}
+
+// unsafeChars are the characters which are never valid in a package name,
+// snap channel or repository, outside of the fields of a deb line, as they
+// are interpreted by the shell.
+const unsafeChars = ";&|<>()`$'\"\\*?[]{}!#"
+
+// checkArgument returns an error if the given value of the given kind is
+// empty, starts with a hyphen, so that it would be taken for an option, or
+// contains whitespace, control characters or unsafeChars.
+func checkArgument(kind, value string) error {
+	if value == "" {
+		return invalid(kind, value, "empty")
+	}
+	if strings.HasPrefix(value, "-") {
+		return invalid(kind, value, "starts with a hyphen")
+	}
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(unsafeChars, r) {
+			return invalid(kind, value, "contains %q", r)
+		}
+	}
+	return nil
+}
+
+var (
+	// debianNameRE matches Debian package names, as defined by the Debian
+	// policy manual.
+	debianNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+$`)
+
+	// debianArchRE matches Debian architecture names.
+	debianArchRE = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+	// debianVersionRE matches Debian versions, with their optional epoch.
+	debianVersionRE = regexp.MustCompile(`^(?:[0-9]+:)?[0-9][A-Za-z0-9.+~-]*$`)
+
+	// debianReleaseRE matches the names of Debian releases and suites,
+	// such as "jammy-backports".
+	debianReleaseRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+_-]*$`)
+)
+
+// ValidateDebianPackage returns a *ValidationError if the given package is
+// not a Debian package name, optionally followed by an ":arch"
+// architecture and by a "=version" version or a "/release" target
+// release, as accepted by apt-get.
+func ValidateDebianPackage(pack string) error {
+	const kind = "debian package"
+	if err := checkArgument(kind, pack); err != nil {
+		return err
+	}
+	name, suffix := pack, ""
+	if i := strings.IndexAny(pack, "=/"); i >= 0 {
+		name, suffix = pack[:i], pack[i:]
+	}
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		if arch := name[i+1:]; !debianArchRE.MatchString(arch) {
+			return invalid(kind, pack, "architecture %q", arch)
+		}
+		name = name[:i]
+	}
+	if !debianNameRE.MatchString(name) {
+		return invalid(kind, pack, "expected at least two lower case letters, digits or any of %q, starting with a letter or digit", "+.-")
+	}
+	switch {
+	case strings.HasPrefix(suffix, "="):
+		if !debianVersionRE.MatchString(suffix[1:]) {
+			return invalid(kind, pack, "version %q", suffix[1:])
+		}
+	case strings.HasPrefix(suffix, "/"):
+		if !debianReleaseRE.MatchString(suffix[1:]) {
+			return invalid(kind, pack, "release %q", suffix[1:])
+		}
+	}
+	return nil
+}
+
+var (
+	// rpmNEVRARE matches RPM package names, optionally followed by their
+	// epoch, version, release and architecture, without the colon of the
+	// epoch, which is checked separately.
+	rpmNEVRARE = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_+.~^-]*$`)
+
+	// epochRE matches the epoch of an RPM package version.
+	epochRE = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ValidateRPMPackage returns a *ValidationError if the given package is
+// not an RPM package name, optionally followed by its version, release and
+// architecture in the "name-[epoch:]version-release.arch" NEVRA format, as
+// accepted by yum, dnf and zypper.
+func ValidateRPMPackage(nevra string) error {
+	const kind = "rpm package"
+	if err := checkArgument(kind, nevra); err != nil {
+		return err
+	}
+	rest := nevra
+	if i := strings.IndexByte(nevra, ':'); i >= 0 {
+		dash := strings.LastIndexByte(nevra[:i], '-')
+		if dash <= 0 || !epochRE.MatchString(nevra[dash+1:i]) {
+			return invalid(kind, nevra, "epoch %q", nevra[dash+1:i])
+		}
+		if strings.IndexByte(nevra[i+1:], ':') >= 0 {
+			return invalid(kind, nevra, "more than one epoch")
+		}
+		if i+1 == len(nevra) {
+			return invalid(kind, nevra, "epoch without version")
+		}
+		rest = nevra[:dash] + "-" + nevra[i+1:]
+	}
+	if !rpmNEVRARE.MatchString(rest) {
+		return invalid(kind, nevra, "expected letters, digits or any of %q, starting with a letter, digit or underscore", "_+.~^-")
+	}
+	return nil
+}
+
+var (
+	// apkPackageRE matches apk package names, optionally followed by the
+	// "@tag" of their repository and an "=" or "~" version constraint.
+	apkPackageRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*(?:@[A-Za-z0-9._-]+)?(?:[=~][A-Za-z0-9._+~-]+)?$`)
+
+	// pacmanPackageRE matches pacman package names, optionally preceded
+	// by the "repository/" they are installed from.
+	pacmanPackageRE = regexp.MustCompile(`^(?:[a-z0-9][a-z0-9._-]*/)?[a-z0-9@_+][a-z0-9@._+-]*$`)
+)
+
+// validateApkPackage returns a *ValidationError if the given package is not
+// an apk package name, as accepted by apk add.
+func validateApkPackage(pack string) error {
+	const kind = "apk package"
+	if err := checkArgument(kind, pack); err != nil {
+		return err
+	}
+	if !apkPackageRE.MatchString(pack) {
+		return invalid(kind, pack, "expected a name, optionally followed by a repository tag and version constraint")
+	}
+	return nil
+}
+
+// validatePacmanPackage returns a *ValidationError if the given package is
+// not a pacman package name, as accepted by pacman -S.
+func validatePacmanPackage(pack string) error {
+	const kind = "pacman package"
+	if err := checkArgument(kind, pack); err != nil {
+		return err
+	}
+	if !pacmanPackageRE.MatchString(pack) {
+		return invalid(kind, pack, "expected lower case letters, digits or any of %q, optionally preceded by a repository", "@._+-")
+	}
+	return nil
+}
+
+var (
+	// snapNameRE matches snap names, optionally followed by the "_key"
+	// instance key of a parallel install.
+	snapNameRE = regexp.MustCompile(`^([a-z0-9](?:-?[a-z0-9])*)(?:_[a-z0-9]{1,10})?$`)
+
+	// snapTrackRE matches the tracks and branches of snap channels.
+	snapTrackRE = regexp.MustCompile(`^[A-Za-z0-9](?:[_.-]?[A-Za-z0-9])*$`)
+
+	// snapRisks are the risk levels of snap channels.
+	snapRisks = []string{"stable", "candidate", "beta", "edge"}
+)
+
+// ValidateSnapName returns a *ValidationError if the given name is not a
+// snap name, optionally followed by the instance key of a parallel install,
+// such as "juju_2".
+func ValidateSnapName(name string) error {
+	const kind = "snap name"
+	if err := checkArgument(kind, name); err != nil {
+		return err
+	}
+	m := snapNameRE.FindStringSubmatch(name)
+	if m == nil {
+		return invalid(kind, name, "expected lower case letters, digits and single hyphens, not at the ends")
+	}
+	if len(m[1]) > 40 {
+		return invalid(kind, name, "longer than 40 characters")
+	}
+	if !strings.ContainsAny(m[1], "abcdefghijklmnopqrstuvwxyz") {
+		return invalid(kind, name, "without letters")
+	}
+	return nil
+}
+
+// ValidateSnapChannel returns a *ValidationError if the given channel is
+// not a snap channel in the "track/risk/branch" format, any of whose parts
+// but the risk may be omitted, as in "latest/stable", "edge",
+// "3.1/beta/hotfix" or "3.1".
+func ValidateSnapChannel(channel string) error {
+	const kind = "snap channel"
+	if err := checkArgument(kind, channel); err != nil {
+		return err
+	}
+	isRisk := func(s string) bool {
+		for _, risk := range snapRisks {
+			if s == risk {
+				return true
+			}
+		}
+		return false
+	}
+
+	parts := strings.Split(channel, "/")
+	for _, part := range parts {
+		if part == "" {
+			return invalid(kind, channel, "empty track, risk or branch")
+		}
+	}
+	var track, risk, branch string
+	switch len(parts) {
+	case 1:
+		if isRisk(parts[0]) {
+			risk = parts[0]
+		} else {
+			track = parts[0]
+		}
+	case 2:
+		if isRisk(parts[0]) {
+			risk, branch = parts[0], parts[1]
+		} else {
+			track, risk = parts[0], parts[1]
+		}
+	case 3:
+		track, risk, branch = parts[0], parts[1], parts[2]
+	default:
+		return invalid(kind, channel, "more than three parts")
+	}
+	if track != "" && (!snapTrackRE.MatchString(track) || len(track) > 28) {
+		return invalid(kind, channel, "track %q", track)
+	}
+	if len(parts) > 1 && !isRisk(risk) {
+		return invalid(kind, channel, "risk %q, expected one of %s", risk, strings.Join(snapRisks, ", "))
+	}
+	if branch != "" && (!snapTrackRE.MatchString(branch) || len(branch) > 128) {
+		return invalid(kind, channel, "branch %q", branch)
+	}
+	return nil
+}
+
+// urlUnsafeChars are the characters which are not valid in a repository
+// URL, as they are interpreted by the shell. The URL escapes of "%" are
+// allowed.
+const urlUnsafeChars = ";&|<>()`$'\"\\*[]{}!"
+
+// ValidateRepositoryURL returns a *ValidationError if the given URL is not
+// the absolute URL of a repository, with a host unless it is a file URL.
+func ValidateRepositoryURL(repo string) error {
+	const kind = "repository URL"
+	if repo == "" {
+		return invalid(kind, repo, "empty")
+	}
+	for _, r := range repo {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(urlUnsafeChars, r) {
+			return invalid(kind, repo, "contains %q", r)
+		}
+	}
+	u, err := url.Parse(repo)
+	if err != nil {
+		return invalid(kind, repo, "%v", errors.Cause(err))
+	}
+	switch {
+	case !u.IsAbs():
+		return invalid(kind, repo, "without scheme")
+	case u.Scheme == "file" || u.Scheme == "dir":
+		if u.Path == "" {
+			return invalid(kind, repo, "without path")
+		}
+	case u.Host == "":
+		return invalid(kind, repo, "without host")
+	case strings.HasPrefix(u.Host, "-"):
+		return invalid(kind, repo, "host starts with a hyphen")
+	}
+	return nil
+}
+
+// validateRepositoryPath returns a *ValidationError if the given path is
+// not the absolute path of a repository or repository file.
+func validateRepositoryPath(kind, path string) error {
+	if err := checkArgument(kind, path); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(path, "/") {
+		return invalid(kind, path, "not absolute")
+	}
+	return nil
+}
+
+var (
+	// ppaRE matches the "owner/name" of a PPA, whose name defaults to
+	// "ppa" if omitted.
+	ppaRE = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]*(?:/[a-z0-9][a-z0-9+._-]*)?$`)
+
+	// cloudArchiveRE matches the pockets of the Ubuntu Cloud Archive,
+	// such as "antelope" or "antelope-proposed".
+	cloudArchiveRE = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+	// aptComponentRE matches the components of an apt repository, such as
+	// "universe", and the suites of deb lines.
+	aptComponentRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/+-]*$`)
+
+	// aptOptionRE matches the options of deb lines, such as
+	// "arch=amd64,arm64" or "signed-by=/usr/share/keyrings/foo.gpg".
+	aptOptionRE = regexp.MustCompile(`^[a-z][a-z-]*(?:[+-]?=)[A-Za-z0-9._,:/+-]+$`)
+)
+
+// ValidatePPA returns a *ValidationError if the given PPA is not the
+// "owner/name" or "owner" of a PPA, without its "ppa:" prefix.
+func ValidatePPA(ppa string) error {
+	const kind = "PPA"
+	if err := checkArgument(kind, ppa); err != nil {
+		return err
+	}
+	if !ppaRE.MatchString(ppa) {
+		return invalid(kind, ppa, `expected "owner/name"`)
+	}
+	return nil
+}
+
+// ValidateAptRepository returns a *ValidationError if the given repository
+// is not accepted by add-apt-repository, as a "ppa:owner/name" PPA, a
+// "cloud-archive:pocket" or "uca:pocket" pocket of the Ubuntu Cloud
+// Archive, a one-line "deb" or "deb-src" source, a repository URL or a
+// component, such as "universe".
+func ValidateAptRepository(repo string) error {
+	const kind = "apt repository"
+	if strings.HasPrefix(repo, "deb ") || strings.HasPrefix(repo, "deb-src ") {
+		return validateDebLine(repo)
+	}
+	if strings.Contains(repo, "://") {
+		return ValidateRepositoryURL(repo)
+	}
+	if err := checkArgument(kind, repo); err != nil {
+		return err
+	}
+	scheme, rest, ok := strings.Cut(repo, ":")
+	switch {
+	case !ok:
+		if !aptComponentRE.MatchString(repo) || strings.Contains(repo, "/") {
+			return invalid(kind, repo, "expected a PPA, cloud archive pocket, deb line, URL or component")
+		}
+	case scheme == "ppa":
+		if err := ValidatePPA(rest); err != nil {
+			return invalid(kind, repo, "PPA %q", rest)
+		}
+	case scheme == "cloud-archive" || scheme == "uca":
+		if !cloudArchiveRE.MatchString(rest) {
+			return invalid(kind, repo, "cloud archive pocket %q", rest)
+		}
+	default:
+		return invalid(kind, repo, "unknown prefix %q", scheme+":")
+	}
+	return nil
+}
+
+// validateDebLine returns a *ValidationError if the given line is not a
+// one-line "deb" or "deb-src" source.
+func validateDebLine(line string) error {
+	const kind = "deb line"
+	for _, r := range line {
+		if r != ' ' && unicode.IsSpace(r) || unicode.IsControl(r) {
+			return invalid(kind, line, "contains %q", r)
+		}
+	}
+	fields := strings.Fields(line)[1:]
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "[") {
+		fields[0] = fields[0][1:]
+		closed := false
+		for len(fields) > 0 && !closed {
+			option := fields[0]
+			if strings.HasSuffix(option, "]") {
+				option, closed = option[:len(option)-1], true
+			}
+			if option != "" && !aptOptionRE.MatchString(option) {
+				return invalid(kind, line, "option %q", option)
+			}
+			fields = fields[1:]
+		}
+		if !closed {
+			return invalid(kind, line, "unterminated options")
+		}
+	}
+	if len(fields) < 2 {
+		return invalid(kind, line, "without URI or suite")
+	}
+	if err := ValidateRepositoryURL(fields[0]); err != nil {
+		return invalid(kind, line, "URI %q", fields[0])
+	}
+	for _, field := range fields[1:] {
+		if !aptComponentRE.MatchString(field) {
+			return invalid(kind, line, "suite or component %q", field)
+		}
+	}
+	return nil
+}
+
+// rpmRepositoryIDRE matches the ids of yum and dnf repositories and the
+// aliases of zypper repositories.
+var rpmRepositoryIDRE = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._:@-]*$`)
+
+// validateRPMRepository returns a *ValidationError if the given repository
+// is not a repository URL, the absolute path of a repository file, or the
+// id or alias of a repository, as accepted by yum-config-manager,
+// dnf config-manager and zypper.
+func validateRPMRepository(repo string) error {
+	const kind = "rpm repository"
+	switch {
+	case strings.Contains(repo, "://"):
+		return ValidateRepositoryURL(repo)
+	case strings.HasPrefix(repo, "/"):
+		return validateRepositoryPath(kind, repo)
+	}
+	if err := checkArgument(kind, repo); err != nil {
+		return err
+	}
+	if !rpmRepositoryIDRE.MatchString(repo) {
+		return invalid(kind, repo, "expected a URL, absolute path or repository id")
+	}
+	return nil
+}
+
+// apkTagRE matches the "@tag" of apk repositories.
+var apkTagRE = regexp.MustCompile(`^@[A-Za-z0-9._-]+$`)
+
+// validateApkRepository returns a *ValidationError if the given repository
+// is not a repository URL or absolute path, optionally preceded by its
+// "@tag", as listed in the apk repositories file.
+func validateApkRepository(repo string) error {
+	const kind = "apk repository"
+	location := repo
+	if strings.HasPrefix(repo, "@") {
+		tag, rest, ok := strings.Cut(repo, " ")
+		if !ok || !apkTagRE.MatchString(tag) {
+			return invalid(kind, repo, "tag %q", tag)
+		}
+		location = strings.TrimLeft(rest, " ")
+	}
+	if strings.HasPrefix(location, "/") {
+		return validateRepositoryPath(kind, location)
+	}
+	return ValidateRepositoryURL(location)
+}
@@ -0,0 +1,224 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package commands_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/commands"
+)
+
+var _ = gc.Suite(&ValidateSuite{})
+
+type ValidateSuite struct{}
+
+type validateTest struct {
+	value string
+	err   string
+}
+
+func checkValidate(c *gc.C, validate func(string) error, tests []validateTest) {
+	for i, test := range tests {
+		c.Logf("test %d: %q", i, test.value)
+		err := validate(test.value)
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+			continue
+		}
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, commands.IsValidationError)
+	}
+}
+
+func (s *ValidateSuite) TestValidateDebianPackage(c *gc.C) {
+	checkValidate(c, commands.ValidateDebianPackage, []validateTest{
+		{"bash", ""},
+		{"libstdc++6", ""},
+		{"g++-12:arm64", ""},
+		{"bash=5.1-6ubuntu1", ""},
+		{"bash=1:5.1~rc1", ""},
+		{"juju:amd64/jammy-backports", ""},
+		{"", `debian package "" not valid: empty`},
+		{"-oDebug::pkgProblemResolver=1", `debian package .* not valid: starts with a hyphen`},
+		{"foo bar", `debian package "foo bar" not valid: contains ' '`},
+		{"foo;reboot", `debian package "foo;reboot" not valid: contains ';'`},
+		{"foo$(reboot)", `debian package .* not valid: contains '\$'`},
+		{"Bash", `debian package "Bash" not valid: expected .*`},
+		{"a", `debian package "a" not valid: expected .*`},
+		{"bash:AMD64", `debian package .* not valid: architecture "AMD64"`},
+		{"bash=latest", `debian package .* not valid: version "latest"`},
+	})
+}
+
+func (s *ValidateSuite) TestValidateRPMPackage(c *gc.C) {
+	checkValidate(c, commands.ValidateRPMPackage, []validateTest{
+		{"bash", ""},
+		{"NetworkManager", ""},
+		{"bash.x86_64", ""},
+		{"bash-5.1.8-6.el9", ""},
+		{"bash-0:5.1.8-6.el9.x86_64", ""},
+		{"python3-pip-21.2.3-6.el9.noarch", ""},
+		{"--setopt=sslverify=0", `rpm package .* not valid: starts with a hyphen`},
+		{"bash*", `rpm package "bash\*" not valid: contains '\*'`},
+		{"bash-x:5.1", `rpm package .* not valid: epoch "x"`},
+		{"bash-1:5.1:2", `rpm package .* not valid: more than one epoch`},
+		{"bash-1:", `rpm package .* not valid: epoch without version`},
+		{"bash/x", `rpm package .* not valid: expected .*`},
+	})
+}
+
+func (s *ValidateSuite) TestValidateSnapName(c *gc.C) {
+	checkValidate(c, commands.ValidateSnapName, []validateTest{
+		{"juju", ""},
+		{"lxd", ""},
+		{"charmcraft", ""},
+		{"juju-db", ""},
+		{"juju_2", ""},
+		{"Juju", `snap name "Juju" not valid: expected .*`},
+		{"juju--db", `snap name .* not valid: expected .*`},
+		{"juju-", `snap name .* not valid: expected .*`},
+		{"1234", `snap name "1234" not valid: without letters`},
+		{"a23456789012345678901234567890123456789012", `snap name .* not valid: longer than 40 characters`},
+		{"--dangerous", `snap name .* not valid: starts with a hyphen`},
+	})
+}
+
+func (s *ValidateSuite) TestValidateSnapChannel(c *gc.C) {
+	checkValidate(c, commands.ValidateSnapChannel, []validateTest{
+		{"stable", ""},
+		{"edge", ""},
+		{"latest/stable", ""},
+		{"3.1/beta", ""},
+		{"3.1/beta/hotfix-1", ""},
+		{"candidate/fix", ""},
+		{"3.1", ""},
+		{"3.1/daily", `snap channel .* not valid: risk "daily", expected one of stable, candidate, beta, edge`},
+		{"3.1/stable/", `snap channel .* not valid: empty track, risk or branch`},
+		{"3.1/stable/-fix", `snap channel .* not valid: branch "-fix"`},
+		{"a/stable/b/c", `snap channel .* not valid: more than three parts`},
+		{"/stable", `snap channel .* not valid: empty track, risk or branch`},
+		{"3.1.x./stable", `snap channel .* not valid: track "3.1.x."`},
+		{"latest/stable --dangerous", `snap channel .* not valid: contains ' '`},
+	})
+}
+
+func (s *ValidateSuite) TestValidateRepositoryURL(c *gc.C) {
+	checkValidate(c, commands.ValidateRepositoryURL, []validateTest{
+		{"https://dl-cdn.alpinelinux.org/alpine/v3.18/main", ""},
+		{"http://archive.ubuntu.com/ubuntu/", ""},
+		{"https://download.docker.com/linux/centos/docker-ce.repo", ""},
+		{"file:///srv/repo", ""},
+		{"https://example.com/repo%20name", ""},
+		{"example.com/repo", `repository URL .* not valid: without scheme`},
+		{"https:///repo", `repository URL .* not valid: without host`},
+		{"https://example.com/$(reboot)", `repository URL .* not valid: contains '\$'`},
+		{"https://example.com/ repo", `repository URL .* not valid: contains ' '`},
+	})
+}
+
+func (s *ValidateSuite) TestValidateAptRepository(c *gc.C) {
+	checkValidate(c, commands.ValidateAptRepository, []validateTest{
+		{"ppa:juju/stable", ""},
+		{"ppa:deadsnakes/ppa", ""},
+		{"ppa:owner", ""},
+		{"cloud-archive:antelope", ""},
+		{"uca:antelope-proposed", ""},
+		{"universe", ""},
+		{"deb http://archive.ubuntu.com/ubuntu jammy main universe", ""},
+		{"deb [arch=amd64 signed-by=/usr/share/keyrings/docker.gpg] https://download.docker.com/linux/ubuntu jammy stable", ""},
+		{"https://ppa.launchpadcontent.net/juju/stable/ubuntu", ""},
+		{"ppa:juju/stable; reboot", `apt repository .* not valid: contains ';'`},
+		{"ppa:Juju/Stable", `apt repository .* not valid: PPA "Juju/Stable"`},
+		{"foo:bar", `apt repository .* not valid: unknown prefix "foo:"`},
+		{"--remove", `apt repository .* not valid: starts with a hyphen`},
+		{"deb http://archive.ubuntu.com/ubuntu", `deb line .* not valid: without URI or suite`},
+		{"deb [arch=amd64 http://archive.ubuntu.com/ubuntu jammy main", `deb line .* not valid: option "http://archive.ubuntu.com/ubuntu"`},
+		{"deb http://archive.ubuntu.com/ubuntu jammy main\ndeb http://evil jammy main", `deb line .* not valid: contains '\\n'`},
+		{"deb http://archive.ubuntu.com/ubuntu jammy main;reboot", `deb line .* not valid: suite or component "main;reboot"`},
+	})
+}
+
+func (s *ValidateSuite) TestValidatePPA(c *gc.C) {
+	checkValidate(c, commands.ValidatePPA, []validateTest{
+		{"juju/stable", ""},
+		{"juju", ""},
+		{"ppa:juju/stable", `PPA .* not valid: expected "owner/name"`},
+		{"juju/stable/extra", `PPA .* not valid: expected "owner/name"`},
+	})
+}
+
+func (s *ValidateSuite) TestCommanderValidatePackage(c *gc.C) {
+	for i, test := range []struct {
+		commander commands.Commander
+		valid     []string
+		invalid   []string
+	}{{
+		commander: commands.NewAptCommander(),
+		valid:     []string{"bash", "bash=5.1-6"},
+		invalid:   []string{"bash-5.1.8-6.el9.x86_64", "--purge"},
+	}, {
+		commander: commands.NewYumCommander(),
+		valid:     []string{"bash", "bash-5.1.8-6.el9.x86_64"},
+		invalid:   []string{"bash=5.1-6", "-y"},
+	}, {
+		commander: commands.NewDnfCommander(),
+		valid:     []string{"bash.x86_64"},
+		invalid:   []string{"bash;reboot"},
+	}, {
+		commander: commands.NewZypperCommander(),
+		valid:     []string{"bash"},
+		invalid:   []string{"bash>5"},
+	}, {
+		commander: commands.NewApkCommander(),
+		valid:     []string{"bash", "bash@edge", "bash=5.2.15-r5", "bash~5.2"},
+		invalid:   []string{"--allow-untrusted", "bash>5.2", "bash=5 2"},
+	}, {
+		commander: commands.NewPacmanCommander(),
+		valid:     []string{"bash", "extra/python-pip", "lib32-glibc"},
+		invalid:   []string{"Bash", "--overwrite=*"},
+	}, {
+		commander: commands.NewSnapCommander(),
+		valid:     []string{"juju", "juju_2"},
+		invalid:   []string{"juju=3.1", "--classic"},
+	}} {
+		for _, pack := range test.valid {
+			c.Logf("test %d: %q", i, pack)
+			c.Check(test.commander.ValidatePackage(pack), jc.ErrorIsNil)
+		}
+		for _, pack := range test.invalid {
+			c.Logf("test %d: %q", i, pack)
+			c.Check(test.commander.ValidatePackage(pack), jc.Satisfies, commands.IsValidationError)
+		}
+	}
+}
+
+func (s *ValidateSuite) TestCommanderValidateRepository(c *gc.C) {
+	apt := commands.NewAptCommander()
+	c.Check(apt.ValidateAddRepository("ppa:juju/stable"), jc.ErrorIsNil)
+	c.Check(apt.ValidateRemoveRepository("juju/stable"), jc.ErrorIsNil)
+	c.Check(apt.ValidateRemoveRepository("ppa:juju/stable"), jc.Satisfies, commands.IsValidationError)
+
+	yum := commands.NewYumCommander()
+	c.Check(yum.ValidateAddRepository("https://download.docker.com/linux/centos/docker-ce.repo"), jc.ErrorIsNil)
+	c.Check(yum.ValidateAddRepository("/etc/yum.repos.d/local.repo"), jc.ErrorIsNil)
+	c.Check(yum.ValidateRemoveRepository("docker-ce-stable"), jc.ErrorIsNil)
+	c.Check(yum.ValidateAddRepository("local.repo; reboot"), jc.Satisfies, commands.IsValidationError)
+	c.Check(yum.ValidateRemoveRepository("--all"), jc.Satisfies, commands.IsValidationError)
+
+	apk := commands.NewApkCommander()
+	c.Check(apk.ValidateAddRepository("@edge https://dl-cdn.alpinelinux.org/alpine/edge/main"), jc.ErrorIsNil)
+	c.Check(apk.ValidateAddRepository("/srv/packages"), jc.ErrorIsNil)
+	c.Check(apk.ValidateAddRepository("@edge"), jc.Satisfies, commands.IsValidationError)
+	c.Check(apk.ValidateAddRepository("dl-cdn.alpinelinux.org/alpine"), jc.Satisfies, commands.IsValidationError)
+
+	c.Check(commands.NewSnapCommander().ValidateAddRepository("anything"), jc.ErrorIsNil)
+}
+
+func (s *ValidateSuite) TestIsValidationErrorTraced(c *gc.C) {
+	err := commands.ValidateSnapName("Juju")
+	c.Check(errors.Trace(err), jc.Satisfies, commands.IsValidationError)
+	c.Check(errors.New("Juju"), gc.Not(jc.Satisfies), commands.IsValidationError)
+}
@@ -4,6 +4,8 @@
 
 package commands
 
+import "github.com/juju/packaging/v3"
+
 const (
 	// CentOSSourcesDir is the default directory in which yum sourcefiles
 	// may be found.
@@ -29,8 +31,14 @@ const (
 	// the basic command for all yum repository configuration operations.
 	yumconf = "yum-config-manager"
 
+	// the basic command for querying the rpm database; the query format
+	// outputs the epoch:version-release of each matching package.
+	rpmQuery = "rpm -q --queryformat '%%{EPOCHNUM}:%%{VERSION}-%%{RELEASE}\\n'"
+
 	// the basic format for specifying a proxy setting for yum.
-	// NOTE: only http(s) proxies are relevant.
+	// NOTE: only http(s) proxies are relevant. yum.conf has no setting for
+	// the hosts which bypass the proxy, so NoProxy is not written; yum
+	// honours the no_proxy environment variable instead.
 	yumProxySettingFormat = "%s_proxy=%s"
 )
 
@@ -44,6 +52,8 @@ var yumCmder = packageCommander{
 	purge:               buildCommand(yum, "remove"), // purges by default
 	search:              buildCommand(yum, "list %s"),
 	isInstalled:         buildCommand(yum, "list installed %s"),
+	packageVersion:      buildCommand(rpmQuery, "%s"),
+	candidateVersion:    buildCommand(yum, "info %s"),
 	listAvailable:       buildCommand(yum, "list all"),
 	listInstalled:       buildCommand(yum, "list installed"),
 	listRepositories:    buildCommand(yum, "repolist all"),
@@ -53,4 +63,22 @@ var yumCmder = packageCommander{
 	getProxy:            buildCommand("grep -R \".*_proxy=\"", YumConfigFilePath),
 	proxySettingsFormat: yumProxySettingFormat,
 	setProxy:            buildCommand("echo %s >>", YumConfigFilePath),
+	proxyFile:           YumConfigFilePath,
+	renderSpec:          renderRPMSpec,
+	validatePackage:     ValidateRPMPackage,
+	validateAddRepo:     validateRPMRepository,
+	validateRemoveRepo:  validateRPMRepository,
+}
+
+// renderRPMSpec renders a package spec in the name[-version][.arch] syntax
+// understood by yum and other rpm-based package managers.
+func renderRPMSpec(spec packaging.PackageSpec) []string {
+	pack := spec.Name
+	if spec.Version != "" {
+		pack += "-" + spec.Version
+	}
+	if spec.Architecture != "" {
+		pack += "." + spec.Architecture
+	}
+	return []string{pack}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/juju/proxy"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 )
 
@@ -48,3 +49,24 @@ ftp_proxy=gimme-files.zone`
 	output := s.paccmder.ProxyConfigContents(sets)
 	c.Assert(output, gc.Equals, expected)
 }
+
+func (s *YumSuite) TestProxyConfigContentsNoProxy(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		NoProxy: "localhost, *.example.com,.internal,10.0.0.0/8,foo*bar,localhost",
+	}
+	// yum has no setting for the hosts which bypass the proxy.
+	expected := `http_proxy=dat-proxy.zone:8080`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *YumSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "bash", Version: "4.2.46-35.el7_9"},
+		{Name: "glibc", Architecture: "i686", Version: "2.17-326.el7_9"},
+	}...)
+	c.Assert(cmd, gc.Equals, "yum --assumeyes --debuglevel=1 install bash bash-4.2.46-35.el7_9 glibc-2.17-326.el7_9.i686")
+}
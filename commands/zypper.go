@@ -4,6 +4,8 @@
 
 package commands
 
+import "github.com/juju/packaging/v3"
+
 const (
 	// OpenSUSESourcesDir is the default directory in which openSUSE sourcefiles
 	// may be found.
@@ -31,6 +33,9 @@ const (
 
 	// OpenSUSE format for proxy environment variables
 	zypperProxySettingFormat = "%s_PROXY=%s"
+
+	// OpenSUSE format for the hosts which bypass the proxy
+	zypperNoProxySettingFormat = `NO_PROXY="%s"`
 )
 
 // zypperCmder is the packageCommander instantiation for zypper-based systems.
@@ -43,6 +48,8 @@ var zypperCmder = packageCommander{
 	purge:               buildCommand(zypper, "remove"), // No purges with zypper
 	search:              buildCommand(zypper, "search %s"),
 	isInstalled:         buildCommand(zypper, "search -i %s"),
+	packageVersion:      buildCommand(rpmQuery, "%s"),
+	candidateVersion:    buildCommand(zypper, "info %s"),
 	listAvailable:       buildCommand(zypper, "packages"),
 	listInstalled:       buildCommand(zypper, "packages -i"),
 	listRepositories:    buildCommand(zypper, "repos"),
@@ -56,5 +63,25 @@ var zypperCmder = packageCommander{
 		"echo %s >>",
 		OpenSUSEProxy),
 	setNoProxy:          buildCommand("echo %s >> ", OpenSUSEProxy),
+	proxyFile:           OpenSUSEProxy,
+	noProxyListFormat:   zypperNoProxySettingFormat,
+	noProxyHost:         curlNoProxyHost,
 	proxyLabelInCapital: true,
+	renderSpec:          renderZypperSpec,
+	validatePackage:     ValidateRPMPackage,
+	validateAddRepo:     validateRPMRepository,
+	validateRemoveRepo:  validateRPMRepository,
+}
+
+// renderZypperSpec renders a package spec in the name[.arch][=version]
+// capability syntax of zypper.
+func renderZypperSpec(spec packaging.PackageSpec) []string {
+	pack := spec.Name
+	if spec.Architecture != "" {
+		pack += "." + spec.Architecture
+	}
+	if spec.Version != "" {
+		pack += "=" + spec.Version
+	}
+	return []string{pack}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/juju/proxy"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 )
 
@@ -48,3 +49,24 @@ FTP_PROXY=gimme-files.zone`
 	output := s.paccmder.ProxyConfigContents(sets)
 	c.Assert(output, gc.Equals, expected)
 }
+
+func (s *ZypperSuite) TestProxyConfigContentsNoProxy(c *gc.C) {
+	sets := proxy.Settings{
+		Http:    "dat-proxy.zone:8080",
+		NoProxy: "localhost,*.example.com,10.0.0.0/8,10.0.0.0/99",
+	}
+	expected := `HTTP_PROXY=dat-proxy.zone:8080
+NO_PROXY="localhost,.example.com,10.0.0.0/8"`
+
+	output := s.paccmder.ProxyConfigContents(sets)
+	c.Assert(output, gc.Equals, expected)
+}
+
+func (s *ZypperSuite) TestInstallSpecCmd(c *gc.C) {
+	cmd := s.paccmder.InstallSpecCmd([]packaging.PackageSpec{
+		{Name: "bash"},
+		{Name: "bash", Version: "4.4-lp152.9.13"},
+		{Name: "glibc", Architecture: "i586", Version: "2.26-lp152.26.12.1"},
+	}...)
+	c.Assert(cmd, gc.Equals, "zypper --quiet --non-interactive install bash bash=4.4-lp152.9.13 glibc.i586=2.26-lp152.26.12.1")
+}
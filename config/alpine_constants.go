@@ -0,0 +1,16 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+// AlpineDefaultPackages is the default package set we'd like installed
+// on all Alpine machines.
+var AlpineDefaultPackages = append(DefaultPackages, []string{
+	"bash",
+	"ca-certificates",
+}...)
+
+// cloudArchivePackagesAlpine maintains a list of Alpine packages that
+// Configurer.IsCloudArchivePackage will reference when determining the
+// repository from which to install a package.
+var cloudArchivePackagesAlpine = map[string]struct{}{}
@@ -0,0 +1,24 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"text/template"
+)
+
+const (
+	// ApkRepositoriesFile is the file which lists all the repositories
+	// apk installs packages from.
+	ApkRepositoriesFile = "/etc/apk/repositories"
+
+	// ApkKeyfileDir is the default directory for apk repository keys.
+	ApkKeyfileDir = "/etc/apk/keys/"
+)
+
+// ApkSourceTemplate is the template specific to the lines of the apk
+// repositories file for a source.
+var ApkSourceTemplate = template.Must(template.New("").Parse(`
+# {{.Name}} (added by Juju)
+{{.URL}}
+`[1:]))
@@ -0,0 +1,61 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/config"
+)
+
+var _ = gc.Suite(&ApkSuite{})
+
+type ApkSuite struct {
+	pacconfer config.PackagingConfigurer
+}
+
+func (s *ApkSuite) SetUpSuite(c *gc.C) {
+	s.pacconfer = config.NewApkPackagingConfigurer()
+}
+
+func (s *ApkSuite) TestNewPackagingConfigurer(c *gc.C) {
+	pacconfer, err := config.NewPackagingConfigurer("alpine")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pacconfer, gc.DeepEquals, s.pacconfer)
+}
+
+func (s *ApkSuite) TestDefaultPackages(c *gc.C) {
+	c.Assert(s.pacconfer.DefaultPackages(), gc.DeepEquals, config.AlpineDefaultPackages)
+	c.Assert(s.pacconfer.DefaultPackages(), jc.SameContents, []string{"curl", "bash", "ca-certificates"})
+}
+
+func (s *ApkSuite) TestIsCloudArchivePackage(c *gc.C) {
+	for i, pack := range []string{"random", "mongodb", "cloud-utils"} {
+		c.Logf("Test %d: package %s:", i+1, pack)
+		res := s.pacconfer.IsCloudArchivePackage(pack)
+		_, there := config.CloudArchivePackagesAlpine[pack]
+
+		c.Assert(res, gc.Equals, there)
+	}
+}
+
+func (s *ApkSuite) TestRenderSource(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(testedSource)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+some-source.com/packages
+`[1:])
+}
+
+func (s *ApkSuite) TestRenderPreferences(c *gc.C) {
+	res, err := s.pacconfer.RenderPreferences(testedPrefs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, "")
+}
+
+func (s *ApkSuite) TestApplyCloudArchiveTarget(c *gc.C) {
+	c.Assert(s.pacconfer.ApplyCloudArchiveTarget("bash"), gc.DeepEquals, []string{"bash"})
+}
@@ -13,6 +13,18 @@ const (
 	// sources for apt packages on an apt-based system.
 	AptSourcesFile = "/etc/apt/sources.list"
 
+	// AptSourcesDirectory is the directory in which additional sources
+	// for apt packages are stored, in either the one-line format in
+	// ".list" files or the deb822 format in ".sources" files.
+	AptSourcesDirectory = "/etc/apt/sources.list.d"
+
+	// AptPreferencesFile is the main apt_preferences(5) file.
+	AptPreferencesFile = "/etc/apt/preferences"
+
+	// AptPreferencesDirectory is the directory in which additional
+	// apt_preferences(5) files are stored.
+	AptPreferencesDirectory = "/etc/apt/preferences.d"
+
 	// AptListsDirectory is the location of the APT sources list.
 	AptListsDirectory = "/var/lib/apt/lists"
 
@@ -56,7 +68,28 @@ Pin-Priority: {{.Priority}}
 	// AptSourceTemplate is the template specific to an apt source file.
 	AptSourceTemplate = template.Must(template.New("").Parse(`
 # {{.Name}} (added by Juju)
-deb {{.URL}} %s main
-# deb-src {{.URL}} %s main
+deb {{with .SignedBy}}[signed-by={{.}}] {{end}}{{.URL}} %s main
+# deb-src {{with .SignedBy}}[signed-by={{.}}] {{end}}{{.URL}} %s main
 `[1:]))
+
+	// AptOneLineSourceTemplate is the template specific to an apt source
+	// file in the one-line format, for sources with their suites given.
+	// The entries of disabled sources are commented out.
+	AptOneLineSourceTemplate = template.Must(template.New("").Funcs(aptTemplateFuncs).Parse(`
+# {{.Name}} (added by Juju)
+{{range $type := debTypes .Types}}{{range $suite := $.Suites}}{{if $.Disabled}}# {{end}}{{$type}} {{aptOptions $}}{{$.URL}} {{$suite}}{{range $.Components}} {{.}}{{end}}
+{{end}}{{end}}`[1:]))
+
+	// AptDeb822SourceTemplate is the template specific to an apt source
+	// file in the deb822 format.
+	AptDeb822SourceTemplate = template.Must(template.New("").Funcs(aptTemplateFuncs).Parse(`
+# {{.Name}} (added by Juju)
+Types: {{join (debTypes .Types) " "}}
+URIs: {{.URL}}
+Suites: {{join .Suites " "}}
+{{with .Components}}Components: {{join . " "}}
+{{end}}{{with .Architectures}}Architectures: {{join . " "}}
+{{end}}{{with .SignedBy}}Signed-By:{{deb822Value .}}
+{{end}}{{if .Disabled}}Enabled: no
+{{end}}`[1:]))
 )
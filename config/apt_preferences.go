@@ -0,0 +1,292 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+// ParseAptPin parses the value of the Pin field of apt_preferences(5),
+// such as "release a=jammy-updates,o=Ubuntu", "origin ppa.launchpad.net"
+// or "version 1.2*". A release pin with a value without a key, such as
+// "release 22.04", pins the release version. An error satisfying
+// errors.IsNotValid is returned for malformed pins.
+func ParseAptPin(s string) (packaging.Pin, error) {
+	var pin packaging.Pin
+	kind, value, _ := strings.Cut(strings.TrimSpace(s), " ")
+	value = strings.TrimSpace(value)
+
+	switch packaging.PinType(kind) {
+	case packaging.PinOrigin:
+		pin.Type = packaging.PinOrigin
+		pin.Origin = unquotePinValue(value)
+		if strings.ContainsAny(pin.Origin, " \t") {
+			return pin, errors.NotValidf("pin %q", s)
+		}
+	case packaging.PinVersion:
+		pin.Type = packaging.PinVersion
+		pin.Version = unquotePinValue(value)
+		if pin.Version == "" || strings.ContainsAny(pin.Version, " \t") {
+			return pin, errors.NotValidf("pin %q", s)
+		}
+	case packaging.PinRelease:
+		pin.Type = packaging.PinRelease
+		if value == "*" {
+			break
+		}
+		for _, field := range strings.Split(value, ",") {
+			key, val, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				key, val = "v", key
+			}
+			val = unquotePinValue(val)
+			if val == "" {
+				return pin, errors.NotValidf("pin %q", s)
+			}
+			switch strings.TrimSpace(key) {
+			case "a":
+				pin.Release.Archive = val
+			case "n":
+				pin.Release.Codename = val
+			case "v":
+				pin.Release.Version = val
+			case "o":
+				pin.Release.Origin = val
+			case "l":
+				pin.Release.Label = val
+			case "c":
+				pin.Release.Component = val
+			case "b":
+				pin.Release.Architecture = val
+			default:
+				return pin, errors.NotValidf("pin %q release property %q", s, key)
+			}
+		}
+	default:
+		return pin, errors.NotValidf("pin %q", s)
+	}
+	return pin, nil
+}
+
+// unquotePinValue trims the whitespace and double quotes around the
+// given value of a pin.
+func unquotePinValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
+
+// validateAptPreferences returns an error satisfying errors.IsNotValid if
+// the given preferences cannot be rendered as an apt_preferences(5)
+// stanza.
+func validateAptPreferences(prefs packaging.PackagePreferences) error {
+	if _, err := ParseAptPin(prefs.Pin); err != nil {
+		return errors.Trace(err)
+	}
+	if strings.Contains(prefs.Explanation, "\n") {
+		return errors.NotValidf("multi-line explanation %q", prefs.Explanation)
+	}
+	if prefs.Priority == 0 {
+		return errors.NotValidf("pin priority 0 for %q", prefs.Package)
+	}
+	_, err := prefs.MatchesPackage("")
+	return errors.Trace(err)
+}
+
+// RenderAptPreferences renders the given preferences as the stanzas of a
+// single apt_preferences(5) file, after validating their pins and package
+// patterns. An error satisfying errors.IsNotValid is returned for invalid
+// preferences.
+func RenderAptPreferences(prefs ...packaging.PackagePreferences) (string, error) {
+	stanzas := make([]string, len(prefs))
+	for i, p := range prefs {
+		if err := validateAptPreferences(p); err != nil {
+			return "", errors.Trace(err)
+		}
+		stanza, err := p.RenderPreferenceFile(AptPreferenceTemplate)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		stanzas[i] = stanza
+	}
+	return strings.Join(stanzas, "\n"), nil
+}
+
+// ParseAptPreferences parses the stanzas of an apt_preferences(5) file,
+// such as those rendered by RenderAptPreferences. Multiple Explanation
+// fields of a stanza are joined by spaces. An error satisfying
+// errors.IsNotValid is returned for malformed stanzas.
+func ParseAptPreferences(contents string) ([]packaging.PackagePreferences, error) {
+	var (
+		res       []packaging.PackagePreferences
+		fields    = make(map[string]string)
+		startLine int
+	)
+	endStanza := func() error {
+		if len(fields) > 0 {
+			prefs, err := aptPreferencesStanza(fields)
+			if err != nil {
+				return errors.Annotatef(err, "stanza at line %d", startLine)
+			}
+			res = append(res, prefs)
+		}
+		fields = make(map[string]string)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			if err := endStanza(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, errors.NotValidf("line %d %q", lineNo, line)
+			}
+			if len(fields) == 0 {
+				startLine = lineNo
+			}
+			key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+			if prev, ok := fields[key]; ok && key == "explanation" {
+				value = strings.TrimSpace(prev + " " + value)
+			}
+			fields[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := endStanza(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// aptPreferencesStanza returns the preferences of the stanza with the
+// given fields, whose keys are lower case.
+func aptPreferencesStanza(fields map[string]string) (packaging.PackagePreferences, error) {
+	prefs := packaging.PackagePreferences{
+		Explanation: fields["explanation"],
+		Package:     fields["package"],
+		Pin:         fields["pin"],
+	}
+	switch {
+	case prefs.Package == "":
+		return prefs, errors.NotValidf("stanza without Package")
+	case prefs.Pin == "":
+		return prefs, errors.NotValidf("stanza without Pin")
+	}
+	if _, err := ParseAptPin(prefs.Pin); err != nil {
+		return prefs, errors.Trace(err)
+	}
+	priority, err := strconv.Atoi(fields["pin-priority"])
+	if err != nil {
+		return prefs, errors.NotValidf("Pin-Priority %q", fields["pin-priority"])
+	}
+	prefs.Priority = priority
+	return prefs, nil
+}
+
+// aptPreferencesNameRE matches the names of the files apt reads from
+// AptPreferencesDirectory.
+var aptPreferencesNameRE = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// ReadAptPreferences reads the preferences configured in AptPreferencesFile
+// and AptPreferencesDirectory of the filesystem tree at the given root,
+// which is "/" for the running system. The Path of each of the preferences
+// is set to the file it is configured in. As with apt, only the files of
+// the directory without an extension or with the ".pref" extension are
+// read. Missing files and directories are skipped.
+func ReadAptPreferences(root string) ([]packaging.PackagePreferences, error) {
+	paths := []string{AptPreferencesFile}
+	entries, err := os.ReadDir(filepath.Join(root, AptPreferencesDirectory))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Trace(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := filepath.Ext(name)
+		if entry.IsDir() || !aptPreferencesNameRE.MatchString(name) || (ext != "" && ext != ".pref") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		paths = append(paths, AptPreferencesDirectory+"/"+name)
+	}
+
+	var res []packaging.PackagePreferences
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		prefs, err := ParseAptPreferences(string(data))
+		if err != nil {
+			return nil, errors.Annotatef(err, "parsing %s", path)
+		}
+		for i := range prefs {
+			prefs[i].Path = path
+		}
+		res = append(res, prefs...)
+	}
+	return res, nil
+}
+
+// aptPreferencesKey returns the key which identifies the stanzas pinning
+// the same packages to the same releases, regardless of how their
+// patterns and pins are spelled.
+func aptPreferencesKey(prefs packaging.PackagePreferences) string {
+	pin := strings.TrimSpace(prefs.Pin)
+	if parsed, err := ParseAptPin(pin); err == nil {
+		pin = parsed.String()
+	}
+	return strings.Join(prefs.PackagePatterns(), " ") + "\x00" + pin
+}
+
+// MergeAptPreferences merges the desired preferences into the existing
+// ones, such as those read by ReadAptPreferences. Existing stanzas pinning
+// the same packages to the same releases as desired ones are replaced in
+// place, whereas the other desired stanzas are appended, so that the
+// pins of other parties are preserved rather than overwritten.
+func MergeAptPreferences(existing, desired []packaging.PackagePreferences) []packaging.PackagePreferences {
+	res := append([]packaging.PackagePreferences(nil), existing...)
+	index := make(map[string]int)
+	for i, prefs := range res {
+		if _, ok := index[aptPreferencesKey(prefs)]; !ok {
+			index[aptPreferencesKey(prefs)] = i
+		}
+	}
+	for _, prefs := range desired {
+		key := aptPreferencesKey(prefs)
+		if i, ok := index[key]; ok {
+			res[i] = prefs
+			continue
+		}
+		index[key] = len(res)
+		res = append(res, prefs)
+	}
+	return res
+}
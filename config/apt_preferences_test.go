@@ -0,0 +1,248 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+)
+
+var _ = gc.Suite(&AptPreferencesSuite{})
+
+type AptPreferencesSuite struct{}
+
+func (s *AptPreferencesSuite) TestParseAptPin(c *gc.C) {
+	for i, test := range []struct {
+		pin      string
+		expected packaging.Pin
+		str      string
+	}{{
+		pin: "release a=jammy-updates, o=Ubuntu",
+		expected: packaging.Pin{Type: packaging.PinRelease, Release: packaging.ReleasePin{
+			Archive: "jammy-updates", Origin: "Ubuntu",
+		}},
+		str: "release a=jammy-updates,o=Ubuntu",
+	}, {
+		pin: `release n=jammy,v=22.04,l="Ubuntu",c=main,b=amd64`,
+		expected: packaging.Pin{Type: packaging.PinRelease, Release: packaging.ReleasePin{
+			Codename: "jammy", Version: "22.04", Label: "Ubuntu", Component: "main", Architecture: "amd64",
+		}},
+		str: "release n=jammy,v=22.04,l=Ubuntu,c=main,b=amd64",
+	}, {
+		pin:      "release 22.04",
+		expected: packaging.Pin{Type: packaging.PinRelease, Release: packaging.ReleasePin{Version: "22.04"}},
+		str:      "release v=22.04",
+	}, {
+		pin:      "release *",
+		expected: packaging.Pin{Type: packaging.PinRelease},
+	}, {
+		pin:      "origin ppa.launchpad.net",
+		expected: packaging.Pin{Type: packaging.PinOrigin, Origin: "ppa.launchpad.net"},
+	}, {
+		pin:      `origin ""`,
+		expected: packaging.Pin{Type: packaging.PinOrigin},
+	}, {
+		pin:      "version 1.2*",
+		expected: packaging.Pin{Type: packaging.PinVersion, Version: "1.2*"},
+	}} {
+		c.Logf("test %d: %q", i, test.pin)
+		pin, err := config.ParseAptPin(test.pin)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(pin, gc.DeepEquals, test.expected)
+		if test.str == "" {
+			test.str = test.pin
+		}
+		c.Check(pin.String(), gc.Equals, test.str)
+	}
+}
+
+func (s *AptPreferencesSuite) TestParseAptPinInvalid(c *gc.C) {
+	for _, pin := range []string{
+		"", "releases/extra-special", "version", "origin a b", "release a=", "release x=foo",
+	} {
+		_, err := config.ParseAptPin(pin)
+		c.Check(err, jc.Satisfies, errors.IsNotValid, gc.Commentf("pin %q", pin))
+	}
+}
+
+func (s *AptPreferencesSuite) TestMatchesPackage(c *gc.C) {
+	prefs := packaging.PackagePreferences{Package: "juju-db lxd* /^mongodb-(server|clients)$/"}
+	for name, expected := range map[string]bool{
+		"juju-db":         true,
+		"juju":            false,
+		"lxd":             true,
+		"lxd-client":      true,
+		"mongodb-server":  true,
+		"mongodb-clients": true,
+		"mongodb":         false,
+	} {
+		matched, err := prefs.MatchesPackage(name)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(matched, gc.Equals, expected, gc.Commentf("package %q", name))
+	}
+
+	all := packaging.PackagePreferences{}
+	c.Assert(all.PackagePatterns(), gc.DeepEquals, []string{"*"})
+
+	invalid := packaging.PackagePreferences{Package: "/mongodb(/"}
+	_, err := invalid.MatchesPackage("mongodb")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+const testedAptPreferences = `# Managed by hand
+Explanation: prefer the cloud archive
+Explanation: for openstack
+Package: *
+Pin: release o=Canonical,a=jammy-updates/zed
+Pin-Priority: 600
+
+Package: lxd* /^juju-db$/
+Pin: origin ppa.launchpad.net
+Pin-Priority: -1
+`
+
+var testedAptPreferencesParsed = []packaging.PackagePreferences{{
+	Explanation: "prefer the cloud archive for openstack",
+	Package:     "*",
+	Pin:         "release o=Canonical,a=jammy-updates/zed",
+	Priority:    600,
+}, {
+	Package:  "lxd* /^juju-db$/",
+	Pin:      "origin ppa.launchpad.net",
+	Priority: -1,
+}}
+
+func (s *AptPreferencesSuite) TestParseAptPreferences(c *gc.C) {
+	prefs, err := config.ParseAptPreferences(testedAptPreferences)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(prefs, gc.DeepEquals, testedAptPreferencesParsed)
+}
+
+func (s *AptPreferencesSuite) TestRenderAptPreferencesRoundTrip(c *gc.C) {
+	rendered, err := config.RenderAptPreferences(testedAptPreferencesParsed...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rendered, gc.Equals, `
+Explanation: prefer the cloud archive for openstack
+Package: *
+Pin: release o=Canonical,a=jammy-updates/zed
+Pin-Priority: 600
+
+Explanation: 
+Package: lxd* /^juju-db$/
+Pin: origin ppa.launchpad.net
+Pin-Priority: -1
+`[1:])
+
+	prefs, err := config.ParseAptPreferences(rendered)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(prefs, gc.DeepEquals, testedAptPreferencesParsed)
+}
+
+func (s *AptPreferencesSuite) TestRenderAptPreferencesInvalid(c *gc.C) {
+	for i, test := range []struct {
+		prefs packaging.PackagePreferences
+		err   string
+	}{{
+		prefs: testedPrefs,
+		err:   `pin "releases/extra-special" not valid`,
+	}, {
+		prefs: packaging.PackagePreferences{Package: "/(/", Pin: "origin foo", Priority: 1},
+		err:   `package regular expression "/\(/" not valid`,
+	}, {
+		prefs: packaging.PackagePreferences{Package: "foo", Pin: "origin foo"},
+		err:   `pin priority 0 for "foo" not valid`,
+	}, {
+		prefs: packaging.PackagePreferences{Explanation: "a\nb", Pin: "origin foo", Priority: 1},
+		err:   `multi-line explanation "a\\nb" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := config.RenderAptPreferences(test.prefs)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *AptPreferencesSuite) TestParseAptPreferencesInvalid(c *gc.C) {
+	for i, test := range []struct {
+		contents string
+		err      string
+	}{{
+		contents: "Package: *\nPin-Priority: 1\n",
+		err:      `stanza at line 1: stanza without Pin not valid`,
+	}, {
+		contents: "\nPin: origin foo\nPin-Priority: 1\n",
+		err:      `stanza at line 2: stanza without Package not valid`,
+	}, {
+		contents: "Package: *\nPin: origin foo\nPin-Priority: high\n",
+		err:      `stanza at line 1: Pin-Priority "high" not valid`,
+	}, {
+		contents: "Package: *\nPin: foo\nPin-Priority: 1\n",
+		err:      `stanza at line 1: pin "foo" not valid`,
+	}, {
+		contents: "Package *\n",
+		err:      `line 1 "Package \*" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := config.ParseAptPreferences(test.contents)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *AptPreferencesSuite) TestReadAptPreferences(c *gc.C) {
+	root := c.MkDir()
+	for path, contents := range map[string]string{
+		"etc/apt/preferences":                    "Package: foo\nPin: version 1.0\nPin-Priority: 1001\n",
+		"etc/apt/preferences.d/cloud-archive":    testedAptPreferences,
+		"etc/apt/preferences.d/juju.pref":        "Package: juju\nPin: origin juju.example.com\nPin-Priority: 900\n",
+		"etc/apt/preferences.d/ignored.dpkg-old": "invalid",
+		"etc/apt/preferences.d/ignored~":         "invalid",
+	} {
+		path = filepath.Join(root, path)
+		c.Assert(os.MkdirAll(filepath.Dir(path), 0755), jc.ErrorIsNil)
+		c.Assert(os.WriteFile(path, []byte(contents), 0644), jc.ErrorIsNil)
+	}
+
+	prefs, err := config.ReadAptPreferences(root)
+	c.Assert(err, jc.ErrorIsNil)
+	var paths []string
+	for _, p := range prefs {
+		paths = append(paths, p.Path+": "+p.Package)
+	}
+	c.Assert(paths, gc.DeepEquals, []string{
+		"/etc/apt/preferences: foo",
+		"/etc/apt/preferences.d/cloud-archive: *",
+		"/etc/apt/preferences.d/cloud-archive: lxd* /^juju-db$/",
+		"/etc/apt/preferences.d/juju.pref: juju",
+	})
+
+	prefs, err = config.ReadAptPreferences(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(prefs, gc.HasLen, 0)
+}
+
+func (s *AptPreferencesSuite) TestMergeAptPreferences(c *gc.C) {
+	desired := []packaging.PackagePreferences{{
+		Package:  "*",
+		Pin:      "release a=jammy-updates/zed, o=Canonical",
+		Priority: 700,
+	}, {
+		Package:  "juju",
+		Pin:      "origin juju.example.com",
+		Priority: 900,
+	}}
+
+	merged := config.MergeAptPreferences(testedAptPreferencesParsed, desired)
+	c.Assert(merged, gc.DeepEquals, []packaging.PackagePreferences{
+		desired[0], testedAptPreferencesParsed[1], desired[1],
+	})
+	// The existing preferences are left alone.
+	c.Assert(testedAptPreferencesParsed[0].Priority, gc.Equals, 600)
+}
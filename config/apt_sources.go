@@ -0,0 +1,326 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+// aptTemplateFuncs are the functions available to the apt source templates.
+var aptTemplateFuncs = template.FuncMap{
+	"join":        strings.Join,
+	"debTypes":    debTypes,
+	"aptOptions":  aptOneLineOptions,
+	"deb822Value": deb822Value,
+}
+
+// debTypes returns the given archive types, defaulting to "deb".
+func debTypes(types []string) []string {
+	if len(types) == 0 {
+		return []string{"deb"}
+	}
+	return types
+}
+
+// aptOneLineOptions returns the options of a one-line apt source entry,
+// including their brackets and a trailing space, if there are any.
+func aptOneLineOptions(src packaging.PackageSource) string {
+	var opts []string
+	if len(src.Architectures) > 0 {
+		opts = append(opts, "arch="+strings.Join(src.Architectures, ","))
+	}
+	if src.SignedBy != "" {
+		opts = append(opts, "signed-by="+src.SignedBy)
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(opts, " ") + "] "
+}
+
+// deb822Value returns the given field value as it follows the colon of a
+// deb822 field: multi-line values start on the next line, with every line
+// indented and empty lines replaced by a dot.
+func deb822Value(value string) string {
+	if !strings.Contains(value, "\n") {
+		return " " + value
+	}
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(value, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			line = "."
+		}
+		sb.WriteString("\n " + line)
+	}
+	return sb.String()
+}
+
+// validateAptSource returns an error satisfying errors.IsNotValid if the
+// given source cannot be rendered as a complete apt source entry. Only the
+// deb822 format can embed an armored key in SignedBy.
+func validateAptSource(src packaging.PackageSource, deb822 bool) error {
+	switch {
+	case src.URL == "":
+		return errors.NotValidf("apt source %q without URL", src.Name)
+	case len(src.Suites) == 0:
+		return errors.NotValidf("apt source %q without suites", src.Name)
+	case !deb822 && strings.Contains(src.SignedBy, "\n"):
+		return errors.NotValidf("apt source %q with armored key in one-line format", src.Name)
+	}
+	for _, t := range src.Types {
+		if t != "deb" && t != "deb-src" {
+			return errors.NotValidf("apt source %q type %q", src.Name, t)
+		}
+	}
+	return nil
+}
+
+// aptSourceNameRE matches the comment preceding the sources added by Juju.
+var aptSourceNameRE = regexp.MustCompile(`^#\s*(.*\S)\s+\(added by Juju\)$`)
+
+// ParseAptSources parses the contents of an apt sources file in either the
+// one-line or the deb822 format, such as those rendered by the
+// PackagingConfigurer for apt-based systems. The names of the sources are
+// taken from the comments preceding them, if they were added by Juju.
+// An error satisfying errors.IsNotValid is returned for malformed entries.
+func ParseAptSources(contents string) ([]packaging.PackageSource, error) {
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if fields := strings.Fields(line); fields[0] == "deb" || fields[0] == "deb-src" {
+			return parseAptOneLineSources(contents)
+		}
+		break
+	}
+	return parseAptDeb822Sources(contents)
+}
+
+// parseAptOneLineSources parses a sources file in the one-line format.
+// Consecutive entries which only differ in their type or suite are merged
+// into a single source.
+func parseAptOneLineSources(contents string) ([]packaging.PackageSource, error) {
+	var (
+		res  []packaging.PackageSource
+		name string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if match := aptSourceNameRE.FindStringSubmatch(line); match != nil {
+				name = match[1]
+			}
+			continue
+		}
+
+		src, err := parseAptOneLineEntry(line)
+		if err != nil {
+			return nil, errors.Annotatef(err, "line %d", lineNo)
+		}
+		src.Name = name
+
+		if n := len(res); n > 0 && sameAptEntry(res[n-1], src) {
+			last := &res[n-1]
+			last.Types = appendMissing(last.Types, src.Types...)
+			last.Suites = appendMissing(last.Suites, src.Suites...)
+			continue
+		}
+		res = append(res, src)
+	}
+	return res, errors.Trace(scanner.Err())
+}
+
+// parseAptOneLineEntry parses a single entry of the one-line format:
+//
+//	deb [arch=amd64,arm64 signed-by=/usr/share/keyrings/foo.gpg] URI suite [component...]
+func parseAptOneLineEntry(line string) (packaging.PackageSource, error) {
+	var src packaging.PackageSource
+	fields := strings.Fields(line)
+	if fields[0] != "deb" && fields[0] != "deb-src" {
+		return src, errors.NotValidf("apt source type %q", fields[0])
+	}
+	src.Types = []string{fields[0]}
+	fields = fields[1:]
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "[") {
+		end := -1
+		for i, field := range fields {
+			if strings.HasSuffix(field, "]") {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return src, errors.NotValidf("apt source options %q", line)
+		}
+		options := strings.Join(fields[:end+1], " ")
+		options = strings.TrimSuffix(strings.TrimPrefix(options, "["), "]")
+		for _, option := range strings.Fields(options) {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "arch":
+				src.Architectures = strings.Split(value, ",")
+			case "signed-by":
+				src.SignedBy = value
+			}
+		}
+		fields = fields[end+1:]
+	}
+
+	if len(fields) < 2 {
+		return src, errors.NotValidf("apt source %q without URI or suite", line)
+	}
+	src.URL = fields[0]
+	src.Suites = []string{fields[1]}
+	if len(fields) > 2 {
+		src.Components = fields[2:]
+	}
+	return src, nil
+}
+
+// sameAptEntry returns whether the given sources only differ in their
+// types and suites.
+func sameAptEntry(a, b packaging.PackageSource) bool {
+	return a.Name == b.Name && a.URL == b.URL && a.SignedBy == b.SignedBy &&
+		strings.Join(a.Components, " ") == strings.Join(b.Components, " ") &&
+		strings.Join(a.Architectures, " ") == strings.Join(b.Architectures, " ")
+}
+
+// appendMissing appends those of the given values to list which it does not
+// already contain.
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// parseAptDeb822Sources parses a sources file in the deb822 format, where
+// each paragraph of fields describes a source for each of its URIs.
+func parseAptDeb822Sources(contents string) ([]packaging.PackageSource, error) {
+	var (
+		res       []packaging.PackageSource
+		name      string
+		fields    = make(map[string]string)
+		lastField string
+		startLine int
+	)
+	endParagraph := func() error {
+		if len(fields) > 0 {
+			srcs, err := deb822ParagraphSources(name, fields)
+			if err != nil {
+				return errors.Annotatef(err, "paragraph at line %d", startLine)
+			}
+			res = append(res, srcs...)
+		}
+		name, fields, lastField = "", make(map[string]string), ""
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			if err := endParagraph(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, "#"):
+			if match := aptSourceNameRE.FindStringSubmatch(line); match != nil && len(fields) == 0 {
+				name = match[1]
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			if lastField == "" {
+				return nil, errors.NotValidf("line %d continuation without field", lineNo)
+			}
+			value := strings.TrimSpace(line)
+			if value == "." {
+				value = ""
+			}
+			if fields[lastField] != "" {
+				value = "\n" + value
+			}
+			fields[lastField] += value
+		default:
+			key, value, ok := strings.Cut(line, ":")
+			if !ok || strings.TrimSpace(key) == "" {
+				return nil, errors.NotValidf("line %d %q", lineNo, line)
+			}
+			if len(fields) == 0 {
+				startLine = lineNo
+			}
+			lastField = strings.ToLower(strings.TrimSpace(key))
+			fields[lastField] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := endParagraph(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// deb822ParagraphSources returns the sources described by the given fields
+// of a deb822 paragraph, whose keys are lower case.
+func deb822ParagraphSources(name string, fields map[string]string) ([]packaging.PackageSource, error) {
+	for _, required := range []string{"types", "uris", "suites"} {
+		if fields[required] == "" {
+			return nil, errors.NotValidf("apt source without %s", required)
+		}
+	}
+
+	disabled := false
+	switch enabled := strings.ToLower(fields["enabled"]); enabled {
+	case "", "yes":
+	case "no":
+		disabled = true
+	default:
+		return nil, errors.NotValidf("apt source Enabled %q", enabled)
+	}
+
+	var res []packaging.PackageSource
+	for _, uri := range strings.Fields(fields["uris"]) {
+		src := packaging.PackageSource{
+			Name:          name,
+			URL:           uri,
+			Types:         strings.Fields(fields["types"]),
+			Suites:        strings.Fields(fields["suites"]),
+			Components:    strings.Fields(fields["components"]),
+			Architectures: strings.Fields(fields["architectures"]),
+			SignedBy:      fields["signed-by"],
+			Disabled:      disabled,
+		}
+		if len(src.Components) == 0 {
+			src.Components = nil
+		}
+		if len(src.Architectures) == 0 {
+			src.Architectures = nil
+		}
+		res = append(res, src)
+	}
+	return res, nil
+}
@@ -5,9 +5,13 @@
 package config_test
 
 import (
+	"strings"
+
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/config"
 )
 
@@ -70,3 +74,226 @@ func (s *AptSuite) TestApplyCloudArchiveTarget(c *gc.C) {
 		"some-package",
 	})
 }
+
+var testedAptSource = packaging.PackageSource{
+	Name:          "Some Totally Official Source.",
+	URL:           "http://some-source.com/ubuntu",
+	Types:         []string{"deb", "deb-src"},
+	Suites:        []string{"jammy", "jammy-updates"},
+	Components:    []string{"main", "universe"},
+	Architectures: []string{"amd64", "arm64"},
+	SignedBy:      "/usr/share/keyrings/some-source.gpg",
+}
+
+func (s *AptSuite) TestRenderSourceLegacyUnchanged(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(testedSource)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+deb some-source.com/packages %s main
+# deb-src some-source.com/packages %s main
+`[1:])
+}
+
+func (s *AptSuite) TestRenderSourceOneLine(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(testedAptSource)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+deb [arch=amd64,arm64 signed-by=/usr/share/keyrings/some-source.gpg] http://some-source.com/ubuntu jammy main universe
+deb [arch=amd64,arm64 signed-by=/usr/share/keyrings/some-source.gpg] http://some-source.com/ubuntu jammy-updates main universe
+deb-src [arch=amd64,arm64 signed-by=/usr/share/keyrings/some-source.gpg] http://some-source.com/ubuntu jammy main universe
+deb-src [arch=amd64,arm64 signed-by=/usr/share/keyrings/some-source.gpg] http://some-source.com/ubuntu jammy-updates main universe
+`[1:])
+}
+
+func (s *AptSuite) TestRenderSourceOneLineMinimal(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(packaging.PackageSource{
+		Name:   "flat",
+		URL:    "http://flat.example.com/repo",
+		Suites: []string{"./"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# flat (added by Juju)
+deb http://flat.example.com/repo ./
+`[1:])
+}
+
+func (s *AptSuite) TestRenderDeb822Source(c *gc.C) {
+	renderer, ok := s.pacconfer.(config.Deb822SourceRenderer)
+	c.Assert(ok, jc.IsTrue)
+
+	res, err := renderer.RenderDeb822Source(testedAptSource)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+Types: deb deb-src
+URIs: http://some-source.com/ubuntu
+Suites: jammy jammy-updates
+Components: main universe
+Architectures: amd64 arm64
+Signed-By: /usr/share/keyrings/some-source.gpg
+`[1:])
+}
+
+func (s *AptSuite) TestRenderDeb822SourceArmoredKey(c *gc.C) {
+	renderer := s.pacconfer.(config.Deb822SourceRenderer)
+	src := packaging.PackageSource{
+		Name:     "keyed",
+		URL:      "http://keyed.example.com/ubuntu",
+		Suites:   []string{"noble"},
+		SignedBy: "-----BEGIN PGP PUBLIC KEY BLOCK-----\n\nmQINBGPk\n-----END PGP PUBLIC KEY BLOCK-----\n",
+	}
+
+	res, err := renderer.RenderDeb822Source(src)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# keyed (added by Juju)
+Types: deb
+URIs: http://keyed.example.com/ubuntu
+Suites: noble
+Signed-By:
+ -----BEGIN PGP PUBLIC KEY BLOCK-----
+ .
+ mQINBGPk
+ -----END PGP PUBLIC KEY BLOCK-----
+`[1:])
+
+	srcs, err := config.ParseAptSources(res)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.HasLen, 1)
+	c.Assert(srcs[0].SignedBy, gc.Equals, strings.TrimSuffix(src.SignedBy, "\n"))
+
+	// Armored keys cannot be given in the one-line format.
+	_, err = s.pacconfer.RenderSource(src)
+	c.Assert(err, gc.ErrorMatches, `apt source "keyed" with armored key in one-line format not valid`)
+}
+
+func (s *AptSuite) TestRenderSourceInvalid(c *gc.C) {
+	renderer := s.pacconfer.(config.Deb822SourceRenderer)
+	for i, test := range []struct {
+		src packaging.PackageSource
+		err string
+	}{{
+		src: packaging.PackageSource{Name: "foo", URL: "http://foo"},
+		err: `apt source "foo" without suites not valid`,
+	}, {
+		src: packaging.PackageSource{Name: "foo", Suites: []string{"jammy"}},
+		err: `apt source "foo" without URL not valid`,
+	}, {
+		src: packaging.PackageSource{Name: "foo", URL: "http://foo", Suites: []string{"jammy"}, Types: []string{"rpm"}},
+		err: `apt source "foo" type "rpm" not valid`,
+	}} {
+		c.Logf("test %d", i)
+		_, err := renderer.RenderDeb822Source(test.src)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *AptSuite) TestParseAptSourcesRoundTrip(c *gc.C) {
+	renderer := s.pacconfer.(config.Deb822SourceRenderer)
+	oneLine, err := s.pacconfer.RenderSource(testedAptSource)
+	c.Assert(err, jc.ErrorIsNil)
+	deb822, err := renderer.RenderDeb822Source(testedAptSource)
+	c.Assert(err, jc.ErrorIsNil)
+
+	for _, contents := range []string{oneLine, deb822} {
+		srcs, err := config.ParseAptSources(contents)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{testedAptSource})
+	}
+}
+
+func (s *AptSuite) TestParseAptSourcesOneLine(c *gc.C) {
+	srcs, err := config.ParseAptSources(`
+# See sources.list(5) for how to upgrade to newer versions of the distribution.
+deb http://archive.ubuntu.com/ubuntu/ jammy main restricted
+# deb-src http://archive.ubuntu.com/ubuntu/ jammy main restricted
+deb http://archive.ubuntu.com/ubuntu/ jammy-updates main restricted
+
+deb [ arch=amd64 ] http://security.ubuntu.com/ubuntu jammy-security main
+`)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{{
+		URL:        "http://archive.ubuntu.com/ubuntu/",
+		Types:      []string{"deb"},
+		Suites:     []string{"jammy", "jammy-updates"},
+		Components: []string{"main", "restricted"},
+	}, {
+		URL:           "http://security.ubuntu.com/ubuntu",
+		Types:         []string{"deb"},
+		Suites:        []string{"jammy-security"},
+		Components:    []string{"main"},
+		Architectures: []string{"amd64"},
+	}})
+}
+
+func (s *AptSuite) TestParseAptSourcesDeb822(c *gc.C) {
+	srcs, err := config.ParseAptSources(`
+## Ubuntu sources have moved to /etc/apt/sources.list.d/ubuntu.sources
+Types: deb
+URIs: http://archive.ubuntu.com/ubuntu/ http://mirror.example.com/ubuntu/
+Suites: noble noble-updates
+Components: main restricted
+Signed-By: /usr/share/keyrings/ubuntu-archive-keyring.gpg
+
+# A flat repository
+types: deb
+uris: http://flat.example.com/repo
+suites: ./
+`)
+	c.Assert(err, jc.ErrorIsNil)
+	base := packaging.PackageSource{
+		Types:      []string{"deb"},
+		Suites:     []string{"noble", "noble-updates"},
+		Components: []string{"main", "restricted"},
+		SignedBy:   "/usr/share/keyrings/ubuntu-archive-keyring.gpg",
+	}
+	archive, mirror := base, base
+	archive.URL = "http://archive.ubuntu.com/ubuntu/"
+	mirror.URL = "http://mirror.example.com/ubuntu/"
+	c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{archive, mirror, {
+		URL:    "http://flat.example.com/repo",
+		Types:  []string{"deb"},
+		Suites: []string{"./"},
+	}})
+}
+
+func (s *AptSuite) TestParseAptSourcesInvalid(c *gc.C) {
+	for i, test := range []struct {
+		contents string
+		err      string
+	}{{
+		contents: "deb http://archive.ubuntu.com/ubuntu/\n",
+		err:      `line 1: apt source "deb http://archive.ubuntu.com/ubuntu/" without URI or suite not valid`,
+	}, {
+		contents: "deb [arch=amd64 http://archive.ubuntu.com/ubuntu/ jammy\n",
+		err:      `line 1: apt source options .* not valid`,
+	}, {
+		contents: "Types: deb\nURIs: http://archive.ubuntu.com/ubuntu/\n",
+		err:      `paragraph at line 1: apt source without suites not valid`,
+	}, {
+		contents: " continued\n",
+		err:      `line 1 continuation without field not valid`,
+	}} {
+		c.Logf("test %d: %q", i, test.contents)
+		_, err := config.ParseAptSources(test.contents)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *AptSuite) TestRenderSourceLegacySignedBy(c *gc.C) {
+	src := testedSource
+	src.SignedBy = "/usr/share/keyrings/some-source.gpg"
+
+	res, err := s.pacconfer.RenderSource(src)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+deb [signed-by=/usr/share/keyrings/some-source.gpg] some-source.com/packages %s main
+# deb-src [signed-by=/usr/share/keyrings/some-source.gpg] some-source.com/packages %s main
+`[1:])
+}
@@ -0,0 +1,15 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+// ArchDefaultPackages is the default package set we'd like installed
+// on all Arch Linux machines.
+var ArchDefaultPackages = append(DefaultPackages, []string{
+	"ca-certificates",
+}...)
+
+// cloudArchivePackagesArch maintains a list of Arch Linux packages that
+// Configurer.IsCloudArchivePackage will reference when determining the
+// repository from which to install a package.
+var cloudArchivePackagesArch = map[string]struct{}{}
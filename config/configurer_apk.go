@@ -0,0 +1,32 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"github.com/juju/packaging/v3"
+)
+
+// apkConfigurer is the PackagingConfigurer implementation for apk-based systems.
+type apkConfigurer struct {
+	*baseConfigurer
+}
+
+// RenderSource is defined on the PackagingConfigurer interface.
+// The source is rendered as lines to be appended to the apk repositories
+// file; its key, if any, has to be placed in ApkKeyfileDir separately.
+func (c *apkConfigurer) RenderSource(src packaging.PackageSource) (string, error) {
+	return src.RenderSourceFile(ApkSourceTemplate)
+}
+
+// RenderPreferences is defined on the PackagingConfigurer interface.
+func (c *apkConfigurer) RenderPreferences(src packaging.PackagePreferences) (string, error) {
+	// apk has no package pinning other than repository tags, which are
+	// requested per package at installation.
+	return "", nil
+}
+
+// ApplyCloudArchiveTarget is defined on the PackagingConfigurer interface.
+func (c *apkConfigurer) ApplyCloudArchiveTarget(pack string) []string {
+	return []string{pack}
+}
@@ -14,8 +14,39 @@ type aptConfigurer struct {
 }
 
 // RenderSource is defined on the PackagingConfigurer interface.
+// Sources with their suites given are rendered in the one-line format,
+// otherwise the legacy template is used.
 func (c *aptConfigurer) RenderSource(src packaging.PackageSource) (string, error) {
-	return src.RenderSourceFile(AptSourceTemplate)
+	src, err := withoutCredentials(src)
+	if err != nil {
+		return "", err
+	}
+	if len(src.Suites) == 0 {
+		return src.RenderSourceFile(AptSourceTemplate)
+	}
+	if err := validateAptSource(src, false); err != nil {
+		return "", err
+	}
+	return src.RenderSourceFile(AptOneLineSourceTemplate)
+}
+
+// RenderDeb822Source is defined on the Deb822SourceRenderer interface.
+func (c *aptConfigurer) RenderDeb822Source(src packaging.PackageSource) (string, error) {
+	src, err := withoutCredentials(src)
+	if err != nil {
+		return "", err
+	}
+	if err := validateAptSource(src, true); err != nil {
+		return "", err
+	}
+	return src.RenderSourceFile(AptDeb822SourceTemplate)
+}
+
+// RenderCredentials is defined on the CredentialsRenderer interface.
+// The credentials are written to a file in AptAuthDirectory, with the
+// host and path of the URL of the source as its machine.
+func (c *aptConfigurer) RenderCredentials(src packaging.PackageSource) (string, string, error) {
+	return renderAptCredentials(src)
 }
 
 // RenderPreferences is defined on the PackagingConfigurer interface.
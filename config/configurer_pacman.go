@@ -0,0 +1,34 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"github.com/juju/packaging/v3"
+)
+
+// pacmanConfigurer is the PackagingConfigurer implementation for
+// pacman-based systems.
+type pacmanConfigurer struct {
+	*baseConfigurer
+}
+
+// RenderSource is defined on the PackagingConfigurer interface.
+// The source is rendered as a repository section to be appended to
+// PacmanConfigFile; its key, if any, has to be imported into the pacman
+// keyring separately.
+func (c *pacmanConfigurer) RenderSource(src packaging.PackageSource) (string, error) {
+	return src.RenderSourceFile(PacmanSourceTemplate)
+}
+
+// RenderPreferences is defined on the PackagingConfigurer interface.
+func (c *pacmanConfigurer) RenderPreferences(src packaging.PackagePreferences) (string, error) {
+	// pacman has no package pinning other than the order of the
+	// repository sections, which is given by the configuration file.
+	return "", nil
+}
+
+// ApplyCloudArchiveTarget is defined on the PackagingConfigurer interface.
+func (c *pacmanConfigurer) ApplyCloudArchiveTarget(pack string) []string {
+	return []string{pack}
+}
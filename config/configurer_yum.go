@@ -14,15 +14,27 @@ type yumConfigurer struct {
 }
 
 // RenderSource is defined on the PackagingConfigurer interface.
+// The credentials of the source are rendered as its username and
+// password, so the file has to be readable by root only.
 func (c *yumConfigurer) RenderSource(src packaging.PackageSource) (string, error) {
+	src, err := withoutCredentials(src)
+	if err != nil {
+		return "", err
+	}
 	return src.RenderSourceFile(YumSourceTemplate)
 }
 
 // RenderPreferences is defined on the PackagingConfigurer interface.
-func (c *yumConfigurer) RenderPreferences(src packaging.PackagePreferences) (string, error) {
-	// TODO (aznashwan): research a way of using yum-priorities in the context
-	// of single/multiple package pinning and implement it.
-	return "", nil
+// The preferences are rendered as the yum repository options they
+// translate to, such as the priority and package exclusions, which
+// belong in the section of the pinned repository.
+func (c *yumConfigurer) RenderPreferences(prefs packaging.PackagePreferences) (string, error) {
+	return renderRPMPreferences(prefs, true)
+}
+
+// ApplyPreferences is defined on the PreferencesApplier interface.
+func (c *yumConfigurer) ApplyPreferences(src packaging.PackageSource, prefs ...packaging.PackagePreferences) (packaging.PackageSource, error) {
+	return applyRPMPreferences(src, prefs, true)
 }
 
 // ApplyCloudArchiveTarget is defined on the PackagingConfigurer interface.
@@ -15,14 +15,31 @@ type zypperConfigurer struct {
 
 // RenderSource is defined on the PackagingConfigurer interface.
 func (c *zypperConfigurer) RenderSource(src packaging.PackageSource) (string, error) {
+	src, err := withoutCredentials(src)
+	if err != nil {
+		return "", err
+	}
 	return src.RenderSourceFile(ZypperSourceTemplate)
 }
 
+// RenderCredentials is defined on the CredentialsRenderer interface.
+// The credentials are written to a file in ZypperCredentialsDir, which
+// the URL of the rendered source references.
+func (c *zypperConfigurer) RenderCredentials(src packaging.PackageSource) (string, string, error) {
+	return renderZypperCredentials(src)
+}
+
 // RenderPreferences is defined on the PackagingConfigurer interface.
-func (c *zypperConfigurer) RenderPreferences(src packaging.PackagePreferences) (string, error) {
-	// TODO (aznashwan): research a way of using zypper-priorities in the context
-	// of single/multiple package pinning and implement it.
-	return "", nil
+// The preferences are rendered as the zypper repository options they
+// translate to, such as the priority, which belong in the section of the
+// pinned repository.
+func (c *zypperConfigurer) RenderPreferences(prefs packaging.PackagePreferences) (string, error) {
+	return renderRPMPreferences(prefs, false)
+}
+
+// ApplyPreferences is defined on the PreferencesApplier interface.
+func (c *zypperConfigurer) ApplyPreferences(src packaging.PackageSource, prefs ...packaging.PackagePreferences) (packaging.PackageSource, error) {
+	return applyRPMPreferences(src, prefs, false)
 }
 
 // ApplyCloudArchiveTarget is defined on the PackagingConfigurer interface.
@@ -0,0 +1,118 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+const (
+	// AptAuthDirectory is the directory of the files from which apt reads
+	// the credentials of authenticated sources.
+	AptAuthDirectory = "/etc/apt/auth.conf.d"
+
+	// ZypperCredentialsDir is the directory of the files holding the
+	// credentials of authenticated zypper repositories, which are
+	// referenced by the "credentials" parameter of the repository URL.
+	ZypperCredentialsDir = "/etc/zypp/credentials.d"
+)
+
+// withoutCredentials returns the given source with the userinfo removed
+// from its URL and its credentials set as its Username and Password
+// instead. Credentials containing line breaks result in an error
+// satisfying errors.IsNotValid.
+func withoutCredentials(src packaging.PackageSource) (packaging.PackageSource, error) {
+	src.Username, src.Password = src.Credentials()
+	if strings.ContainsAny(src.Username+src.Password, "\r\n") {
+		return src, errors.NotValidf("credentials of source %q with line breaks", src.Name)
+	}
+	if u, err := url.Parse(src.URL); err == nil && u.User != nil {
+		u.User = nil
+		src.URL = u.String()
+	}
+	return src, nil
+}
+
+// hasCredentials returns whether the given source, as returned by
+// withoutCredentials, has any credentials.
+func hasCredentials(src packaging.PackageSource) bool {
+	return src.Username != "" || src.Password != ""
+}
+
+// aptAuthMachine returns the machine of the apt auth.conf entry matching
+// the URL of the given source, which is its host and path.
+func aptAuthMachine(src packaging.PackageSource) (string, error) {
+	u, err := url.Parse(src.URL)
+	if err != nil || u.Host == "" {
+		return "", errors.NotValidf("URL %q of source %q", src.URL, src.Name)
+	}
+	return u.Host + strings.TrimSuffix(u.EscapedPath(), "/"), nil
+}
+
+// renderAptCredentials returns the path and contents of the auth.conf
+// file for the given source.
+func renderAptCredentials(src packaging.PackageSource) (string, string, error) {
+	src, err := withoutCredentials(src)
+	if err != nil || !hasCredentials(src) {
+		return "", "", err
+	}
+	// The netrc format of auth.conf has no quoting.
+	if strings.ContainsAny(src.Username+src.Password, " \t") {
+		return "", "", errors.NotValidf("credentials of source %q with whitespace", src.Name)
+	}
+	machine, err := aptAuthMachine(src)
+	if err != nil {
+		return "", "", errors.Trace(err)
+	}
+	contents := fmt.Sprintf("machine %s\nlogin %s\npassword %s\n", machine, src.Username, src.Password)
+	return path.Join(AptAuthDirectory, src.BaseName()+".conf"), contents, nil
+}
+
+// zypperRepositoryURL returns the URL of the given source, as returned by
+// withoutCredentials, which references its credentials file, if it has
+// credentials.
+func zypperRepositoryURL(src packaging.PackageSource) string {
+	if !hasCredentials(src) {
+		return src.URL
+	}
+	u, err := url.Parse(src.URL)
+	if err != nil {
+		return src.URL
+	}
+	query := u.Query()
+	query.Set("credentials", src.BaseName())
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// renderZypperCredentials returns the path and contents of the credentials
+// file for the given source.
+func renderZypperCredentials(src packaging.PackageSource) (string, string, error) {
+	src, err := withoutCredentials(src)
+	if err != nil || !hasCredentials(src) {
+		return "", "", err
+	}
+	contents := fmt.Sprintf("username=%s\npassword=%s\n", src.Username, src.Password)
+	return path.Join(ZypperCredentialsDir, src.BaseName()), contents, nil
+}
+
+// stripZypperCredentials removes the "credentials" parameter, which
+// references the credentials file of a repository, from the given URL.
+func stripZypperCredentials(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.Query().Has("credentials") {
+		return rawURL
+	}
+	query := u.Query()
+	query.Del("credentials")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
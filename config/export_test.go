@@ -8,4 +8,6 @@ var (
 	CloudArchivePackagesUbuntu   = cloudArchivePackagesUbuntu
 	CloudArchivePackagesCentOS   = cloudArchivePackagesCentOS
 	CloudArchivePackagesOpenSUSE = cloudArchivePackagesOpenSUSE
+	CloudArchivePackagesAlpine   = cloudArchivePackagesAlpine
+	CloudArchivePackagesArch     = cloudArchivePackagesArch
 )
@@ -35,12 +35,62 @@ type PackagingConfigurer interface {
 	RenderPreferences(prefs packaging.PackagePreferences) (string, error)
 }
 
+// Deb822SourceRenderer is implemented by the PackagingConfigurer for
+// apt-based systems, which can also render sources in the deb822 format
+// of ".sources" files.
+type Deb822SourceRenderer interface {
+	// RenderDeb822Source returns the full file contents of a given
+	// PackageSource in the deb822 format. The source must have its
+	// suites given.
+	RenderDeb822Source(src packaging.PackageSource) (string, error)
+}
+
+// PreferencesApplier is implemented by the PackagingConfigurers of
+// rpm-based systems, where package preferences are settings of the
+// repositories they pin rather than files of their own.
+type PreferencesApplier interface {
+	// ApplyPreferences returns the given source with those of the given
+	// preferences applied which pin it. There, the pin has to be
+	// "origin <repository>", with the name of the source as the
+	// repository. The preferences are translated into settings of the
+	// source, so that they mean the same as on apt-based systems:
+	//
+	//   - the priority of the preferences for all packages sets the
+	//     Priority of the source, see RPMPriority;
+	//   - a negative priority for some packages excludes those from the
+	//     source;
+	//   - a negative priority for all packages restricts the source to
+	//     the packages with a positive priority, or disables it if there
+	//     are none.
+	//
+	// Preferences which cannot be expressed, such as positive priorities
+	// for some packages without a negative priority for all packages, or
+	// any preferences for some packages on zypper, result in an error
+	// satisfying errors.IsNotSupported.
+	ApplyPreferences(src packaging.PackageSource, prefs ...packaging.PackagePreferences) (packaging.PackageSource, error)
+}
+
+// CredentialsRenderer is implemented by the PackagingConfigurers of
+// systems which read the credentials of authenticated sources from files
+// of their own, apart from the sources. Other systems render the
+// credentials into the source itself, which then has to be kept private.
+type CredentialsRenderer interface {
+	// RenderCredentials returns the path and the full file contents of
+	// the credentials of a given PackageSource, or empty ones if it has
+	// no credentials. The file has to be readable by root only.
+	RenderCredentials(src packaging.PackageSource) (path, contents string, err error)
+}
+
 func NewPackagingConfigurer(os string) (PackagingConfigurer, error) {
 	switch os {
 	case "centos":
 		return NewYumPackagingConfigurer(), nil
 	case "opensuse":
 		return NewZypperPackagingConfigurer(), nil
+	case "alpine":
+		return NewApkPackagingConfigurer(), nil
+	case "arch":
+		return NewPacmanPackagingConfigurer(), nil
 	default:
 		return NewAptPackagingConfigurer(), nil
 	}
@@ -68,3 +118,20 @@ func NewZypperPackagingConfigurer() PackagingConfigurer {
 		cloudArchivePackages: cloudArchivePackagesOpenSUSE,
 	}}
 }
+
+// NewApkPackagingConfigurer returns a PackagingConfigurer for apk-based systems.
+func NewApkPackagingConfigurer() PackagingConfigurer {
+	return &apkConfigurer{&baseConfigurer{
+		defaultPackages:      AlpineDefaultPackages,
+		cloudArchivePackages: cloudArchivePackagesAlpine,
+	}}
+}
+
+// NewPacmanPackagingConfigurer returns a PackagingConfigurer for pacman-based
+// systems.
+func NewPacmanPackagingConfigurer() PackagingConfigurer {
+	return &pacmanConfigurer{&baseConfigurer{
+		defaultPackages:      ArchDefaultPackages,
+		cloudArchivePackages: cloudArchivePackagesArch,
+	}}
+}
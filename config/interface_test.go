@@ -11,3 +11,5 @@ import (
 var _ config.PackagingConfigurer = config.NewAptPackagingConfigurer()
 var _ config.PackagingConfigurer = config.NewYumPackagingConfigurer()
 var _ config.PackagingConfigurer = config.NewZypperPackagingConfigurer()
+var _ config.PackagingConfigurer = config.NewApkPackagingConfigurer()
+var _ config.PackagingConfigurer = config.NewPacmanPackagingConfigurer()
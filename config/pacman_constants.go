@@ -0,0 +1,27 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"text/template"
+)
+
+const (
+	// PacmanConfigFile is the pacman configuration file, which holds the
+	// sections of all the repositories pacman installs packages from.
+	PacmanConfigFile = "/etc/pacman.conf"
+
+	// PacmanKeyfileDir is the default directory for pacman repository keys,
+	// which have to be imported into the pacman keyring with pacman-key.
+	PacmanKeyfileDir = "/etc/pacman.d/gnupg/"
+)
+
+// PacmanSourceTemplate is the template specific to the section of the
+// pacman configuration file for a source.
+var PacmanSourceTemplate = template.Must(template.New("").Parse(`
+# {{.Name}} (added by Juju)
+[{{.Name}}]
+Server = {{.URL}}
+{{if .Key}}SigLevel = Required DatabaseOptional
+{{end}}`[1:]))
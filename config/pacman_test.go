@@ -0,0 +1,77 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+)
+
+var _ = gc.Suite(&PacmanSuite{})
+
+type PacmanSuite struct {
+	pacconfer config.PackagingConfigurer
+}
+
+func (s *PacmanSuite) SetUpSuite(c *gc.C) {
+	s.pacconfer = config.NewPacmanPackagingConfigurer()
+}
+
+func (s *PacmanSuite) TestNewPackagingConfigurer(c *gc.C) {
+	pacconfer, err := config.NewPackagingConfigurer("arch")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pacconfer, gc.DeepEquals, s.pacconfer)
+}
+
+func (s *PacmanSuite) TestDefaultPackages(c *gc.C) {
+	c.Assert(s.pacconfer.DefaultPackages(), gc.DeepEquals, config.ArchDefaultPackages)
+	c.Assert(s.pacconfer.DefaultPackages(), jc.SameContents, []string{"curl", "ca-certificates"})
+}
+
+func (s *PacmanSuite) TestIsCloudArchivePackage(c *gc.C) {
+	for i, pack := range []string{"random", "mongodb", "cloud-utils"} {
+		c.Logf("Test %d: package %s:", i+1, pack)
+		res := s.pacconfer.IsCloudArchivePackage(pack)
+		_, there := config.CloudArchivePackagesArch[pack]
+
+		c.Assert(res, gc.Equals, there)
+	}
+}
+
+func (s *PacmanSuite) TestRenderSource(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(testedSource)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# Some Totally Official Source. (added by Juju)
+[Some Totally Official Source.]
+Server = some-source.com/packages
+SigLevel = Required DatabaseOptional
+`[1:])
+}
+
+func (s *PacmanSuite) TestRenderSourceNoKey(c *gc.C) {
+	res, err := s.pacconfer.RenderSource(packaging.PackageSource{
+		Name: "juju",
+		URL:  "https://example.com/$repo/os/$arch",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+# juju (added by Juju)
+[juju]
+Server = https://example.com/$repo/os/$arch
+`[1:])
+}
+
+func (s *PacmanSuite) TestRenderPreferences(c *gc.C) {
+	res, err := s.pacconfer.RenderPreferences(testedPrefs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, "")
+}
+
+func (s *PacmanSuite) TestApplyCloudArchiveTarget(c *gc.C) {
+	c.Assert(s.pacconfer.ApplyCloudArchiveTarget("bash"), gc.DeepEquals, []string{"bash"})
+}
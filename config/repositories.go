@@ -0,0 +1,108 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+// ReadAptSources reads the sources configured in AptSourcesFile and the
+// ".list" and ".sources" files of AptSourcesDirectory of the filesystem
+// tree at the given root, which is "/" for the running system. Missing
+// files and directories are skipped.
+func ReadAptSources(root string) ([]packaging.PackageSource, error) {
+	paths := []string{AptSourcesFile}
+	for _, ext := range []string{".list", ".sources"} {
+		dirPaths, err := listDir(root, AptSourcesDirectory, ext)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		paths = append(paths, dirPaths...)
+	}
+	return readSourceFiles(root, paths, func(path, contents string) ([]packaging.PackageSource, error) {
+		if strings.HasSuffix(path, ".sources") {
+			return parseAptDeb822Sources(contents)
+		}
+		return parseAptOneLineSources(contents)
+	})
+}
+
+// ReadYumRepositories reads the repositories configured in the ".repo"
+// files of YumReposDir and YumSourcesDir of the filesystem tree at the
+// given root, which is "/" for the running system. Missing directories are
+// skipped.
+func ReadYumRepositories(root string) ([]packaging.PackageSource, error) {
+	var paths []string
+	for _, dir := range []string{YumReposDir, YumSourcesDir} {
+		dirPaths, err := listDir(root, dir, ".repo")
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		paths = append(paths, dirPaths...)
+	}
+	return readSourceFiles(root, paths, func(_, contents string) ([]packaging.PackageSource, error) {
+		return ParseYumRepositories(contents)
+	})
+}
+
+// ReadZypperRepositories reads the repositories configured in the ".repo"
+// files of ZypperSourcesDir of the filesystem tree at the given root, which
+// is "/" for the running system. A missing directory is skipped.
+func ReadZypperRepositories(root string) ([]packaging.PackageSource, error) {
+	paths, err := listDir(root, ZypperSourcesDir, ".repo")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return readSourceFiles(root, paths, func(_, contents string) ([]packaging.PackageSource, error) {
+		return ParseZypperRepositories(contents)
+	})
+}
+
+// listDir returns the sorted paths of the files with the given extension in
+// the given directory below root.
+func listDir(root, dir, ext string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var res []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ext) {
+			res = append(res, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// readSourceFiles parses the given files below root with the given parser,
+// skipping the ones which do not exist.
+func readSourceFiles(
+	root string, paths []string, parse func(path, contents string) ([]packaging.PackageSource, error),
+) ([]packaging.PackageSource, error) {
+	var res []packaging.PackageSource
+	for _, path := range paths {
+		data, err := os.ReadFile(filepath.Join(root, path))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		srcs, err := parse(path, string(data))
+		if err != nil {
+			return nil, errors.Annotatef(err, "parsing %s", path)
+		}
+		res = append(res, srcs...)
+	}
+	return res, nil
+}
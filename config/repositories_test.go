@@ -0,0 +1,218 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+)
+
+var _ = gc.Suite(&RepositoriesSuite{})
+
+type RepositoriesSuite struct {
+	root string
+}
+
+func (s *RepositoriesSuite) SetUpTest(c *gc.C) {
+	s.root = c.MkDir()
+}
+
+func (s *RepositoriesSuite) writeFile(c *gc.C, path, contents string) {
+	path = filepath.Join(s.root, path)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0755), jc.ErrorIsNil)
+	c.Assert(os.WriteFile(path, []byte(contents), 0644), jc.ErrorIsNil)
+}
+
+const testedRepoFile = `
+# Managed by hand
+[baseos]
+name=Rocky Linux $releasever - BaseOS
+mirrorlist=https://mirrors.rockylinux.org/mirrorlist?arch=$basearch&repo=BaseOS-$releasever
+gpgcheck=1
+enabled=1
+gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-Rocky-9
+
+[epel]
+name = Extra Packages
+baseurl = https://one.example.com/epel/9/
+  https://two.example.com/epel/9/
+enabled = 0
+priority = 10
+gpgkey = https://example.com/RPM-GPG-KEY-EPEL-9
+`
+
+func (s *RepositoriesSuite) TestParseYumRepositories(c *gc.C) {
+	srcs, err := config.ParseYumRepositories(testedRepoFile)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{{
+		Name:     "baseos",
+		URL:      "https://mirrors.rockylinux.org/mirrorlist?arch=$basearch&repo=BaseOS-$releasever",
+		SignedBy: "/etc/pki/rpm-gpg/RPM-GPG-KEY-Rocky-9",
+	}, {
+		Name:     "epel",
+		URL:      "https://one.example.com/epel/9/",
+		SignedBy: "https://example.com/RPM-GPG-KEY-EPEL-9",
+		Disabled: true,
+		Priority: 10,
+	}})
+}
+
+func (s *RepositoriesSuite) TestParseYumRepositoriesRoundTrip(c *gc.C) {
+	src := packaging.PackageSource{
+		Name:     "juju",
+		URL:      "https://example.com/juju/el9",
+		SignedBy: "/etc/pki/rpm-gpg/RPM-GPG-KEY-juju",
+		Disabled: true,
+	}
+	for _, pacconfer := range []config.PackagingConfigurer{
+		config.NewYumPackagingConfigurer(),
+		config.NewZypperPackagingConfigurer(),
+	} {
+		contents, err := pacconfer.RenderSource(src)
+		c.Assert(err, jc.ErrorIsNil)
+		srcs, err := config.ParseZypperRepositories(contents)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{src})
+	}
+}
+
+func (s *RepositoriesSuite) TestParseYumRepositoriesInvalid(c *gc.C) {
+	for i, test := range []struct {
+		contents string
+		err      string
+	}{{
+		contents: "baseurl=http://example.com\n",
+		err:      `line 1 outside of section not valid`,
+	}, {
+		contents: "[broken\n",
+		err:      `line 1 section "\[broken" not valid`,
+	}, {
+		contents: "[foo]\nname=foo\n",
+		err:      `line 1: repository "foo" without baseurl not valid`,
+	}, {
+		contents: "[foo]\nbaseurl=http://example.com\nenabled=maybe\n",
+		err:      `line 1: repository "foo": boolean "maybe" not valid`,
+	}, {
+		contents: "[foo]\nbaseurl=http://example.com\npriority=high\n",
+		err:      `line 1: repository "foo" priority "high" not valid`,
+	}} {
+		c.Logf("test %d: %q", i, test.contents)
+		_, err := config.ParseYumRepositories(test.contents)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *RepositoriesSuite) TestDeb822Disabled(c *gc.C) {
+	src := packaging.PackageSource{
+		Name:     "disabled",
+		URL:      "http://example.com/ubuntu",
+		Types:    []string{"deb"},
+		Suites:   []string{"noble"},
+		Disabled: true,
+	}
+	contents, err := config.NewAptPackagingConfigurer().(config.Deb822SourceRenderer).RenderDeb822Source(src)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(contents, gc.Equals, `
+# disabled (added by Juju)
+Types: deb
+URIs: http://example.com/ubuntu
+Suites: noble
+Enabled: no
+`[1:])
+
+	srcs, err := config.ParseAptSources(contents)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{src})
+
+	// The entries of disabled sources are commented out in the one-line
+	// format.
+	contents, err = config.NewAptPackagingConfigurer().RenderSource(src)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(contents, gc.Equals, `
+# disabled (added by Juju)
+# deb http://example.com/ubuntu noble
+`[1:])
+}
+
+func (s *RepositoriesSuite) TestReadAptSources(c *gc.C) {
+	s.writeFile(c, "etc/apt/sources.list", "deb http://archive.ubuntu.com/ubuntu jammy main\n")
+	s.writeFile(c, "etc/apt/sources.list.d/b.list", "deb http://b.example.com/ubuntu jammy main\n")
+	s.writeFile(c, "etc/apt/sources.list.d/a.sources", `
+Types: deb
+URIs: http://a.example.com/ubuntu
+Suites: jammy
+Components: main
+`)
+	s.writeFile(c, "etc/apt/sources.list.d/ignored.save", "deb http://ignored.example.com/ubuntu jammy main\n")
+
+	srcs, err := config.ReadAptSources(s.root)
+	c.Assert(err, jc.ErrorIsNil)
+	var urls []string
+	for _, src := range srcs {
+		urls = append(urls, src.URL)
+	}
+	c.Assert(urls, gc.DeepEquals, []string{
+		"http://archive.ubuntu.com/ubuntu",
+		"http://b.example.com/ubuntu",
+		"http://a.example.com/ubuntu",
+	})
+}
+
+func (s *RepositoriesSuite) TestReadAptSourcesInvalid(c *gc.C) {
+	s.writeFile(c, "etc/apt/sources.list.d/broken.sources", "Types: deb\n")
+
+	_, err := config.ReadAptSources(s.root)
+	c.Assert(err, gc.ErrorMatches, `parsing /etc/apt/sources.list.d/broken.sources: .* not valid`)
+}
+
+func (s *RepositoriesSuite) TestReadYumRepositories(c *gc.C) {
+	s.writeFile(c, "etc/yum.repos.d/rocky.repo", testedRepoFile)
+	s.writeFile(c, "etc/yum/repos.d/juju.repo", "[juju]\nbaseurl=https://example.com/juju\n")
+
+	srcs, err := config.ReadYumRepositories(s.root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.HasLen, 3)
+	c.Assert(srcs[2].Name, gc.Equals, "juju")
+}
+
+func (s *RepositoriesSuite) TestReadZypperRepositories(c *gc.C) {
+	s.writeFile(c, "etc/zypp/repos.d/repo-oss.repo", `
+[repo-oss]
+name=Main Repository
+enabled=1
+autorefresh=1
+baseurl=http://download.opensuse.org/distribution/leap/15.5/repo/oss/
+type=rpm-md
+keeppackages=0
+priority=99
+`)
+
+	srcs, err := config.ReadZypperRepositories(s.root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(srcs, gc.DeepEquals, []packaging.PackageSource{{
+		Name:     "repo-oss",
+		URL:      "http://download.opensuse.org/distribution/leap/15.5/repo/oss/",
+		Priority: 99,
+	}})
+}
+
+func (s *RepositoriesSuite) TestReadRepositoriesEmptyRoot(c *gc.C) {
+	for _, read := range []func(string) ([]packaging.PackageSource, error){
+		config.ReadAptSources,
+		config.ReadYumRepositories,
+		config.ReadZypperRepositories,
+	} {
+		srcs, err := read(s.root)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(srcs, gc.HasLen, 0)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+const (
+	// aptDefaultPriority is the priority apt assigns to the versions of
+	// packages from sources which are not pinned.
+	aptDefaultPriority = 500
+
+	// rpmDefaultPriority is the priority yum and zypper assign to
+	// repositories without one.
+	rpmDefaultPriority = 99
+)
+
+// RPMPriority returns the yum and zypper repository priority equivalent to
+// the given apt_preferences(5) priority. Where apt prefers higher
+// priorities, yum and zypper prefer lower ones, within 1 to 99. The default
+// priorities of both map onto each other, and every 10 points apt
+// priorities exceed the default by lower the repository priority by one.
+// Priorities below the apt default cannot be expressed and map onto the
+// default.
+func RPMPriority(aptPriority int) int {
+	if aptPriority <= aptDefaultPriority {
+		return rpmDefaultPriority
+	}
+	priority := rpmDefaultPriority - (aptPriority-aptDefaultPriority)/10
+	if priority < 1 {
+		return 1
+	}
+	return priority
+}
+
+// rpmPinRepository returns the identifier of the repository which the given
+// pin of a PackagePreferences refers to on rpm-based systems. There, the
+// pin has to be "origin <repository>", with the identifier of the
+// repository in place of the host name apt expects.
+func rpmPinRepository(pin string) (string, error) {
+	parsed, err := ParseAptPin(pin)
+	if err != nil || parsed.Type != packaging.PinOrigin || parsed.Origin == "" {
+		return "", errors.NotValidf("pin %q, expected \"origin <repository>\"", pin)
+	}
+	return parsed.Origin, nil
+}
+
+// isAllPackages returns whether the Package field of a PackagePreferences
+// matches all packages.
+func isAllPackages(pack string) bool {
+	pack = strings.TrimSpace(pack)
+	return pack == "" || pack == "*"
+}
+
+// renderRPMPreferences returns the options of the repository section the
+// given preferences translate to. Preferences for all packages set the
+// priority of the repository, or disable it if it is negative, whereas
+// negative preferences for some packages exclude those. Positive
+// preferences for some packages are only supported by ApplyPreferences, in
+// combination with a negative one for all packages, as yum cannot prefer
+// repositories per package. packageLists is false for zypper, which cannot
+// exclude packages either.
+func renderRPMPreferences(prefs packaging.PackagePreferences, packageLists bool) (string, error) {
+	if _, err := rpmPinRepository(prefs.Pin); err != nil {
+		return "", errors.Trace(err)
+	}
+
+	var option string
+	switch {
+	case isAllPackages(prefs.Package) && prefs.Priority < 0:
+		option = "enabled=0"
+	case isAllPackages(prefs.Package):
+		option = "priority=" + strconv.Itoa(RPMPriority(prefs.Priority))
+	case !packageLists:
+		return "", errors.NotSupportedf("package preferences for %q", prefs.Package)
+	case prefs.Priority < 0:
+		option = "exclude=" + strings.Join(strings.Fields(prefs.Package), " ")
+	default:
+		return "", errors.NotSupportedf("positive package preferences for %q without negative preferences for all packages", prefs.Package)
+	}
+
+	var sb strings.Builder
+	if prefs.Explanation != "" {
+		sb.WriteString("# " + prefs.Explanation + "\n")
+	}
+	sb.WriteString(option + "\n")
+	return sb.String(), nil
+}
+
+// applyRPMPreferences returns the given source with those of the given
+// preferences applied which pin it, as described on PreferencesApplier.
+// packageLists is false for zypper, which can neither restrict sources to
+// nor exclude packages from them.
+func applyRPMPreferences(
+	src packaging.PackageSource, prefs []packaging.PackagePreferences, packageLists bool,
+) (packaging.PackageSource, error) {
+	var (
+		excludeAll bool
+		include    []string
+	)
+	for _, pref := range prefs {
+		repo, err := rpmPinRepository(pref.Pin)
+		if err != nil {
+			return src, errors.Trace(err)
+		}
+		if repo != src.Name {
+			continue
+		}
+
+		packs := strings.Fields(pref.Package)
+		switch {
+		case isAllPackages(pref.Package) && pref.Priority < 0:
+			excludeAll = true
+		case isAllPackages(pref.Package):
+			src.Priority = RPMPriority(pref.Priority)
+		case !packageLists:
+			return src, errors.NotSupportedf("package preferences for %q", pref.Package)
+		case pref.Priority < 0:
+			src.ExcludePackages = appendMissing(src.ExcludePackages, packs...)
+		default:
+			include = appendMissing(include, packs...)
+		}
+	}
+
+	switch {
+	case excludeAll && len(include) > 0:
+		src.IncludePackages = appendMissing(src.IncludePackages, include...)
+	case excludeAll:
+		src.Disabled = true
+	case len(include) > 0:
+		return src, errors.NotSupportedf("positive package preferences for %q without negative preferences for all packages", strings.Join(include, " "))
+	}
+	return src, nil
+}
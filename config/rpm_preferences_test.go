@@ -0,0 +1,147 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+)
+
+var _ = gc.Suite(&RPMPreferencesSuite{})
+
+type RPMPreferencesSuite struct {
+	yum    config.PackagingConfigurer
+	zypper config.PackagingConfigurer
+}
+
+func (s *RPMPreferencesSuite) SetUpSuite(c *gc.C) {
+	s.yum = config.NewYumPackagingConfigurer()
+	s.zypper = config.NewZypperPackagingConfigurer()
+}
+
+func (s *RPMPreferencesSuite) TestRPMPriority(c *gc.C) {
+	for _, test := range []struct{ apt, rpm int }{
+		{-1, 99}, {100, 99}, {500, 99}, {509, 99}, {510, 98}, {600, 89}, {990, 50}, {1001, 49}, {2000, 1},
+	} {
+		c.Check(config.RPMPriority(test.apt), gc.Equals, test.rpm, gc.Commentf("apt priority %d", test.apt))
+	}
+}
+
+func (s *RPMPreferencesSuite) TestRenderPreferences(c *gc.C) {
+	for i, test := range []struct {
+		prefs packaging.PackagePreferences
+		yum   string
+	}{{
+		prefs: packaging.PackagePreferences{Explanation: "prefer juju", Package: "*", Pin: "origin juju", Priority: 990},
+		yum:   "# prefer juju\npriority=50\n",
+	}, {
+		prefs: packaging.PackagePreferences{Pin: "origin juju", Priority: -1},
+		yum:   "enabled=0\n",
+	}, {
+		prefs: packaging.PackagePreferences{Package: "mongodb* lxd", Pin: "origin juju", Priority: -10},
+		yum:   "exclude=mongodb* lxd\n",
+	}} {
+		c.Logf("test %d", i)
+		res, err := s.yum.RenderPreferences(test.prefs)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, test.yum)
+	}
+}
+
+func (s *RPMPreferencesSuite) TestRenderPreferencesZypper(c *gc.C) {
+	res, err := s.zypper.RenderPreferences(packaging.PackagePreferences{Package: "*", Pin: "origin juju", Priority: 600})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, "priority=89\n")
+
+	_, err = s.zypper.RenderPreferences(packaging.PackagePreferences{Package: "lxd", Pin: "origin juju", Priority: -1})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *RPMPreferencesSuite) TestRenderPreferencesInvalid(c *gc.C) {
+	_, err := s.yum.RenderPreferences(testedPrefs)
+	c.Assert(err, gc.ErrorMatches, `pin "releases/extra-special", expected "origin <repository>" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	_, err = s.yum.RenderPreferences(packaging.PackagePreferences{Package: "lxd", Pin: "origin juju", Priority: 900})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *RPMPreferencesSuite) TestApplyPreferences(c *gc.C) {
+	src := packaging.PackageSource{Name: "juju", URL: "https://example.com/juju"}
+	prefs := []packaging.PackagePreferences{
+		{Package: "*", Pin: "origin juju", Priority: -1},
+		{Package: "juju-db lxd", Pin: "origin juju", Priority: 500},
+		{Package: "*", Pin: "origin other", Priority: -1},
+	}
+
+	res, err := s.yum.(config.PreferencesApplier).ApplyPreferences(src, prefs...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.IncludePackages, gc.DeepEquals, []string{"juju-db", "lxd"})
+	c.Assert(res.Disabled, jc.IsFalse)
+
+	rendered, err := s.yum.RenderSource(res)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rendered, gc.Equals, `
+[juju]
+name=juju (added by Juju)
+baseurl=https://example.com/juju
+
+includepkgs=juju-db lxd
+enabled=1
+`[1:])
+
+	parsed, err := config.ParseYumRepositories(rendered)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, gc.DeepEquals, []packaging.PackageSource{res})
+}
+
+func (s *RPMPreferencesSuite) TestApplyPreferencesPriorityAndExclusions(c *gc.C) {
+	src := packaging.PackageSource{Name: "juju", URL: "https://example.com/juju"}
+	prefs := []packaging.PackagePreferences{
+		{Package: "*", Pin: "origin juju", Priority: 700},
+		{Package: "mongodb*", Pin: "origin juju", Priority: -1},
+	}
+
+	res, err := s.yum.(config.PreferencesApplier).ApplyPreferences(src, prefs...)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Priority, gc.Equals, 79)
+	c.Assert(res.ExcludePackages, gc.DeepEquals, []string{"mongodb*"})
+
+	rendered, err := s.yum.RenderSource(res)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rendered, gc.Equals, `
+[juju]
+name=juju (added by Juju)
+baseurl=https://example.com/juju
+
+priority=79
+exclude=mongodb*
+enabled=1
+`[1:])
+
+	// All packages pinned negatively disable the source.
+	res, err = s.zypper.(config.PreferencesApplier).ApplyPreferences(src, packaging.PackagePreferences{
+		Pin: "origin juju", Priority: -1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res.Disabled, jc.IsTrue)
+}
+
+func (s *RPMPreferencesSuite) TestApplyPreferencesNotSupported(c *gc.C) {
+	src := packaging.PackageSource{Name: "juju", URL: "https://example.com/juju"}
+
+	_, err := s.yum.(config.PreferencesApplier).ApplyPreferences(src, packaging.PackagePreferences{
+		Package: "lxd", Pin: "origin juju", Priority: 900,
+	})
+	c.Assert(err, gc.ErrorMatches, `positive package preferences for "lxd" without negative preferences for all packages not supported`)
+
+	_, err = s.zypper.(config.PreferencesApplier).ApplyPreferences(src, packaging.PackagePreferences{
+		Package: "lxd", Pin: "origin juju", Priority: -1,
+	})
+	c.Assert(err, gc.ErrorMatches, `package preferences for "lxd" not supported`)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package config
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+)
+
+// rpmTemplateFuncs are the functions available to the yum and zypper source
+// templates.
+var rpmTemplateFuncs = template.FuncMap{
+	"gpgKeyURL": gpgKeyURL,
+	"join":      strings.Join,
+	"zypperURL": zypperRepositoryURL,
+}
+
+// gpgKeyURL returns the gpgkey URL of a repository file for the given
+// SignedBy value, which is either a URL or the path of a key.
+func gpgKeyURL(signedBy string) string {
+	if strings.Contains(signedBy, "://") {
+		return signedBy
+	}
+	return "file://" + signedBy
+}
+
+// ParseYumRepositories parses the contents of a yum or dnf ".repo" file,
+// which holds an INI section per repository, into sources named after the
+// identifiers of their sections. The first baseurl, mirrorlist or metalink
+// of a repository is taken as its URL, a gpgkey referencing a local file
+// is taken as its path and the username and password of a repository as
+// its credentials. An error satisfying errors.IsNotValid is returned
+// for malformed files.
+func ParseYumRepositories(contents string) ([]packaging.PackageSource, error) {
+	sections, err := parseRepoINI(contents)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	res := make([]packaging.PackageSource, 0, len(sections))
+	for _, section := range sections {
+		src, err := repoSectionSource(section)
+		if err != nil {
+			return nil, errors.Annotatef(err, "line %d", section.line)
+		}
+		res = append(res, src)
+	}
+	return res, nil
+}
+
+// ParseZypperRepositories parses the contents of a zypper ".repo" file,
+// which uses the same format as the repository files of yum. The
+// parameter referencing the credentials file of a repository is removed
+// from its URL; the credentials themselves are not read.
+func ParseZypperRepositories(contents string) ([]packaging.PackageSource, error) {
+	res, err := ParseYumRepositories(contents)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i := range res {
+		res[i].URL = stripZypperCredentials(res[i].URL)
+	}
+	return res, nil
+}
+
+// repoSection is a section of a ".repo" INI file.
+type repoSection struct {
+	id     string
+	line   int
+	fields map[string]string
+}
+
+// parseRepoINI splits the contents of a ".repo" file into its sections.
+// Lines starting with whitespace continue the value of the previous field.
+func parseRepoINI(contents string) ([]repoSection, error) {
+	var (
+		res       []repoSection
+		lastField string
+	)
+	scanner := bufio.NewScanner(strings.NewReader(contents))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+			continue
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return nil, errors.NotValidf("line %d section %q", lineNo, line)
+			}
+			res = append(res, repoSection{
+				id:     strings.TrimSpace(line[1 : len(line)-1]),
+				line:   lineNo,
+				fields: make(map[string]string),
+			})
+			lastField = ""
+		case len(res) == 0:
+			return nil, errors.NotValidf("line %d outside of section", lineNo)
+		case raw[0] == ' ' || raw[0] == '\t':
+			if lastField == "" {
+				return nil, errors.NotValidf("line %d continuation without field", lineNo)
+			}
+			section := res[len(res)-1]
+			section.fields[lastField] += " " + line
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, errors.NotValidf("line %d %q", lineNo, line)
+			}
+			lastField = strings.ToLower(strings.TrimSpace(key))
+			res[len(res)-1].fields[lastField] = strings.TrimSpace(value)
+		}
+	}
+	return res, errors.Trace(scanner.Err())
+}
+
+// repoSectionSource returns the source described by the given section of
+// a ".repo" file.
+func repoSectionSource(section repoSection) (packaging.PackageSource, error) {
+	src := packaging.PackageSource{Name: section.id}
+
+	for _, key := range []string{"baseurl", "mirrorlist", "metalink"} {
+		if urls := strings.FieldsFunc(section.fields[key], isURLSeparator); len(urls) > 0 {
+			src.URL = urls[0]
+			break
+		}
+	}
+	if src.URL == "" {
+		return src, errors.NotValidf("repository %q without baseurl", section.id)
+	}
+
+	src.Username, src.Password = section.fields["username"], section.fields["password"]
+
+	if v, ok := section.fields["enabled"]; ok {
+		enabled, err := parseRepoBool(v)
+		if err != nil {
+			return src, errors.Annotatef(err, "repository %q", section.id)
+		}
+		src.Disabled = !enabled
+	}
+	if v := section.fields["priority"]; v != "" {
+		priority, err := strconv.Atoi(v)
+		if err != nil {
+			return src, errors.NotValidf("repository %q priority %q", section.id, v)
+		}
+		src.Priority = priority
+	}
+	src.IncludePackages = strings.FieldsFunc(section.fields["includepkgs"], isURLSeparator)
+	for _, key := range []string{"exclude", "excludepkgs"} {
+		src.ExcludePackages = append(src.ExcludePackages, strings.FieldsFunc(section.fields[key], isURLSeparator)...)
+	}
+	if len(src.IncludePackages) == 0 {
+		src.IncludePackages = nil
+	}
+	if len(src.ExcludePackages) == 0 {
+		src.ExcludePackages = nil
+	}
+	if key := section.fields["gpgkey"]; key != "" {
+		if path := strings.TrimPrefix(key, "file://"); path != key && !strings.ContainsAny(path, " \t,") {
+			key = path
+		}
+		src.SignedBy = key
+	}
+	return src, nil
+}
+
+// isURLSeparator returns whether the given rune separates the URLs or
+// package globs of a field of a ".repo" file.
+func isURLSeparator(r rune) bool {
+	return r == ' ' || r == '\t' || r == ','
+}
+
+// parseRepoBool parses a boolean value of a ".repo" file.
+func parseRepoBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "1", "yes", "true", "on":
+		return true, nil
+	case "0", "no", "false", "off":
+		return false, nil
+	}
+	return false, errors.NotValidf("boolean %q", v)
+}
@@ -12,16 +12,26 @@ const (
 	// YumSourcesDir is the default directory in which yum sourcefiles are located.
 	YumSourcesDir = "/etc/yum/repos.d"
 
+	// YumReposDir is the directory in which dnf and recent versions of yum
+	// look for repository files, in addition to YumSourcesDir.
+	YumReposDir = "/etc/yum.repos.d"
+
 	// YumKeyfileDir is the default directory for yum repository keys.
 	YumKeyfileDir = "/etc/pki/rpm-gpg/"
 )
 
 // YumSourceTemplate is the template specific to a yum source file.
-var YumSourceTemplate = template.Must(template.New("").Parse(`
+var YumSourceTemplate = template.Must(template.New("").Funcs(rpmTemplateFuncs).Parse(`
 [{{.Name}}]
 name={{.Name}} (added by Juju)
 baseurl={{.URL}}
-{{if .Key}}gpgcheck=1
+{{with .Username}}username={{.}}
+{{end}}{{with .Password}}password={{.}}
+{{end}}{{if .SignedBy}}gpgcheck=1
+gpgkey={{gpgKeyURL .SignedBy}}{{else if .Key}}gpgcheck=1
 gpgkey=%s{{end}}
-enabled=1
+{{if .Priority}}priority={{.Priority}}
+{{end}}{{with .IncludePackages}}includepkgs={{join . " "}}
+{{end}}{{with .ExcludePackages}}exclude={{join . " "}}
+{{end}}enabled={{if .Disabled}}0{{else}}1{{end}}
 `[1:]))
@@ -53,3 +53,19 @@ func (s *YumSuite) TestRenderSource(c *gc.C) {
 
 	c.Assert(res, gc.Equals, expected)
 }
+
+func (s *YumSuite) TestRenderSourceSignedBy(c *gc.C) {
+	src := testedSource
+	src.SignedBy = "/etc/pki/rpm-gpg/RPM-GPG-KEY-juju"
+
+	res, err := s.pacconfer.RenderSource(src)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(res, gc.Equals, `
+[Some Totally Official Source.]
+name=Some Totally Official Source. (added by Juju)
+baseurl=some-source.com/packages
+gpgcheck=1
+gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-juju
+enabled=1
+`[1:])
+}
@@ -14,12 +14,14 @@ const (
 )
 
 // ZypperSourceTemplate is the template specific to a yum source file.
-var ZypperSourceTemplate = template.Must(template.New("").Parse(`
+var ZypperSourceTemplate = template.Must(template.New("").Funcs(rpmTemplateFuncs).Parse(`
 [{{.Name}}]
 name={{.Name}} (added by Juju)
-baseurl={{.URL}}
-{{if .Key}}gpgcheck=1
+baseurl={{zypperURL .}}
+{{if .SignedBy}}gpgcheck=1
+gpgkey={{gpgKeyURL .SignedBy}}{{else if .Key}}gpgcheck=1
 gpgkey=%s{{end}}
-autorefresh=0
-enabled=1
+{{if .Priority}}priority={{.Priority}}
+{{end}}autorefresh=0
+enabled={{if .Disabled}}0{{else}}1{{end}}
 `[1:]))
@@ -0,0 +1,182 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package distro detects the operating system of a filesystem tree from
+// its os-release file, and selects the packaging implementations to use
+// for it from a registry of backends. Besides the built-in backends,
+// other packages may register their own with Register.
+package distro
+
+import (
+	"strconv"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/manager"
+)
+
+// Backend groups the packaging implementations for a package management
+// system.
+type Backend struct {
+	// Name is the name of the package management system, such as "apt"
+	// or "dnf".
+	Name string
+
+	// Commander builds the commands of the package management system.
+	Commander commands.PackageCommander
+
+	// Manager runs the commands of the package management system.
+	Manager manager.PackageManager
+
+	// Configurer renders the configuration of the package management
+	// system.
+	Configurer config.PackagingConfigurer
+}
+
+// Detect reads the os-release file of the filesystem tree at the given
+// root, which is "/" for the running system, and returns the Backend for
+// the operating system it identifies.
+func Detect(root string) (Backend, error) {
+	rel, err := ReadOSRelease(root)
+	if err != nil {
+		return Backend{}, errors.Trace(err)
+	}
+	return ForOSRelease(rel)
+}
+
+// ForOSRelease returns the registered Backend for the operating system
+// identified by the given os-release fields. The operating systems listed
+// in ID_LIKE are tried in turn if no backend matches ID. An error
+// satisfying errors.IsNotSupported is returned if none of them is
+// supported.
+func ForOSRelease(rel OSRelease) (Backend, error) {
+	reg, err := matchOSRelease(rel)
+	if err != nil {
+		return Backend{}, errors.Trace(err)
+	}
+	return reg.newBackend(), nil
+}
+
+// DetectTarget reads the os-release file below the Root of the given
+// target and returns the Backend for the operating system it identifies,
+// whose Manager runs its commands inside the target with the given
+// Executor. An error satisfying errors.IsNotValid is returned if the
+// target is not valid or has no Root, and one satisfying
+// errors.IsNotSupported if the backend cannot run its commands with an
+// Executor.
+func DetectTarget(target manager.Target, executor manager.Executor) (Backend, error) {
+	targetExecutor, err := manager.NewTargetExecutor(target, executor)
+	if err != nil {
+		return Backend{}, errors.Trace(err)
+	}
+	if target.Root == "" {
+		return Backend{}, errors.NotValidf("detecting the operating system of a %s target without root", target.Kind)
+	}
+	rel, err := ReadOSRelease(target.Root)
+	if err != nil {
+		return Backend{}, errors.Trace(err)
+	}
+	reg, err := matchOSRelease(rel)
+	if err != nil {
+		return Backend{}, errors.Trace(err)
+	}
+	return reg.newTargetBackend(targetExecutor)
+}
+
+// matchOSRelease returns the registered backend for the operating system
+// identified by the given os-release fields, as described by ForOSRelease.
+func matchOSRelease(rel OSRelease) (Registration, error) {
+	for _, id := range append([]string{rel.ID}, rel.IDLike...) {
+		if reg, ok := match(id, rel); ok {
+			return reg, nil
+		}
+	}
+	return Registration{}, errors.NotSupportedf("operating system %q", rel.ID)
+}
+
+// enterpriseLinuxIDs are the identifiers of the operating systems which are
+// versioned like Red Hat Enterprise Linux.
+var enterpriseLinuxIDs = MatchIDs("centos", "rhel", "rocky", "almalinux", "ol")
+
+// matchYum matches the operating systems which still use yum.
+func matchYum(id string, rel OSRelease) bool {
+	switch {
+	case enterpriseLinuxIDs(id, rel):
+		// Enterprise Linux switched from yum to dnf with version 8.
+		major, err := strconv.Atoi(rel.MajorVersion())
+		return err == nil && major < 8
+	case id == "amzn":
+		// Amazon Linux 2 is based on Enterprise Linux 7, whereas later
+		// versions are versioned by year and based on Fedora.
+		return rel.MajorVersion() == "2"
+	}
+	return false
+}
+
+// matchDnf matches the operating systems which use dnf.
+func matchDnf(id string, rel OSRelease) bool {
+	switch {
+	case id == "fedora":
+		return true
+	case enterpriseLinuxIDs(id, rel), id == "amzn":
+		return !matchYum(id, rel)
+	}
+	return false
+}
+
+func init() {
+	for _, reg := range []Registration{{
+		Name:                   "apt",
+		Match:                  MatchIDs("ubuntu", "debian"),
+		NewCommander:           commands.NewAptPackageCommander,
+		NewManager:             manager.NewAptPackageManager,
+		NewManagerWithExecutor: manager.NewAptPackageManagerWithExecutor,
+		NewConfigurer:          config.NewAptPackagingConfigurer,
+	}, {
+		Name:                   "yum",
+		Match:                  matchYum,
+		NewCommander:           commands.NewYumPackageCommander,
+		NewManager:             manager.NewYumPackageManager,
+		NewManagerWithExecutor: manager.NewYumPackageManagerWithExecutor,
+		NewConfigurer:          config.NewYumPackagingConfigurer,
+	}, {
+		Name:                   "dnf",
+		Match:                  matchDnf,
+		NewCommander:           commands.NewDnfPackageCommander,
+		NewManager:             manager.NewDnfPackageManager,
+		NewManagerWithExecutor: manager.NewDnfPackageManagerWithExecutor,
+		// dnf reads the same repository files as yum.
+		NewConfigurer: config.NewYumPackagingConfigurer,
+	}, {
+		Name:                   "zypper",
+		Match:                  MatchIDs("opensuse", "opensuse-leap", "opensuse-tumbleweed", "sles", "suse"),
+		NewCommander:           commands.NewZypperPackageCommander,
+		NewManager:             manager.NewZypperPackageManager,
+		NewManagerWithExecutor: manager.NewZypperPackageManagerWithExecutor,
+		NewConfigurer:          config.NewZypperPackagingConfigurer,
+	}, {
+		Name:         "apk",
+		Match:        MatchIDs("alpine"),
+		NewCommander: commands.NewApkPackageCommander,
+		NewManager:   func() manager.PackageManager { return manager.NewApkPackageManager() },
+		NewManagerWithExecutor: func(executor manager.Executor) manager.PackageManager {
+			return manager.NewApkPackageManagerWithExecutor(executor)
+		},
+		NewConfigurer: config.NewApkPackagingConfigurer,
+	}, {
+		Name:         "pacman",
+		Match:        MatchIDs("arch"),
+		NewCommander: commands.NewPacmanPackageCommander,
+		NewManager:   func() manager.PackageManager { return manager.NewPacmanPackageManager() },
+		NewManagerWithExecutor: func(executor manager.Executor) manager.PackageManager {
+			return manager.NewPacmanPackageManagerWithExecutor(executor)
+		},
+		NewConfigurer: config.NewPacmanPackagingConfigurer,
+	}} {
+		if err := Register(reg); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package distro_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/distro"
+	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
+)
+
+var _ = gc.Suite(&DetectSuite{})
+
+type DetectSuite struct{}
+
+func (s *DetectSuite) TestDetect(c *gc.C) {
+	root := writeOSRelease(c, "etc/os-release", ubuntuOSRelease)
+
+	backend, err := distro.Detect(root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "apt")
+	c.Assert(backend.Commander, gc.Equals, commands.NewAptPackageCommander())
+	c.Assert(backend.Manager, gc.FitsTypeOf, manager.NewAptPackageManager())
+	c.Assert(backend.Configurer, gc.DeepEquals, config.NewAptPackagingConfigurer())
+}
+
+func (s *DetectSuite) TestDetectNotFound(c *gc.C) {
+	_, err := distro.Detect(c.MkDir())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *DetectSuite) TestForOSRelease(c *gc.C) {
+	for i, test := range []struct {
+		id        string
+		idLike    []string
+		versionID string
+		backend   string
+	}{
+		{id: "ubuntu", versionID: "22.04", backend: "apt"},
+		{id: "debian", versionID: "12", backend: "apt"},
+		{id: "linuxmint", idLike: []string{"ubuntu", "debian"}, versionID: "21.2", backend: "apt"},
+		{id: "centos", versionID: "7", backend: "yum"},
+		{id: "centos", versionID: "8", backend: "dnf"},
+		{id: "rocky", versionID: "9.2", backend: "dnf"},
+		{id: "almalinux", versionID: "8.8", backend: "dnf"},
+		{id: "rhel", versionID: "7.9", backend: "yum"},
+		{id: "fedora", versionID: "39", backend: "dnf"},
+		{id: "amzn", idLike: []string{"centos", "rhel", "fedora"}, versionID: "2", backend: "yum"},
+		{id: "amzn", idLike: []string{"fedora"}, versionID: "2023", backend: "dnf"},
+		{id: "eurolinux", idLike: []string{"rhel", "fedora", "centos"}, versionID: "7.9", backend: "yum"},
+		{id: "opensuse-leap", idLike: []string{"suse", "opensuse"}, versionID: "15.5", backend: "zypper"},
+		{id: "opensuse-tumbleweed", backend: "zypper"},
+		{id: "sles", versionID: "15.5", backend: "zypper"},
+		{id: "alpine", versionID: "3.18.4", backend: "apk"},
+		{id: "arch", backend: "pacman"},
+		{id: "manjaro", idLike: []string{"arch"}, backend: "pacman"},
+	} {
+		c.Logf("test %d: %s %v %s", i, test.id, test.idLike, test.versionID)
+		backend, err := distro.ForOSRelease(distro.OSRelease{
+			ID:        test.id,
+			IDLike:    test.idLike,
+			VersionID: test.versionID,
+		})
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(backend.Name, gc.Equals, test.backend)
+		c.Check(backend.Commander, gc.NotNil)
+		c.Check(backend.Manager, gc.NotNil)
+		c.Check(backend.Configurer, gc.NotNil)
+	}
+}
+
+func (s *DetectSuite) TestForOSReleaseNotSupported(c *gc.C) {
+	_, err := distro.ForOSRelease(distro.OSRelease{
+		ID:     "gentoo",
+		IDLike: []string{"unknown"},
+	})
+	c.Assert(err, gc.ErrorMatches, `operating system "gentoo" not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *DetectSuite) TestDetectTarget(c *gc.C) {
+	root := writeOSRelease(c, "etc/os-release", ubuntuOSRelease)
+	exec := managertesting.NewFakeExecutor()
+
+	backend, err := distro.DetectTarget(manager.Target{Kind: manager.Chroot, Root: root}, exec)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "apt")
+	c.Assert(backend.Configurer, gc.DeepEquals, config.NewAptPackagingConfigurer())
+
+	c.Assert(backend.Manager.Install("bash"), jc.ErrorIsNil)
+	c.Assert(exec.Commands(), gc.HasLen, 1)
+	c.Assert(exec.Commands()[0].Args[:3], gc.DeepEquals, []string{"chroot", root, "apt-get"})
+}
+
+func (s *DetectSuite) TestDetectTargetWithoutRoot(c *gc.C) {
+	target := manager.Target{Kind: manager.Docker, Container: "build"}
+	_, err := distro.DetectTarget(target, managertesting.NewFakeExecutor())
+	c.Assert(err, gc.ErrorMatches, "detecting the operating system of a docker target without root not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *DetectSuite) TestDetectTargetWithoutExecutorFactory(c *gc.C) {
+	c.Assert(distro.Register(portageRegistration), jc.ErrorIsNil)
+	defer distro.Unregister("portage")
+	root := writeOSRelease(c, "etc/os-release", "ID=gentoo\n")
+
+	_, err := distro.DetectTarget(manager.Target{Kind: manager.Nspawn, Root: root}, managertesting.NewFakeExecutor())
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package distro
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+var (
+	// OSReleasePath is the path, relative to the filesystem root, of the
+	// file identifying the operating system.
+	OSReleasePath = "etc/os-release"
+
+	// OSReleaseFallbackPath is the path, relative to the filesystem root,
+	// which is read if OSReleasePath does not exist.
+	OSReleaseFallbackPath = "usr/lib/os-release"
+)
+
+// OSRelease holds the fields of an os-release file which identify the
+// operating system.
+type OSRelease struct {
+	// ID is the lower-case identifier of the operating system, such as
+	// "ubuntu" or "centos".
+	ID string
+
+	// IDLike are the identifiers of the operating systems this one is
+	// derived from, closest first.
+	IDLike []string
+
+	// VersionID is the version of the operating system, such as "22.04"
+	// or "9". It is empty for rolling releases.
+	VersionID string
+
+	// VersionCodename is the lower-case code name of the version of the
+	// operating system, such as "jammy". It is empty if there is none.
+	VersionCodename string
+
+	// Fields holds all the fields of the os-release file, including the
+	// ones above, by their name.
+	Fields map[string]string
+}
+
+// MajorVersion returns the part of VersionID before its first dot.
+func (r OSRelease) MajorVersion() string {
+	major, _, _ := strings.Cut(r.VersionID, ".")
+	return major
+}
+
+// ParseOSRelease parses the contents of an os-release file, which consists
+// of KEY=VALUE lines with optionally quoted values, as described by
+// os-release(5).
+func ParseOSRelease(r io.Reader) (OSRelease, error) {
+	res := OSRelease{Fields: make(map[string]string)}
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return OSRelease{}, errors.NotValidf("os-release line %d %q", lineNo, line)
+		}
+		value, err := unquoteOSReleaseValue(value)
+		if err != nil {
+			return OSRelease{}, errors.Annotatef(err, "os-release line %d", lineNo)
+		}
+		res.Fields[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return OSRelease{}, errors.Trace(err)
+	}
+
+	res.ID = res.Fields["ID"]
+	res.IDLike = strings.Fields(res.Fields["ID_LIKE"])
+	res.VersionID = res.Fields["VERSION_ID"]
+	res.VersionCodename = res.Fields["VERSION_CODENAME"]
+	// os-release(5) states that ID defaults to "linux".
+	if res.ID == "" {
+		res.ID = "linux"
+	}
+	return res, nil
+}
+
+// unquoteOSReleaseValue removes the shell-style quoting of an os-release
+// value. Within double quotes, backslashes escape the characters which are
+// special to the shell.
+func unquoteOSReleaseValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	quote := value[0]
+	if quote != '"' && quote != '\'' {
+		return value, nil
+	}
+	if len(value) < 2 || value[len(value)-1] != quote {
+		return "", errors.NotValidf("os-release value %s with unbalanced quotes", value)
+	}
+	value = value[1 : len(value)-1]
+	if quote == '\'' {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c == '\\' && i+1 < len(value) && strings.IndexByte("$\"\\`", value[i+1]) >= 0 {
+			i++
+			c = value[i]
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String(), nil
+}
+
+// ReadOSRelease reads and parses the os-release file of the filesystem
+// tree at the given root, which is "/" for the running system. An error
+// satisfying errors.IsNotFound is returned if there is no such file.
+func ReadOSRelease(root string) (OSRelease, error) {
+	f, err := os.Open(filepath.Join(root, OSReleasePath))
+	if os.IsNotExist(err) {
+		// /etc/os-release is commonly a symlink to the fallback, which
+		// may be absolute and thus dangling when root is not "/".
+		f, err = os.Open(filepath.Join(root, OSReleaseFallbackPath))
+	}
+	if os.IsNotExist(err) {
+		return OSRelease{}, errors.NotFoundf("os-release file in %q", root)
+	} else if err != nil {
+		return OSRelease{}, errors.Trace(err)
+	}
+	defer f.Close()
+
+	res, err := ParseOSRelease(f)
+	return res, errors.Annotatef(err, "reading %s", f.Name())
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package distro_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/distro"
+)
+
+var _ = gc.Suite(&OSReleaseSuite{})
+
+type OSReleaseSuite struct{}
+
+const ubuntuOSRelease = `PRETTY_NAME="Ubuntu 22.04.3 LTS"
+NAME="Ubuntu"
+VERSION_ID="22.04"
+VERSION="22.04.3 LTS (Jammy Jellyfish)"
+VERSION_CODENAME=jammy
+ID=ubuntu
+ID_LIKE=debian
+HOME_URL="https://www.ubuntu.com/"
+UBUNTU_CODENAME=jammy
+`
+
+// writeOSRelease writes the given os-release contents to the given path
+// below a new root directory, which is returned.
+func writeOSRelease(c *gc.C, path, contents string) string {
+	root := c.MkDir()
+	path = filepath.Join(root, path)
+	c.Assert(os.MkdirAll(filepath.Dir(path), 0755), jc.ErrorIsNil)
+	c.Assert(os.WriteFile(path, []byte(contents), 0644), jc.ErrorIsNil)
+	return root
+}
+
+func (s *OSReleaseSuite) TestParseOSRelease(c *gc.C) {
+	rel, err := distro.ParseOSRelease(strings.NewReader(ubuntuOSRelease))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.ID, gc.Equals, "ubuntu")
+	c.Assert(rel.IDLike, gc.DeepEquals, []string{"debian"})
+	c.Assert(rel.VersionID, gc.Equals, "22.04")
+	c.Assert(rel.MajorVersion(), gc.Equals, "22")
+	c.Assert(rel.VersionCodename, gc.Equals, "jammy")
+	c.Assert(rel.Fields["PRETTY_NAME"], gc.Equals, "Ubuntu 22.04.3 LTS")
+	c.Assert(rel.Fields, gc.HasLen, 9)
+}
+
+func (s *OSReleaseSuite) TestParseOSReleaseQuoting(c *gc.C) {
+	rel, err := distro.ParseOSRelease(strings.NewReader(`
+# a comment
+
+ID='rocky'
+ID_LIKE="rhel centos fedora"
+NAME="Some \"quoted\" \$name\\"
+VERSION_ID=
+`))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.ID, gc.Equals, "rocky")
+	c.Assert(rel.IDLike, gc.DeepEquals, []string{"rhel", "centos", "fedora"})
+	c.Assert(rel.Fields["NAME"], gc.Equals, `Some "quoted" $name\`)
+	c.Assert(rel.VersionID, gc.Equals, "")
+}
+
+func (s *OSReleaseSuite) TestParseOSReleaseDefaultID(c *gc.C) {
+	rel, err := distro.ParseOSRelease(strings.NewReader("NAME=Linux\n"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.ID, gc.Equals, "linux")
+}
+
+func (s *OSReleaseSuite) TestParseOSReleaseInvalid(c *gc.C) {
+	for i, test := range []struct {
+		contents string
+		err      string
+	}{{
+		contents: "ID=ubuntu\nnot a field\n",
+		err:      `os-release line 2 "not a field" not valid`,
+	}, {
+		contents: `NAME="Ubuntu`,
+		err:      `os-release line 1: os-release value "Ubuntu with unbalanced quotes not valid`,
+	}} {
+		c.Logf("test %d: %q", i, test.contents)
+		_, err := distro.ParseOSRelease(strings.NewReader(test.contents))
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *OSReleaseSuite) TestReadOSRelease(c *gc.C) {
+	root := writeOSRelease(c, "etc/os-release", ubuntuOSRelease)
+
+	rel, err := distro.ReadOSRelease(root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.ID, gc.Equals, "ubuntu")
+}
+
+func (s *OSReleaseSuite) TestReadOSReleaseFallback(c *gc.C) {
+	root := writeOSRelease(c, "usr/lib/os-release", "ID=alpine\nVERSION_ID=3.18.4\n")
+	// An absolute symlink points outside of the root.
+	c.Assert(os.MkdirAll(filepath.Join(root, "etc"), 0755), jc.ErrorIsNil)
+	err := os.Symlink("/nonexistent/usr/lib/os-release", filepath.Join(root, "etc", "os-release"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	rel, err := distro.ReadOSRelease(root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(rel.ID, gc.Equals, "alpine")
+	c.Assert(rel.VersionID, gc.Equals, "3.18.4")
+}
+
+func (s *OSReleaseSuite) TestReadOSReleaseNotFound(c *gc.C) {
+	_, err := distro.ReadOSRelease(c.MkDir())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
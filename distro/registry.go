@@ -0,0 +1,173 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package distro
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/manager"
+)
+
+// Matcher returns whether a backend supports the operating system with the
+// given identifier, which is either rel.ID or one of rel.IDLike.
+type Matcher func(id string, rel OSRelease) bool
+
+// MatchIDs returns a Matcher which matches any of the given operating
+// system identifiers, regardless of their version.
+func MatchIDs(ids ...string) Matcher {
+	return func(id string, _ OSRelease) bool {
+		for _, candidate := range ids {
+			if id == candidate {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Registration describes a backend which can be selected by Detect,
+// DetectTarget, ForOSRelease and Lookup.
+type Registration struct {
+	// Name is the unique name of the backend, such as "apt".
+	Name string
+
+	// Match returns whether the backend supports an operating system.
+	Match Matcher
+
+	// NewCommander returns the PackageCommander of the backend.
+	NewCommander func() commands.PackageCommander
+
+	// NewManager returns the PackageManager of the backend.
+	NewManager func() manager.PackageManager
+
+	// NewManagerWithExecutor returns the PackageManager of the backend,
+	// which runs its commands with the given Executor. It is optional,
+	// but backends without it cannot be selected by DetectTarget.
+	NewManagerWithExecutor func(manager.Executor) manager.PackageManager
+
+	// NewConfigurer returns the PackagingConfigurer of the backend.
+	NewConfigurer func() config.PackagingConfigurer
+}
+
+// Validate returns an error satisfying errors.IsNotValid if the
+// registration lacks its name, matcher or any of its factories.
+func (r Registration) Validate() error {
+	switch {
+	case r.Name == "":
+		return errors.NotValidf("backend registration without name")
+	case r.Match == nil:
+		return errors.NotValidf("backend %q registration without matcher", r.Name)
+	case r.NewCommander == nil || r.NewManager == nil || r.NewConfigurer == nil:
+		return errors.NotValidf("backend %q registration without factories", r.Name)
+	}
+	return nil
+}
+
+// newBackend returns a Backend built with the factories of r.
+func (r Registration) newBackend() Backend {
+	return Backend{
+		Name:       r.Name,
+		Commander:  r.NewCommander(),
+		Manager:    r.NewManager(),
+		Configurer: r.NewConfigurer(),
+	}
+}
+
+// newTargetBackend returns a Backend built with the factories of r, whose
+// Manager runs its commands with the given Executor.
+func (r Registration) newTargetBackend(executor manager.Executor) (Backend, error) {
+	if r.NewManagerWithExecutor == nil {
+		return Backend{}, errors.NotSupportedf("backend %q running commands in a target", r.Name)
+	}
+	backend := r.newBackend()
+	backend.Manager = r.NewManagerWithExecutor(executor)
+	return backend, nil
+}
+
+var (
+	registryMu sync.RWMutex
+	// registry holds the registered backends in the order of their
+	// registration.
+	registry []Registration
+)
+
+// Register adds the given backend to the registry. Backends are matched
+// against operating systems in the reverse order of their registration, so
+// a backend takes precedence over all backends registered before it,
+// including the built-in ones. A backend registered with the name of an
+// existing one replaces it.
+func Register(reg Registration) error {
+	if err := reg.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(removeRegistration(registry, reg.Name), reg)
+	return nil
+}
+
+// Unregister removes the backend with the given name from the registry,
+// returning whether there was one.
+func Unregister(name string) bool {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	n := len(registry)
+	registry = removeRegistration(registry, name)
+	return len(registry) != n
+}
+
+// removeRegistration returns regs without the registration with the given
+// name.
+func removeRegistration(regs []Registration, name string) []Registration {
+	res := make([]Registration, 0, len(regs)+1)
+	for _, reg := range regs {
+		if reg.Name != name {
+			res = append(res, reg)
+		}
+	}
+	return res
+}
+
+// Lookup returns the registered backend with the given name. An error
+// satisfying errors.IsNotFound is returned if there is none.
+func Lookup(name string) (Backend, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for _, reg := range registry {
+		if reg.Name == name {
+			return reg.newBackend(), nil
+		}
+	}
+	return Backend{}, errors.NotFoundf("packaging backend %q", name)
+}
+
+// Names returns the names of all registered backends, in the order in
+// which they are matched against operating systems.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for i := len(registry) - 1; i >= 0; i-- {
+		names = append(names, registry[i].Name)
+	}
+	return names
+}
+
+// match returns the registered backend which supports the operating system
+// with the given identifier, if any.
+func match(id string, rel OSRelease) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	for i := len(registry) - 1; i >= 0; i-- {
+		if registry[i].Match(id, rel) {
+			return registry[i], true
+		}
+	}
+	return Registration{}, false
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package distro_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/distro"
+	"github.com/juju/packaging/v3/manager"
+)
+
+var _ = gc.Suite(&RegistrySuite{})
+
+type RegistrySuite struct{}
+
+// portageRegistration is a backend registered by tests, which reuses the
+// apt implementations.
+var portageRegistration = distro.Registration{
+	Name:          "portage",
+	Match:         distro.MatchIDs("gentoo"),
+	NewCommander:  commands.NewAptPackageCommander,
+	NewManager:    manager.NewAptPackageManager,
+	NewConfigurer: config.NewAptPackagingConfigurer,
+}
+
+func (s *RegistrySuite) TestBuiltinNames(c *gc.C) {
+	c.Assert(distro.Names(), gc.DeepEquals, []string{"pacman", "apk", "zypper", "dnf", "yum", "apt"})
+}
+
+func (s *RegistrySuite) TestLookup(c *gc.C) {
+	backend, err := distro.Lookup("zypper")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "zypper")
+	c.Assert(backend.Commander, gc.Equals, commands.NewZypperPackageCommander())
+	c.Assert(backend.Configurer, gc.DeepEquals, config.NewZypperPackagingConfigurer())
+}
+
+func (s *RegistrySuite) TestLookupNotFound(c *gc.C) {
+	_, err := distro.Lookup("portage")
+	c.Assert(err, gc.ErrorMatches, `packaging backend "portage" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RegistrySuite) TestRegister(c *gc.C) {
+	c.Assert(distro.Register(portageRegistration), jc.ErrorIsNil)
+	defer distro.Unregister("portage")
+
+	c.Assert(distro.Names()[0], gc.Equals, "portage")
+	backend, err := distro.Lookup("portage")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "portage")
+
+	backend, err = distro.ForOSRelease(distro.OSRelease{ID: "gentoo"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "portage")
+}
+
+func (s *RegistrySuite) TestRegisterTakesPrecedence(c *gc.C) {
+	reg := portageRegistration
+	reg.Match = distro.MatchIDs("gentoo", "debian")
+	c.Assert(distro.Register(reg), jc.ErrorIsNil)
+	defer distro.Unregister("portage")
+
+	backend, err := distro.ForOSRelease(distro.OSRelease{ID: "debian"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "portage")
+
+	// Matches of ID still take precedence over matches of ID_LIKE.
+	backend, err = distro.ForOSRelease(distro.OSRelease{ID: "ubuntu", IDLike: []string{"debian"}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "apt")
+}
+
+func (s *RegistrySuite) TestRegisterReplacesByName(c *gc.C) {
+	c.Assert(distro.Register(portageRegistration), jc.ErrorIsNil)
+	defer distro.Unregister("portage")
+
+	reg := portageRegistration
+	reg.Match = distro.MatchIDs("funtoo")
+	c.Assert(distro.Register(reg), jc.ErrorIsNil)
+
+	c.Assert(distro.Names(), gc.HasLen, 7)
+	_, err := distro.ForOSRelease(distro.OSRelease{ID: "gentoo"})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	backend, err := distro.ForOSRelease(distro.OSRelease{ID: "funtoo"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(backend.Name, gc.Equals, "portage")
+}
+
+func (s *RegistrySuite) TestUnregister(c *gc.C) {
+	c.Assert(distro.Register(portageRegistration), jc.ErrorIsNil)
+
+	c.Assert(distro.Unregister("portage"), jc.IsTrue)
+	c.Assert(distro.Unregister("portage"), jc.IsFalse)
+	_, err := distro.Lookup("portage")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *RegistrySuite) TestRegisterInvalid(c *gc.C) {
+	for i, test := range []struct {
+		mutate func(*distro.Registration)
+		err    string
+	}{{
+		mutate: func(r *distro.Registration) { r.Name = "" },
+		err:    `backend registration without name not valid`,
+	}, {
+		mutate: func(r *distro.Registration) { r.Match = nil },
+		err:    `backend "portage" registration without matcher not valid`,
+	}, {
+		mutate: func(r *distro.Registration) { r.NewManager = nil },
+		err:    `backend "portage" registration without factories not valid`,
+	}} {
+		c.Logf("test %d", i)
+		reg := portageRegistration
+		test.mutate(&reg)
+		err := distro.Register(reg)
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+	c.Assert(distro.Names(), gc.HasLen, 6)
+}
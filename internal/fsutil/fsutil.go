@@ -0,0 +1,41 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package fsutil holds the filesystem helpers shared by the packages
+// managing files on the target system.
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+)
+
+// WriteFileAtomic writes the given data to a temporary file with the given
+// permissions next to the given path, which is then renamed to it,
+// creating its directory if needed. Readers of the path see either its
+// previous contents or the new ones, never a partial write.
+func WriteFileAtomic(p string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errors.Trace(err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".tmp")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return errors.Trace(err)
+	}
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return errors.Trace(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(os.Rename(f.Name(), p))
+}
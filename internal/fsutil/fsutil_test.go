@@ -0,0 +1,58 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package fsutil_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/internal/fsutil"
+)
+
+var _ = gc.Suite(&FSUtilSuite{})
+
+type FSUtilSuite struct {
+	testing.IsolationSuite
+}
+
+func (s *FSUtilSuite) TestWriteFileAtomic(c *gc.C) {
+	p := filepath.Join(c.MkDir(), "etc", "apt", "auth.conf.d", "juju.conf")
+
+	err := fsutil.WriteFileAtomic(p, []byte("first\n"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	err = fsutil.WriteFileAtomic(p, []byte("second\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := os.ReadFile(p)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "second\n")
+	info, err := os.Stat(p)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(info.Mode().Perm(), gc.Equals, os.FileMode(0600))
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(p))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(entries, gc.HasLen, 1)
+}
+
+func (s *FSUtilSuite) TestWriteFileAtomicFailureRemovesTemporaryFile(c *gc.C) {
+	dir := c.MkDir()
+	// The path is a directory which is not empty, so it cannot be
+	// replaced.
+	p := filepath.Join(dir, "yum.conf")
+	c.Assert(os.MkdirAll(filepath.Join(p, "keep"), 0755), jc.ErrorIsNil)
+
+	err := fsutil.WriteFileAtomic(p, []byte("data"), 0644)
+	c.Assert(err, gc.NotNil)
+
+	entries, err := os.ReadDir(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	c.Check(entries[0].Name(), gc.Equals, "yum.conf")
+}
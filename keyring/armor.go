@@ -0,0 +1,111 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+const (
+	armorBegin = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+	armorEnd   = "-----END PGP PUBLIC KEY BLOCK-----"
+)
+
+// IsArmored returns whether the given key is an ASCII-armored OpenPGP
+// public key block.
+func IsArmored(key []byte) bool {
+	return bytes.Contains(key, []byte(armorBegin))
+}
+
+// Dearmor returns the binary OpenPGP packets of the given ASCII-armored
+// public key blocks, as "gpg --dearmor" does. Keys which are already
+// binary are returned as they are. An error satisfying errors.IsNotValid
+// is returned for malformed keys.
+func Dearmor(key []byte) ([]byte, error) {
+	if !IsArmored(key) {
+		// Binary OpenPGP packets always have the most significant bit of
+		// their first byte set.
+		if len(key) == 0 || key[0]&0x80 == 0 {
+			return nil, errors.NotValidf("OpenPGP key")
+		}
+		return key, nil
+	}
+
+	var res []byte
+	lines := strings.Split(strings.ReplaceAll(string(key), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != armorBegin {
+			continue
+		}
+		block, next, err := decodeArmorBlock(lines[i+1:])
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		res = append(res, block...)
+		i += next
+	}
+	return res, nil
+}
+
+// decodeArmorBlock decodes the lines of an armored block following its
+// BEGIN line, returning the decoded data and the number of lines consumed.
+func decodeArmorBlock(lines []string) ([]byte, int, error) {
+	// Skip the armor headers, which end with an empty line.
+	i := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		if !strings.Contains(lines[i], ": ") {
+			// Some tools omit the empty line if there are no headers.
+			break
+		}
+	}
+
+	var (
+		data     strings.Builder
+		checksum string
+	)
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+		case line == armorEnd:
+			decoded, err := base64.StdEncoding.DecodeString(data.String())
+			if err != nil {
+				return nil, 0, errors.NewNotValid(err, "armored OpenPGP key")
+			}
+			if checksum != "" && checksum != armorChecksum(decoded) {
+				return nil, 0, errors.NotValidf("armored OpenPGP key checksum")
+			}
+			return decoded, i + 1, nil
+		case strings.HasPrefix(line, "="):
+			checksum = line[1:]
+		default:
+			data.WriteString(line)
+		}
+	}
+	return nil, 0, errors.NotValidf("armored OpenPGP key without end")
+}
+
+// armorChecksum returns the base64 encoded CRC-24 checksum of the given
+// data, as defined by RFC 4880.
+func armorChecksum(data []byte) string {
+	const (
+		crc24Init = 0xb704ce
+		crc24Poly = 0x1864cfb
+	)
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)})
+}
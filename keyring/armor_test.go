@@ -0,0 +1,66 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package keyring_test
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/keyring"
+)
+
+var _ = gc.Suite(&ArmorSuite{})
+
+type ArmorSuite struct{}
+
+func (s *ArmorSuite) TestDearmor(c *gc.C) {
+	c.Assert(keyring.IsArmored([]byte(testKey)), jc.IsTrue)
+
+	data, err := keyring.Dearmor([]byte(testKey))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, testKeyData)
+}
+
+func (s *ArmorSuite) TestDearmorMultipleBlocks(c *gc.C) {
+	key := "preamble\r\n" + strings.ReplaceAll(testKey, "\n", "\r\n") + testKey
+
+	data, err := keyring.Dearmor([]byte(key))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, testKeyData+testKeyData)
+}
+
+func (s *ArmorSuite) TestDearmorBinary(c *gc.C) {
+	c.Assert(keyring.IsArmored([]byte(testKeyData)), jc.IsFalse)
+
+	data, err := keyring.Dearmor([]byte(testKeyData))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, testKeyData)
+}
+
+func (s *ArmorSuite) TestDearmorInvalid(c *gc.C) {
+	for i, test := range []struct {
+		key string
+		err string
+	}{{
+		key: "not a key",
+		err: "OpenPGP key not valid",
+	}, {
+		key: strings.Replace(testKey, "=CApW", "=AAAA", 1),
+		err: "armored OpenPGP key checksum not valid",
+	}, {
+		key: strings.Replace(testKey, "mQANBGp1anUtdGVzdC1rZXk=", "mQ!!", 1),
+		err: "armored OpenPGP key: .*",
+	}, {
+		key: strings.Split(testKey, "=CApW")[0],
+		err: "armored OpenPGP key without end not valid",
+	}} {
+		c.Logf("test %d", i)
+		_, err := keyring.Dearmor([]byte(test.key))
+		c.Check(err, gc.ErrorMatches, test.err)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package keyring installs the OpenPGP keys which package sources are
+// signed with as separate keyring files, so that each source can be
+// restricted to its own key instead of it being trusted globally.
+package keyring
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/internal/fsutil"
+)
+
+const (
+	// AptKeyringDir is the directory in which the keyrings referenced
+	// by the signed-by option of apt sources are installed.
+	AptKeyringDir = "/usr/share/keyrings"
+
+	// RPMKeyDir is the directory in which the keys referenced by the
+	// gpgkey option of yum and zypper repositories are installed.
+	RPMKeyDir = "/etc/pki/rpm-gpg"
+
+	// manifestFile is the file, in the directory of a Keyring, which lists
+	// the names of the keys installed by it.
+	manifestFile = ".juju-keys"
+)
+
+// validKeyName matches the names keys can be installed with.
+var validKeyName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// Key is a key installed by a Keyring.
+type Key struct {
+	// Name is the name the key was installed with.
+	Name string
+
+	// Path is the path of the key file on the target system, which is
+	// what sources reference.
+	Path string
+}
+
+// Keyring installs keys as files in a directory of a filesystem tree.
+type Keyring struct {
+	root    string
+	dir     string
+	dearmor bool
+	prefix  string
+	suffix  string
+}
+
+// NewAptKeyring returns a Keyring which installs dearmored keys as
+// "<name>.gpg" into AptKeyringDir below the given root, which is "/" for
+// the running system.
+func NewAptKeyring(root string) *Keyring {
+	return &Keyring{
+		root:    root,
+		dir:     AptKeyringDir,
+		dearmor: true,
+		suffix:  ".gpg",
+	}
+}
+
+// NewRPMKeyring returns a Keyring which installs armored keys, as rpm
+// requires them, as "RPM-GPG-KEY-<name>" into RPMKeyDir below the given
+// root, which is "/" for the running system.
+func NewRPMKeyring(root string) *Keyring {
+	return &Keyring{
+		root:   root,
+		dir:    RPMKeyDir,
+		prefix: "RPM-GPG-KEY-",
+	}
+}
+
+// Path returns the path on the target system of the key with the given
+// name.
+func (k *Keyring) Path(name string) string {
+	return path.Join(k.dir, k.prefix+name+k.suffix)
+}
+
+// hostPath returns the path below the root of the given path on the target
+// system.
+func (k *Keyring) hostPath(p string) string {
+	return filepath.Join(k.root, filepath.FromSlash(p))
+}
+
+// Install installs the given armored or binary key under the given name,
+// replacing any key previously installed under it, and returns its path
+// on the target system.
+func (k *Keyring) Install(name string, key []byte) (string, error) {
+	data, err := k.keyData(name, key)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+
+	if err := os.MkdirAll(k.hostPath(k.dir), 0755); err != nil {
+		return "", errors.Trace(err)
+	}
+	p := k.Path(name)
+	if err := fsutil.WriteFileAtomic(k.hostPath(p), data, 0644); err != nil {
+		return "", errors.Annotatef(err, "installing key %q", name)
+	}
+
+	names, err := k.readManifest()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if _, ok := names[name]; !ok {
+		names[name] = struct{}{}
+		if err := k.writeManifest(names); err != nil {
+			return "", errors.Trace(err)
+		}
+	}
+	return p, nil
+}
+
+// IsInstalled returns whether the given armored or binary key is installed
+// under the given name, as Install would install it.
+func (k *Keyring) IsInstalled(name string, key []byte) (bool, error) {
+	data, err := k.keyData(name, key)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	existing, err := os.ReadFile(k.hostPath(k.Path(name)))
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Trace(err)
+	}
+	names, err := k.readManifest()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	_, managed := names[name]
+	return managed && bytes.Equal(existing, data), nil
+}
+
+// keyData returns the contents of the file the given key is installed as
+// under the given name.
+func (k *Keyring) keyData(name string, key []byte) ([]byte, error) {
+	if !validKeyName.MatchString(name) {
+		return nil, errors.NotValidf("key name %q", name)
+	}
+	if k.dearmor {
+		data, err := Dearmor(key)
+		return data, errors.Annotatef(err, "key %q", name)
+	} else if !IsArmored(key) {
+		return nil, errors.NotValidf("key %q without armor", name)
+	}
+	return key, nil
+}
+
+// InstallSource installs the key of the given source, if it has one, under
+// a name derived from the name of the source, and sets the SignedBy field
+// of the source to the path of the installed key.
+func (k *Keyring) InstallSource(src *packaging.PackageSource) error {
+	if src.Key == "" {
+		return nil
+	}
+	p, err := k.Install(SourceKeyName(*src), []byte(src.Key))
+	if err != nil {
+		return errors.Annotatef(err, "source %q", src.Name)
+	}
+	src.SignedBy = p
+	return nil
+}
+
+// SourceKeyName returns the name under which the key of the given source
+// is installed by InstallSource.
+func SourceKeyName(src packaging.PackageSource) string {
+	return src.BaseName()
+}
+
+// List returns the keys installed by the Keyring, sorted by name.
+func (k *Keyring) List() ([]Key, error) {
+	names, err := k.readManifest()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	res := make([]Key, 0, len(names))
+	for name := range names {
+		res = append(res, Key{Name: name, Path: k.Path(name)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// Remove removes the key installed under the given name. An error
+// satisfying errors.IsNotFound is returned if the Keyring did not install
+// a key under that name; keys installed by other means are never removed.
+func (k *Keyring) Remove(name string) error {
+	names, err := k.readManifest()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := names[name]; !ok {
+		return errors.NotFoundf("key %q", name)
+	}
+	if err := os.Remove(k.hostPath(k.Path(name))); err != nil && !os.IsNotExist(err) {
+		return errors.Annotatef(err, "removing key %q", name)
+	}
+	delete(names, name)
+	return errors.Trace(k.writeManifest(names))
+}
+
+// readManifest returns the names listed in the manifest of the Keyring.
+func (k *Keyring) readManifest() (map[string]struct{}, error) {
+	names := make(map[string]struct{})
+	f, err := os.Open(k.hostPath(path.Join(k.dir, manifestFile)))
+	if os.IsNotExist(err) {
+		return names, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" && !strings.HasPrefix(name, "#") {
+			names[name] = struct{}{}
+		}
+	}
+	return names, errors.Trace(scanner.Err())
+}
+
+// writeManifest replaces the manifest of the Keyring with one listing the
+// given names, or removes it if there are none.
+func (k *Keyring) writeManifest(names map[string]struct{}) error {
+	p := k.hostPath(path.Join(k.dir, manifestFile))
+	if len(names) == 0 {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		return nil
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	data := "# Keys installed by Juju\n" + strings.Join(sorted, "\n") + "\n"
+	return errors.Trace(fsutil.WriteFileAtomic(p, []byte(data), 0644))
+}
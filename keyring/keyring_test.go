@@ -0,0 +1,149 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package keyring_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/keyring"
+)
+
+var _ = gc.Suite(&KeyringSuite{})
+
+type KeyringSuite struct {
+	root string
+}
+
+func (s *KeyringSuite) SetUpTest(c *gc.C) {
+	s.root = c.MkDir()
+}
+
+func (s *KeyringSuite) TestAptInstall(c *gc.C) {
+	kr := keyring.NewAptKeyring(s.root)
+
+	p, err := kr.Install("juju", []byte(testKey))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(p, gc.Equals, "/usr/share/keyrings/juju.gpg")
+
+	hostPath := filepath.Join(s.root, "usr", "share", "keyrings", "juju.gpg")
+	data, err := os.ReadFile(hostPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, testKeyData)
+
+	info, err := os.Stat(hostPath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Mode().Perm(), gc.Equals, os.FileMode(0644))
+}
+
+func (s *KeyringSuite) TestRPMInstall(c *gc.C) {
+	kr := keyring.NewRPMKeyring(s.root)
+
+	p, err := kr.Install("juju", []byte(testKey))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(p, gc.Equals, "/etc/pki/rpm-gpg/RPM-GPG-KEY-juju")
+
+	data, err := os.ReadFile(filepath.Join(s.root, "etc", "pki", "rpm-gpg", "RPM-GPG-KEY-juju"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, testKey)
+
+	_, err = kr.Install("binary", []byte(testKeyData))
+	c.Assert(err, gc.ErrorMatches, `key "binary" without armor not valid`)
+}
+
+func (s *KeyringSuite) TestInstallInvalid(c *gc.C) {
+	kr := keyring.NewAptKeyring(s.root)
+
+	_, err := kr.Install("../escape", []byte(testKey))
+	c.Assert(err, gc.ErrorMatches, `key name "../escape" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+
+	_, err = kr.Install("juju", []byte("garbage"))
+	c.Assert(err, gc.ErrorMatches, `key "juju": OpenPGP key not valid`)
+
+	keys, err := kr.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.HasLen, 0)
+}
+
+func (s *KeyringSuite) TestListAndRemove(c *gc.C) {
+	kr := keyring.NewAptKeyring(s.root)
+	for _, name := range []string{"b", "a", "b"} {
+		_, err := kr.Install(name, []byte(testKey))
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	// Keys installed by other means are neither listed nor removed.
+	other := filepath.Join(s.root, "usr", "share", "keyrings", "ubuntu-archive-keyring.gpg")
+	c.Assert(os.WriteFile(other, []byte(testKeyData), 0644), jc.ErrorIsNil)
+
+	keys, err := kr.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.DeepEquals, []keyring.Key{
+		{Name: "a", Path: "/usr/share/keyrings/a.gpg"},
+		{Name: "b", Path: "/usr/share/keyrings/b.gpg"},
+	})
+
+	c.Assert(kr.Remove("a"), jc.ErrorIsNil)
+	err = kr.Remove("ubuntu-archive-keyring")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = os.Stat(other)
+	c.Assert(err, jc.ErrorIsNil)
+
+	keys, err = kr.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(keys, gc.DeepEquals, []keyring.Key{{Name: "b", Path: "/usr/share/keyrings/b.gpg"}})
+	_, err = os.Stat(filepath.Join(s.root, "usr", "share", "keyrings", "a.gpg"))
+	c.Assert(os.IsNotExist(err), jc.IsTrue)
+
+	// Removing the last key removes the manifest.
+	c.Assert(kr.Remove("b"), jc.ErrorIsNil)
+	entries, err := os.ReadDir(filepath.Join(s.root, "usr", "share", "keyrings"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 1)
+	c.Assert(entries[0].Name(), gc.Equals, "ubuntu-archive-keyring.gpg")
+}
+
+func (s *KeyringSuite) TestInstallSource(c *gc.C) {
+	kr := keyring.NewAptKeyring(s.root)
+	src := packaging.PackageSource{
+		Name: "Some Totally Official Source.",
+		URL:  "http://some-source.com/ubuntu",
+		Key:  testKey,
+	}
+	c.Assert(keyring.SourceKeyName(src), gc.Equals, "some-totally-official-source")
+
+	c.Assert(kr.InstallSource(&src), jc.ErrorIsNil)
+	c.Assert(src.SignedBy, gc.Equals, "/usr/share/keyrings/some-totally-official-source.gpg")
+
+	keyless := packaging.PackageSource{Name: "keyless"}
+	c.Assert(kr.InstallSource(&keyless), jc.ErrorIsNil)
+	c.Assert(keyless.SignedBy, gc.Equals, "")
+}
+
+func (s *KeyringSuite) TestIsInstalled(c *gc.C) {
+	kr := keyring.NewAptKeyring(s.root)
+
+	installed, err := kr.IsInstalled("juju", []byte(testKey))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(installed, jc.IsFalse)
+
+	_, err = kr.Install("juju", []byte(testKey))
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Armored and binary forms of the same key are equivalent.
+	for _, key := range []string{testKey, testKeyData} {
+		installed, err = kr.IsInstalled("juju", []byte(key))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(installed, jc.IsTrue)
+	}
+
+	installed, err = kr.IsInstalled("juju", []byte("\x99other"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(installed, jc.IsFalse)
+}
@@ -0,0 +1,28 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package keyring_test
+
+import (
+	"testing"
+
+	gc "gopkg.in/check.v1"
+)
+
+func TestPackage(t *testing.T) {
+	gc.TestingT(t)
+}
+
+const (
+	// testKey is an armored OpenPGP public key block, whose packets are
+	// testKeyData.
+	testKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+Comment: test
+
+mQANBGp1anUtdGVzdC1rZXk=
+=CApW
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+	testKeyData = "\x99\x00\x0d\x04juju-test-key"
+)
@@ -0,0 +1,396 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/proxy"
+)
+
+var (
+	// apkRetryableRE matches the output of apk commands which failed due to
+	// the database being locked or transient network failures.
+	apkRetryableRE = regexp.MustCompile(`(?i)unable to lock database|temporary error|network error|dns lookup error|operation timed out`)
+
+	_ PackageManager        = (*Apk)(nil)
+	_ PackageManagerContext = (*Apk)(nil)
+)
+
+// Apk is the PackageManager implementation for apk-based systems.
+// As apk has no proxy configuration of its own, the proxy settings given
+// to SetProxy are passed to the commands it runs through their environment,
+// and persisted in commands.ApkProxyFilePath, which the login shells of the
+// system source.
+type Apk struct {
+	basePackageManager
+	repositoriesPath string
+}
+
+// NewApkPackageManager returns a PackageManager for apk-based systems.
+func NewApkPackageManager() *Apk {
+	return NewApkPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewApkPackageManagerWithExecutor returns a PackageManager for apk-based
+// systems, which runs its commands with the given Executor. The
+// repositories file is edited directly on the local system, below the Root
+// of the target of an Executor returned by NewTargetExecutor, and otherwise
+// with commands run by the Executor.
+func NewApkPackageManagerWithExecutor(executor Executor) *Apk {
+	manager := &Apk{
+		basePackageManager: basePackageManager{
+			executor:       executor,
+			cmder:          commands.NewApkPackageCommander(),
+			commander:      commands.NewApkCommander(),
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parseApkList,
+			parseAvailable: parseApkList,
+			parseVersion:   parseApkListVersion,
+			parseCandidate: parseApkPolicyCandidate,
+			specFields:     specFields{version: true, targetRelease: true},
+		},
+		repositoriesPath: hostPath(executor, commands.ApkRepositoriesFile),
+	}
+	manager.basePackageManager.retryable = manager
+	return manager
+}
+
+// Search is defined on the PackageManager interface.
+func (apk *Apk) Search(pack string) (bool, error) {
+	return apk.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (apk *Apk) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := apk.validatePackages(pack); err != nil {
+		return false, err
+	}
+	out, _, err := apk.runCommand(ctx, apk.commander.Search(pack), apk, apk.env)
+	if err != nil {
+		return false, err
+	}
+
+	// apk search --exact package returns no output if the search was
+	// unsuccessful.
+	return strings.TrimSpace(out) != "", nil
+}
+
+// Install is defined on the PackageManager interface.
+func (apk *Apk) Install(packs ...string) error {
+	return apk.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (apk *Apk) InstallContext(ctx context.Context, packs ...string) error {
+	if err := apk.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := apk.runCommand(ctx, apk.commander.Install(packs...), apk, apk.env)
+	return err
+}
+
+// InstallSpec is defined on the PackageManager interface.
+func (apk *Apk) InstallSpec(specs ...packaging.PackageSpec) error {
+	return apk.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (apk *Apk) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := apk.specFields.validate(apk.commander, specs); err != nil {
+		return err
+	}
+	_, _, err := apk.runCommand(ctx, apk.commander.InstallSpec(specs...), apk, apk.env)
+	return err
+}
+
+// AddRepository is defined on the PackageManager interface.
+// The repository is a line of the apk repositories file, such as
+// "https://dl-cdn.alpinelinux.org/alpine/v3.18/community", which is
+// appended to the file unless it is already listed.
+func (apk *Apk) AddRepository(repo string) error {
+	return apk.AddRepositoryContext(context.Background(), repo)
+}
+
+// AddRepositoryContext is defined on the PackageManagerContext interface.
+func (apk *Apk) AddRepositoryContext(ctx context.Context, repo string) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	repo, err := apk.repositoryLine(repo)
+	if err != nil {
+		return err
+	}
+
+	lines, mode, err := apk.readRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == repo {
+			return nil
+		}
+	}
+	return apk.writeRepositories(ctx, append(lines, repo), mode)
+}
+
+// RemoveRepository is defined on the PackageManager interface.
+// All the lines of the apk repositories file which list the repository
+// are removed.
+func (apk *Apk) RemoveRepository(repo string) error {
+	return apk.RemoveRepositoryContext(context.Background(), repo)
+}
+
+// RemoveRepositoryContext is defined on the PackageManagerContext interface.
+func (apk *Apk) RemoveRepositoryContext(ctx context.Context, repo string) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Trace(err)
+	}
+	repo, err := apk.repositoryLine(repo)
+	if err != nil {
+		return err
+	}
+
+	lines, mode, err := apk.readRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != repo {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+	return apk.writeRepositories(ctx, kept, mode)
+}
+
+// repositoryLine validates the given repository and returns it as a line
+// of the apk repositories file.
+func (apk *Apk) repositoryLine(repo string) (string, error) {
+	repo = strings.TrimSpace(repo)
+	if repo == "" || strings.ContainsAny(repo, "\r\n") {
+		return "", errors.NotValidf("apk repository %q", repo)
+	}
+	if err := apk.commander.ValidateAddRepository(repo); err != nil {
+		return "", errors.Trace(err)
+	}
+	return repo, nil
+}
+
+// readRepositories returns the lines of the apk repositories file along
+// with its permissions. A missing file has no lines.
+func (apk *Apk) readRepositories(ctx context.Context) ([]string, fs.FileMode, error) {
+	mode := fs.FileMode(0644)
+	var content string
+	if apk.repositoriesPath == "" {
+		// The file is read on the system the commands are run on, where
+		// it may be missing.
+		out, err := readFile(ctx, apk.executor, commands.ApkRepositoriesFile)
+		if err != nil {
+			return nil, mode, errors.Annotate(err, "reading apk repositories")
+		}
+		content = out
+	} else {
+		data, err := os.ReadFile(apk.repositoriesPath)
+		if os.IsNotExist(err) {
+			return nil, mode, nil
+		} else if err != nil {
+			return nil, mode, errors.Annotate(err, "reading apk repositories")
+		}
+		if info, err := os.Stat(apk.repositoriesPath); err == nil {
+			mode = info.Mode().Perm()
+		}
+		content = string(data)
+	}
+
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return nil, mode, nil
+	}
+	return strings.Split(content, "\n"), mode, nil
+}
+
+// writeRepositories replaces the contents of the apk repositories file with
+// the given lines. The permissions are those of a new file, as an existing
+// one written with commands keeps its own.
+func (apk *Apk) writeRepositories(ctx context.Context, lines []string, mode fs.FileMode) error {
+	var content string
+	if len(lines) > 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+	if apk.repositoriesPath != "" {
+		err := os.WriteFile(apk.repositoriesPath, []byte(content), mode)
+		return errors.Annotate(err, "writing apk repositories")
+	}
+
+	cmd := Command{Args: []string{"tee", "--", commands.ApkRepositoriesFile}, Stdin: []byte(content)}
+	if _, _, err := execute(ctx, apk.executor, cmd); err != nil {
+		logger.Errorf("command failed: %v\nargs: %#v", err, cmd.Args)
+		return errors.Annotate(err, "writing apk repositories")
+	}
+	return nil
+}
+
+// readFile returns the contents of the given file on the system the
+// given executor runs commands on, which are empty if it is missing.
+func readFile(ctx context.Context, executor Executor, p string) (string, error) {
+	cmd := Command{Args: []string{"sh", "-c", `[ ! -e "$1" ] || cat "$1"`, "sh", p}}
+	out, _, err := execute(ctx, executor, cmd)
+	if err != nil {
+		logger.Errorf("command failed: %v\nargs: %#v\n%s", err, cmd.Args, Redact(out))
+		return "", errors.Trace(err)
+	}
+	return out, nil
+}
+
+// GetProxySettings is defined on the PackageManager interface.
+func (apk *Apk) GetProxySettings() (proxy.Settings, error) {
+	return apk.GetProxySettingsContext(context.Background())
+}
+
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+// It returns the proxy settings persisted in commands.ApkProxyFilePath.
+func (apk *Apk) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	return apk.getProfileProxy(ctx, "apk", commands.ApkProxyFilePath)
+}
+
+// SetProxy is defined on the PackageManager interface.
+// It persists the proxy settings in commands.ApkProxyFilePath, replacing
+// the previous ones, and passes them to all subsequent commands through
+// their environment.
+func (apk *Apk) SetProxy(settings proxy.Settings) error {
+	return apk.SetProxyContext(context.Background(), settings)
+}
+
+// SetProxyContext is defined on the PackageManagerContext interface.
+func (apk *Apk) SetProxyContext(ctx context.Context, settings proxy.Settings) error {
+	// The file is only readable by its owner if the proxy URLs hold the
+	// credentials of authenticated proxies.
+	return apk.setProfileProxy(ctx, "apk", settings)
+}
+
+func (*Apk) IsRetryable(code int, output string) bool {
+	return code != 0 && apkRetryableRE.MatchString(output)
+}
+
+// MaskError will mask an error using the cmd exit code and the stdout/stderr
+// output.
+func (*Apk) MaskError(code int, output string) error {
+	// apk reports packages which cannot be found with
+	// "<name> (no such package)".
+	if strings.Contains(output, "(no such package)") {
+		return errors.New("unable to locate package")
+	}
+	return nil
+}
+
+// parseApkList parses the output of "apk list", which looks like:
+//
+//	bash-5.2.15-r5 x86_64 {bash} (GPL-3.0-or-later) [installed]
+//	curl-8.4.0-r0 x86_64 {curl} (curl) [upgradable from: curl-8.3.0-r0]
+//	zsh-5.9-r2 x86_64 {zsh} (custom)
+func parseApkList(output string) []PackageInfo {
+	var res []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, version := splitApkPackage(fields[0])
+		if version == "" {
+			continue
+		}
+
+		state := PackageAvailable
+		if strings.HasSuffix(line, "[installed]") {
+			state = PackageInstalled
+		}
+		res = append(res, PackageInfo{
+			Name:         name,
+			Version:      version,
+			Architecture: fields[1],
+			State:        state,
+		})
+	}
+	return res
+}
+
+// parseApkListVersion parses the version of a single package out of the
+// output of "apk list <name>".
+func parseApkListVersion(output string) string {
+	packs := parseApkList(output)
+	if len(packs) == 0 {
+		return ""
+	}
+	return packs[0].Version
+}
+
+// parseApkPolicyCandidate parses the version apk installs out of the output
+// of "apk policy <name>", which looks like:
+//
+//	bash policy:
+//	  5.2.15-r5:
+//	    lib/apk/db/installed
+//	  5.2.15-r6:
+//	    https://dl-cdn.alpinelinux.org/alpine/v3.18/main
+//	  5.2.21-r0:
+//	    @edge https://dl-cdn.alpinelinux.org/alpine/edge/main
+//
+// The versions are listed in ascending order, along with the repositories
+// providing them. Those of untagged repositories take precedence over those
+// of tagged ones, which apk only installs when asked to, which in turn take
+// precedence over those only installed. The highest version of the highest
+// precedence is the candidate.
+func parseApkPolicyCandidate(output string) string {
+	var candidate, current string
+	best, precedence := -1, -1
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasSuffix(trimmed, " policy:"):
+			continue
+		case strings.HasSuffix(trimmed, ":") && !strings.Contains(trimmed, " "):
+			current, precedence = strings.TrimSuffix(trimmed, ":"), 0
+		case current == "":
+			continue
+		case strings.HasPrefix(trimmed, "@"):
+			precedence = max(precedence, 1)
+		case trimmed != "lib/apk/db/installed":
+			precedence = 2
+		}
+		if current != "" && precedence >= best {
+			candidate, best = current, precedence
+		}
+	}
+	return candidate
+}
+
+// splitApkPackage splits an apk "name-version-rN" package identifier into
+// its name and version. The version is empty if the identifier has none.
+func splitApkPackage(pack string) (string, string) {
+	i := strings.LastIndexByte(pack, '-')
+	if i <= 0 || !strings.HasPrefix(pack[i+1:], "r") {
+		return pack, ""
+	}
+	j := strings.LastIndexByte(pack[:i], '-')
+	if j <= 0 || !isDigitPrefixed(pack[j+1:]) {
+		return pack, ""
+	}
+	return pack[:j], pack[j+1:]
+}
+
+// isDigitPrefixed returns whether the given string starts with a digit.
+func isDigitPrefixed(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
@@ -5,14 +5,14 @@
 package manager
 
 import (
-	"bytes"
-	"fmt"
-	"os/exec"
+	"context"
 	"regexp"
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
 	"github.com/juju/proxy"
 )
 
@@ -25,6 +25,10 @@ const (
 // the apt configuration file.
 var aptProxyRE = regexp.MustCompile(`(?im)^\s*Acquire::(?P<protocol>[a-z]+)::Proxy\s+"(?P<proxy>[^"]+)";\s*$`)
 
+// aptNoProxyRE matches the hosts for which apt bypasses the proxy, which
+// apt-config reports with their names unquoted.
+var aptNoProxyRE = regexp.MustCompile(`(?im)^\s*Acquire::[a-z]+::Proxy::"?(?P<host>[^"\s]+)"?\s+"DIRECT";\s*$`)
+
 // apt is the PackageManager implementation for deb-based systems.
 type apt struct {
 	basePackageManager
@@ -33,10 +37,25 @@ type apt struct {
 
 // NewAptPackageManager returns a PackageManager for apt-based systems.
 func NewAptPackageManager() PackageManager {
+	return NewAptPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewAptPackageManagerWithExecutor returns a PackageManager for apt-based
+// systems, which runs its commands with the given Executor.
+func NewAptPackageManagerWithExecutor(executor Executor) PackageManager {
 	manager := &apt{
 		basePackageManager: basePackageManager{
-			cmder:       commands.NewAptPackageCommander(),
-			retryPolicy: DefaultRetryPolicy(),
+			executor:       executor,
+			cmder:          commands.NewAptPackageCommander(),
+			commander:      commands.NewAptCommander(),
+			proxyConfig:    "apt",
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parseDpkgList,
+			parseAvailable: parseAptPackageNames,
+			parseVersion:   parseDpkgStatusVersion,
+			parseCandidate: parseAptPolicyCandidate,
+			specFields:     specFields{version: true, architecture: true, targetRelease: true},
+			versionScheme:  version.DebianScheme,
 		},
 		installRetryable: makeAPTInstallRetryable(APTExitCode),
 	}
@@ -48,7 +67,15 @@ func NewAptPackageManager() PackageManager {
 
 // Search is defined on the PackageManager interface.
 func (apt *apt) Search(pack string) (bool, error) {
-	out, _, err := RunCommandWithRetry(apt.cmder.SearchCmd(pack), apt, apt.retryPolicy, nil)
+	return apt.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (apt *apt) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := apt.validatePackages(pack); err != nil {
+		return false, err
+	}
+	out, _, err := apt.runCommand(ctx, apt.commander.Search(pack), apt, apt.env)
 	if err != nil {
 		return false, err
 	}
@@ -63,29 +90,51 @@ func (apt *apt) Search(pack string) (bool, error) {
 
 // Install is defined on the PackageManager interface.
 func (apt *apt) Install(packs ...string) error {
-	_, _, err := RunCommandWithRetry(apt.cmder.InstallCmd(packs...), apt.installRetryable, apt.retryPolicy, []string{commands.EnvFrontendNoninteractive})
+	return apt.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (apt *apt) InstallContext(ctx context.Context, packs ...string) error {
+	if err := apt.validatePackages(packs...); err != nil {
+		return err
+	}
+	return apt.install(ctx, apt.commander.Install(packs...))
+}
+
+// InstallSpec is defined on the PackageManager interface.
+func (apt *apt) InstallSpec(specs ...packaging.PackageSpec) error {
+	return apt.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (apt *apt) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := apt.specFields.validate(apt.commander, specs); err != nil {
+		return err
+	}
+	return apt.install(ctx, apt.commander.InstallSpec(specs...))
+}
+
+// install runs the given apt-get install command non-interactively.
+func (apt *apt) install(ctx context.Context, cmd commands.Command) error {
+	_, _, err := apt.runCommand(ctx, cmd, apt.installRetryable, append([]string{commands.EnvFrontendNoninteractive}, apt.env...))
 	return err
 }
 
 // GetProxySettings is defined on the PackageManager interface.
 func (apt *apt) GetProxySettings() (proxy.Settings, error) {
-	var res proxy.Settings
-
-	args := strings.Fields(apt.cmder.GetProxyCmd())
-	if len(args) <= 1 {
-		return proxy.Settings{}, fmt.Errorf("expected at least 2 arguments, got %d %v", len(args), args)
-	}
+	return apt.GetProxySettingsContext(context.Background())
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
-	out, err := CommandOutput(cmd)
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+func (apt *apt) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	var res proxy.Settings
 
+	out, _, err := apt.queryProxy(ctx, apt.commander.GetProxy())
 	if err != nil {
-		logger.Errorf("command failed: %v\nargs: %#v\n%s",
-			err, args, string(out))
-		return res, fmt.Errorf("command failed: %v", err)
+		return res, err
 	}
 
-	output := string(bytes.Join(aptProxyRE.FindAll(out, -1), []byte("\n")))
+	output := strings.Join(aptProxyRE.FindAllString(out, -1), "\n")
 
 	for _, match := range aptProxyRE.FindAllStringSubmatch(output, -1) {
 		switch match[1] {
@@ -97,10 +146,102 @@ func (apt *apt) GetProxySettings() (proxy.Settings, error) {
 			res.Ftp = match[2]
 		}
 	}
+	var hosts []string
+	for _, match := range aptNoProxyRE.FindAllStringSubmatch(out, -1) {
+		hosts = append(hosts, match[1])
+	}
+	res.NoProxy = strings.Join(commands.NoProxyHosts(strings.Join(hosts, ",")), ",")
 
 	return res, nil
 }
 
+// parseDpkgList parses the output of "dpkg-query --list", which looks like:
+//
+//	Desired=Unknown/Install/Remove/Purge/Hold
+//	| Status=Not/Inst/Conf-files/Unpacked/halF-conf/Half-inst/trig-aWait/Trig-pend
+//	|/ Err?=(none)/Reinst-required (Status,Err: uppercase=bad)
+//	||/ Name           Version      Architecture Description
+//	+++-==============-============-============-=========================
+//	ii  adduser        3.118ubuntu2 all          add and remove users
+//
+// Only packages which are currently installed are returned.
+func parseDpkgList(output string) []PackageInfo {
+	var res []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || len(fields[0]) < 2 || len(fields[0]) > 3 {
+			continue
+		}
+
+		// The first letter is the desired action and the second one the
+		// current status of the package; "i" stands for installed and
+		// "W" and "t" for installed with triggers pending.
+		status := fields[0]
+		if !strings.ContainsRune("iWt", rune(status[1])) {
+			continue
+		}
+		state := PackageInstalled
+		if status[0] == 'h' {
+			state = PackageHeld
+		}
+
+		// Multi-arch packages are listed as "name:arch".
+		name := fields[1]
+		if i := strings.Index(name, ":"); i > 0 {
+			name = name[:i]
+		}
+		res = append(res, PackageInfo{
+			Name:         name,
+			Version:      fields[2],
+			Architecture: fields[3],
+			State:        state,
+		})
+	}
+	return res
+}
+
+// parseAptPackageNames parses the output of "apt-cache pkgnames", which
+// lists a single package name per line.
+func parseAptPackageNames(output string) []PackageInfo {
+	var res []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(line)
+		if name == "" || strings.ContainsAny(name, " \t") {
+			continue
+		}
+		res = append(res, PackageInfo{
+			Name:  name,
+			State: PackageAvailable,
+		})
+	}
+	return res
+}
+
+// parseDpkgStatusVersion parses the version of a package out of the output
+// of "dpkg-query --status", provided that the package is fully installed
+// rather than, for example, only having its configuration files left.
+func parseDpkgStatusVersion(output string) string {
+	if !strings.HasSuffix(infoField(output, "Status"), " installed") {
+		return ""
+	}
+	return infoField(output, "Version")
+}
+
+// parseAptPolicyCandidate parses the candidate version of a package out of
+// the output of "apt-cache policy", which looks like:
+//
+//	bash:
+//	  Installed: 5.0-6ubuntu1.1
+//	  Candidate: 5.0-6ubuntu1.2
+//	  Version table:
+func parseAptPolicyCandidate(output string) string {
+	candidate := infoField(output, "Candidate")
+	if candidate == "(none)" {
+		return ""
+	}
+	return candidate
+}
+
 func (*apt) IsRetryable(code int, output string) bool {
 	return code == APTExitCode
 }
@@ -0,0 +1,163 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
+	"github.com/juju/proxy"
+)
+
+const (
+	// DnfExitCode is the exit code with which dnf reports all failures;
+	// whether they are retryable is decided from the command output.
+	DnfExitCode int = 1
+)
+
+// dnf is the PackageManager implementation for dnf-based systems.
+type dnf struct {
+	basePackageManager
+}
+
+// NewDnfPackageManager returns a PackageManager for dnf-based systems.
+func NewDnfPackageManager() PackageManager {
+	return NewDnfPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewDnfPackageManagerWithExecutor returns a PackageManager for dnf-based
+// systems, which runs its commands with the given Executor.
+func NewDnfPackageManagerWithExecutor(executor Executor) PackageManager {
+	manager := &dnf{
+		basePackageManager: basePackageManager{
+			executor:       executor,
+			cmder:          commands.NewDnfPackageCommander(),
+			commander:      commands.NewDnfCommander(),
+			proxyConfig:    "dnf",
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parseYumList,
+			parseAvailable: parseYumList,
+			parseVersion:   parseRPMQueryVersion,
+			parseCandidate: parseRPMQueryVersion,
+			specFields:     specFields{version: true, architecture: true},
+			versionScheme:  version.RPMScheme,
+		},
+	}
+	manager.basePackageManager.retryable = manager
+	return manager
+}
+
+// Search is defined on the PackageManager interface.
+func (dnf *dnf) Search(pack string) (bool, error) {
+	return dnf.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (dnf *dnf) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := dnf.validatePackages(pack); err != nil {
+		return false, err
+	}
+	out, _, err := dnf.runCommand(ctx, dnf.commander.Search(pack), dnf, dnf.env)
+
+	// dnf list package fails when it cannot find the package.
+	if strings.Contains(combinedOutput(out, err), "No matching Packages") {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Install is defined on the PackageManager interface.
+func (dnf *dnf) Install(packs ...string) error {
+	return dnf.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (dnf *dnf) InstallContext(ctx context.Context, packs ...string) error {
+	if err := dnf.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := dnf.runCommand(ctx, dnf.commander.Install(packs...), dnf, dnf.env)
+	return err
+}
+
+// InstallSpec is defined on the PackageManager interface.
+func (dnf *dnf) InstallSpec(specs ...packaging.PackageSpec) error {
+	return dnf.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (dnf *dnf) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := dnf.specFields.validate(dnf.commander, specs); err != nil {
+		return err
+	}
+	_, _, err := dnf.runCommand(ctx, dnf.commander.InstallSpec(specs...), dnf, dnf.env)
+	return err
+}
+
+// GetProxySettings is defined on the PackageManager interface.
+func (dnf *dnf) GetProxySettings() (proxy.Settings, error) {
+	return dnf.GetProxySettingsContext(context.Background())
+}
+
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+//
+// As dnf uses a single proxy for all protocols, it is reported as the proxy
+// for each of them.
+func (dnf *dnf) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	var res proxy.Settings
+
+	out, code, err := dnf.queryProxy(ctx, dnf.commander.GetProxy())
+	// grep exits with 1 when no proxy is configured.
+	if code == 1 && out == "" {
+		return res, nil
+	}
+	if err != nil {
+		return res, err
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) != "proxy" {
+			continue
+		}
+		value := strings.TrimSpace(fields[1])
+		res.Http, res.Https, res.Ftp = value, value, value
+	}
+
+	return res, nil
+}
+
+// dnfRetryable defines a retryable for all dnf commands; as dnf reports all
+// failures with the same exit code, the output is checked for the transient
+// failures which are worth retrying.
+var dnfRetryable = makeRegexpRetryable([]int{DnfExitCode},
+	`(?i)failed to download metadata`,
+	`(?i)cannot download repomd\.xml`,
+	`(?i)errors? during downloading metadata`,
+	`(?i)error downloading packages`,
+	`(?i)curl error`,
+	`(?i)cannot prepare internal mirrorlist`,
+	`(?i)waiting for process with pid`,
+)
+
+func (*dnf) IsRetryable(code int, output string) bool {
+	return dnfRetryable.IsRetryable(code, output)
+}
+
+// MaskError will mask an error using the cmd exit code and the stdout/stderr
+// output.
+func (*dnf) MaskError(code int, output string) error {
+	// dnf reports packages which cannot be found with
+	// "No match for argument: <name>".
+	if strings.Contains(output, "No match for argument") {
+		return errors.New("unable to locate package")
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/proxy"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
+)
+
+var _ = gc.Suite(&DnfSuite{})
+
+type DnfSuite struct {
+	testing.IsolationSuite
+	paccmder  commands.PackageCommander
+	commander commands.Commander
+	exec      *managertesting.FakeExecutor
+	pacman    manager.PackageManager
+}
+
+func (s *DnfSuite) SetUpSuite(c *gc.C) {
+	s.IsolationSuite.SetUpSuite(c)
+	s.paccmder = commands.NewDnfPackageCommander()
+	s.commander = commands.NewDnfCommander()
+}
+
+func (s *DnfSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.exec = managertesting.NewFakeExecutor()
+	s.pacman = manager.NewDnfPackageManagerWithExecutor(s.exec)
+}
+
+func (s *DnfSuite) TearDownTest(c *gc.C) {
+	s.IsolationSuite.TearDownTest(c)
+}
+
+func (s *DnfSuite) TearDownSuite(c *gc.C) {
+	s.IsolationSuite.TearDownSuite(c)
+}
+
+func (s *DnfSuite) TestNewPackageManagerSelectsDnf(c *gc.C) {
+	for _, series := range []string{"centos8", "centos9", "rocky8", "rocky9", "almalinux8", "almalinux9"} {
+		pacman, err := manager.NewPackageManager(series)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(pacman, gc.FitsTypeOf, s.pacman)
+
+		paccmder, err := commands.NewPackageCommander(series)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(paccmder, gc.Equals, s.paccmder)
+	}
+}
+
+func (s *DnfSuite) TestSearch(c *gc.C) {
+	const output = `Available Packages
+bash.x86_64                   5.1.8-6.el9_1                   baseos
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	found, err := s.pacman.Search("bash")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.Search("bash").Argv())
+}
+
+func (s *DnfSuite) TestSearchNotFound(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "Error: No matching Packages to list\n", Code: manager.DnfExitCode})
+
+	found, err := s.pacman.Search("foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsFalse)
+}
+
+func (s *DnfSuite) TestInstallPackageNotFound(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "No match for argument: foo\nError: Unable to find a match: foo\n", Code: manager.DnfExitCode})
+
+	err := s.pacman.Install("foo")
+	c.Assert(err, gc.ErrorMatches, `packaging command failed: encountered fatal error: unable to locate package`)
+	c.Assert(s.exec.Commands(), gc.HasLen, 1)
+}
+
+func (s *DnfSuite) TestInstallRetriesMetadataFailures(c *gc.C) {
+	const minRetries = 3
+	s.PatchValue(&manager.Attempts, minRetries)
+	s.PatchValue(&manager.Delay, testing.ShortWait)
+	s.exec.Push(managertesting.Response{Stdout: "Error: Failed to download metadata for repo 'appstream': Cannot download repomd.xml\n", Code: manager.DnfExitCode})
+
+	pacman := manager.NewDnfPackageManagerWithExecutor(s.exec)
+	err := pacman.Install("bash")
+	c.Assert(err, gc.ErrorMatches, `packaging command failed: attempt count exceeded: .*`)
+	c.Assert(s.exec.Commands(), gc.HasLen, minRetries)
+}
+
+func (s *DnfSuite) TestUpdateDoesNotRetryOtherFailures(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "Error: This command has to be run with superuser privileges\n", Code: manager.DnfExitCode})
+
+	err := s.pacman.Update()
+	c.Assert(err, gc.ErrorMatches, `packaging command failed: exit status 1`)
+	c.Assert(s.exec.Commands(), gc.HasLen, 1)
+}
+
+func (s *DnfSuite) TestCandidateVersion(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "0:5.1.8-6.el9_1\n"})
+
+	version, err := s.pacman.CandidateVersion("bash")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "5.1.8-6.el9_1")
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.CandidateVersion("bash").Argv())
+}
+
+func (s *DnfSuite) TestCandidateVersionNotFound(c *gc.C) {
+	s.exec.Push(managertesting.Response{})
+
+	_, err := s.pacman.CandidateVersion("foo")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *DnfSuite) TestGetProxySettingsEmpty(c *gc.C) {
+	s.exec.Push(managertesting.Response{Code: 1})
+
+	out, err := s.pacman.GetProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(out, gc.Equals, proxy.Settings{})
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.GetProxy().Argv())
+}
+
+func (s *DnfSuite) TestProxySettingsRoundTrip(c *gc.C) {
+	initial := proxy.Settings{
+		Http:  "some-proxy.local:8080",
+		Https: "some-proxy.local:8080",
+		Ftp:   "some-proxy.local:8080",
+	}
+
+	expected := s.paccmder.ProxyConfigContents(initial)
+	c.Assert(expected, gc.Equals, "proxy=some-proxy.local:8080")
+	s.exec.Push(managertesting.Response{Stdout: expected})
+
+	result, err := s.pacman.GetProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, gc.Equals, initial)
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3/proxyconfig"
+)
+
+// setEnvProxy makes the package manager pass the given proxy settings to
+// all the commands it runs through their environment. It is used by the
+// package managers, such as apk, which have no proxy configuration of their
+// own but honour the proxy environment variables.
+func (pm *basePackageManager) setEnvProxy(settings proxy.Settings) {
+	// All the variables are set, even if empty, so that the proxy
+	// settings of the current process do not leak through.
+	var env []string
+	for _, setting := range []struct{ key, value string }{
+		{"http_proxy", settings.Http},
+		{"https_proxy", settings.Https},
+		{"ftp_proxy", settings.Ftp},
+		{"no_proxy", settings.FullNoProxy()},
+	} {
+		env = append(env,
+			setting.key+"="+setting.value,
+			strings.ToUpper(setting.key)+"="+setting.value,
+		)
+	}
+	pm.env = env
+}
+
+// setProfileProxy persists the given proxy settings in the profile script
+// managed by the proxyconfig Writer with the given name, replacing the
+// previous ones, and passes them to all subsequent commands through their
+// environment, see setEnvProxy.
+func (pm *basePackageManager) setProfileProxy(ctx context.Context, name string, settings proxy.Settings) error {
+	w, err := proxyconfig.NewWithFS(newExecutorFS(ctx, pm.executor), name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := w.Set(settings); err != nil {
+		return errors.Annotatef(err, "writing %s proxy settings", name)
+	}
+
+	pm.setEnvProxy(settings)
+	return nil
+}
+
+// getProfileProxy returns the proxy settings persisted in the profile
+// script at the given path by setProfileProxy, which are empty if there
+// are none. The proxy settings of the current process are not those of
+// the system, and are never returned.
+func (pm *basePackageManager) getProfileProxy(ctx context.Context, name, p string) (proxy.Settings, error) {
+	out, err := readFile(ctx, pm.executor, p)
+	if err != nil {
+		return proxy.Settings{}, errors.Annotatef(err, "reading %s proxy settings", name)
+	}
+	return parseProxyProfile(out), nil
+}
+
+// parseProxyProfile parses the proxy settings out of the contents of a
+// profile script written by setProfileProxy, such as
+// commands.ApkProxyFilePath, which looks like:
+//
+//	export http_proxy=http://proxy.local:3128
+//	export no_proxy='localhost,.example.com'
+func parseProxyProfile(contents string) proxy.Settings {
+	var settings proxy.Settings
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = shellUnquote(value)
+		switch strings.ToLower(key) {
+		case "http_proxy":
+			settings.Http = value
+		case "https_proxy":
+			settings.Https = value
+		case "ftp_proxy":
+			settings.Ftp = value
+		case "no_proxy":
+			settings.NoProxy = value
+		}
+	}
+	return settings
+}
+
+// shellUnquote removes the quoting of the given shell word, as written by
+// commands.ShellQuote, or with double quotes or backslashes.
+func shellUnquote(word string) string {
+	var res strings.Builder
+	var quote rune
+	escaped := false
+	for _, r := range word {
+		switch {
+		case escaped:
+			res.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				res.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				res.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		default:
+			res.WriteRune(r)
+		}
+	}
+	return res.String()
+}
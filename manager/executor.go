@@ -0,0 +1,138 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// Command is a command run by an Executor.
+type Command struct {
+	// Args are the program to run and its arguments. The program is run
+	// directly, without a shell.
+	Args []string
+
+	// Env are environment variables, as "key=value" pairs, which are set
+	// for the command in addition to the environment of the Executor.
+	Env []string
+
+	// Stdin is the standard input of the command, which is empty if nil.
+	Stdin []byte
+
+	// Dir is the working directory of the command, which is the working
+	// directory of the Executor if empty.
+	Dir string
+}
+
+// String returns the arguments of the command separated by spaces, for
+// logging.
+func (c Command) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// Result is the outcome of a Command which ran.
+type Result struct {
+	// Stdout and Stderr are the standard output and standard error of
+	// the command.
+	Stdout []byte
+	Stderr []byte
+
+	// Code is the exit code of the command.
+	Code int
+}
+
+// Output returns the standard output of the command followed by its
+// standard error.
+func (r Result) Output() string {
+	return string(r.Stdout) + string(r.Stderr)
+}
+
+// Executor runs the commands of a PackageManager. Every PackageManager is
+// constructed with an Executor, which lets the commands be run somewhere
+// other than on the local system, or not at all when testing; see the
+// FakeExecutor of the manager/testing package.
+type Executor interface {
+	// Execute runs the given command and returns its result once it
+	// exits. A non-zero exit code is reported in the result and is not an
+	// error; an error is returned if the command could not be run, or
+	// did not complete, at all. The command is killed if the context is
+	// done before it completes.
+	Execute(ctx context.Context, cmd Command) (Result, error)
+}
+
+// NewExecExecutor returns an Executor which runs the commands as processes
+// on the local system, with the environment of the current process. The
+// commands are run with CommandOutput, so their standard error is reported
+// along with their standard output in Result.Stdout.
+func NewExecExecutor() Executor {
+	return execExecutor{}
+}
+
+// execExecutor is the Executor running commands with os/exec.
+type execExecutor struct{}
+
+// Execute is defined on the Executor interface.
+func (execExecutor) Execute(ctx context.Context, cmd Command) (Result, error) {
+	if len(cmd.Args) == 0 {
+		return Result{}, errors.NotValidf("empty command")
+	}
+	// A new exec.Cmd is created for every execution, as it can only be
+	// run once. See http://pad.lv/1394524.
+	command := exec.CommandContext(ctx, cmd.Args[0], cmd.Args[1:]...)
+	command.Env = append(os.Environ(), cmd.Env...)
+	command.Dir = cmd.Dir
+	if cmd.Stdin != nil {
+		command.Stdin = bytes.NewReader(cmd.Stdin)
+	}
+
+	out, err := CommandOutput(command)
+	res := Result{Stdout: out}
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return res, ctxErr
+	}
+	exitErr, ok := errors.Cause(err).(*exec.ExitError)
+	if !ok {
+		return res, errors.Trace(err)
+	}
+	status, ok := ProcessStateSys(exitErr.ProcessState).(exitStatuser)
+	if !ok {
+		return res, errors.Errorf("unexpected process state type %T", exitErr.ProcessState.Sys())
+	}
+	if res.Code = status.ExitStatus(); res.Code < 0 {
+		// The command was killed by a signal.
+		return Result{Stdout: out}, errors.Trace(err)
+	}
+	return res, nil
+}
+
+// exitError is the error of a command which exited with a non-zero code.
+type exitError struct {
+	code int
+}
+
+// Error is defined on the error interface.
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// execute runs the given command once with the given executor and returns
+// its output, as returned by Result.Output. A command exiting with a
+// non-zero code results in an error.
+func execute(ctx context.Context, executor Executor, cmd Command) (string, int, error) {
+	res, err := executor.Execute(ctx, cmd)
+	if err != nil {
+		return res.Output(), -1, err
+	}
+	if res.Code != 0 {
+		return res.Output(), res.Code, &exitError{code: res.Code}
+	}
+	return res.Output(), 0, nil
+}
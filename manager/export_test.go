@@ -0,0 +1,26 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+
+	"github.com/juju/packaging/v3/proxyconfig"
+)
+
+// SetApkRepositoriesPath sets the path of the repositories file which is
+// edited by the given apk package manager.
+func SetApkRepositoriesPath(apk *Apk, path string) {
+	apk.repositoriesPath = path
+}
+
+// RunWithRetry runs the given command with the given executor, retrying it
+// according to the given retryable and policy.
+var RunWithRetry = runWithRetry
+
+// NewExecutorFS returns the proxyconfig.FS of the system the given executor
+// runs commands on.
+func NewExecutorFS(ctx context.Context, executor Executor) proxyconfig.FS {
+	return newExecutorFS(ctx, executor)
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3/proxyconfig"
+)
+
+// executorFS is the proxyconfig.FS of the system an Executor runs commands
+// on, whose files are read and written with commands, so that they are
+// those the package manager sees wherever it runs.
+type executorFS struct {
+	ctx      context.Context
+	executor Executor
+}
+
+var _ proxyconfig.FS = executorFS{}
+
+// newExecutorFS returns the proxyconfig.FS of the system the given
+// executor runs commands on, which runs them with the given context.
+func newExecutorFS(ctx context.Context, executor Executor) executorFS {
+	return executorFS{ctx: ctx, executor: executor}
+}
+
+// ReadFile is defined on the proxyconfig.FS interface.
+func (fs executorFS) ReadFile(p string) ([]byte, os.FileMode, error) {
+	// The permissions precede the contents, so that any output at all
+	// tells an existing file from a missing one.
+	out, err := fs.run(Command{
+		Args: []string{"sh", "-c", `[ -e "$1" ] || exit 0; stat -c %a "$1" && cat "$1"`, "sh", p},
+	})
+	if err != nil {
+		return nil, 0, errors.Annotatef(err, "reading %s", p)
+	}
+	if out == "" {
+		return nil, 0, errors.NotFoundf("%s", p)
+	}
+	mode, contents, _ := strings.Cut(out, "\n")
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, 0, errors.Errorf("reading %s: unexpected permissions %q", p, mode)
+	}
+	return []byte(contents), os.FileMode(perm).Perm(), nil
+}
+
+// WriteFile is defined on the proxyconfig.FS interface. The contents are
+// written to a temporary file next to the path, which only its owner can
+// read until it is given its permissions and renamed to the path.
+func (fs executorFS) WriteFile(p string, data []byte, perm os.FileMode) error {
+	const script = `mkdir -p "${1%/*}" && umask 077 && tmp=$(mktemp "${1%/*}/.${1##*/}.XXXXXX") || exit
+cat > "$tmp" && chmod "$2" "$tmp" && mv -f "$tmp" "$1" || { rm -f "$tmp"; exit 1; }`
+	_, err := fs.run(Command{
+		Args:  []string{"sh", "-c", script, "sh", p, fmt.Sprintf("%o", perm.Perm())},
+		Stdin: data,
+	})
+	return errors.Annotatef(err, "writing %s", p)
+}
+
+// Remove is defined on the proxyconfig.FS interface.
+func (fs executorFS) Remove(p string) error {
+	_, err := fs.run(Command{Args: []string{"rm", "-f", "--", p}})
+	return errors.Annotatef(err, "removing %s", p)
+}
+
+// run runs the given command once with the executor and returns its
+// output.
+func (fs executorFS) run(cmd Command) (string, error) {
+	out, _, err := execute(fs.ctx, fs.executor, cmd)
+	if err != nil {
+		logger.Errorf("command failed: %v\nargs: %s\n%s", err, Redact(fmt.Sprintf("%#v", cmd.Args)), Redact(out))
+		return "", errors.Trace(err)
+	}
+	return out, nil
+}
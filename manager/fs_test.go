@@ -0,0 +1,85 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
+)
+
+var _ = gc.Suite(&ExecutorFSSuite{})
+
+type ExecutorFSSuite struct {
+	testing.IsolationSuite
+}
+
+func (s *ExecutorFSSuite) TestRoundTrip(c *gc.C) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		c.Skip("/bin/sh not available")
+	}
+	// The isolation suite clears $PATH, which the commands are looked
+	// up in.
+	s.PatchEnvironment("PATH", "/usr/bin:/bin")
+	dir := c.MkDir()
+	p := filepath.Join(dir, "etc", "apt", "auth.conf.d", "juju-proxy.conf")
+	fs := manager.NewExecutorFS(context.Background(), manager.NewExecExecutor())
+
+	_, _, err := fs.ReadFile(p)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	c.Assert(fs.WriteFile(p, []byte("machine proxy.local\n"), 0600), jc.ErrorIsNil)
+	data, perm, err := fs.ReadFile(p)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "machine proxy.local\n")
+	c.Check(perm, gc.Equals, os.FileMode(0600))
+
+	c.Assert(fs.WriteFile(p, []byte("replaced"), 0644), jc.ErrorIsNil)
+	data, perm, err = fs.ReadFile(p)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "replaced")
+	c.Check(perm, gc.Equals, os.FileMode(0644))
+
+	// The file was replaced without leaving temporary files behind.
+	entries, err := os.ReadDir(filepath.Dir(p))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(entries, gc.HasLen, 1)
+
+	c.Assert(fs.Remove(p), jc.ErrorIsNil)
+	_, _, err = fs.ReadFile(p)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(fs.Remove(p), jc.ErrorIsNil)
+}
+
+func (s *ExecutorFSSuite) TestWriteFilePassesContentsOnStdin(c *gc.C) {
+	executor := managertesting.NewFakeExecutor()
+	fs := manager.NewExecutorFS(context.Background(), executor)
+
+	err := fs.WriteFile("/etc/yum.conf", []byte("proxy_password=secret\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	cmds := executor.Commands()
+	c.Assert(cmds, gc.HasLen, 1)
+	c.Check(cmds[0].Args[len(cmds[0].Args)-2:], gc.DeepEquals, []string{"/etc/yum.conf", "600"})
+	c.Check(string(cmds[0].Stdin), gc.Equals, "proxy_password=secret\n")
+	for _, arg := range cmds[0].Args {
+		c.Check(arg, gc.Not(jc.Contains), "secret")
+	}
+}
+
+func (s *ExecutorFSSuite) TestReadFileFailure(c *gc.C) {
+	executor := managertesting.NewFakeExecutor(managertesting.Response{Stderr: "permission denied", Code: 1})
+	fs := manager.NewExecutorFS(context.Background(), executor)
+
+	_, _, err := fs.ReadFile("/etc/yum.conf")
+	c.Assert(err, gc.ErrorMatches, "reading /etc/yum.conf: exit status 1")
+}
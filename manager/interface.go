@@ -8,7 +8,12 @@
 package manager
 
 import (
+	"context"
+
 	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/version"
 )
 
 // PackageManager is the interface which carries out various
@@ -33,6 +38,12 @@ type PackageManager interface {
 	// Install runs a *single* command that installs the given package(s).
 	Install(packs ...string) error
 
+	// InstallSpec runs a *single* command that installs the given
+	// package(s), constrained to the version, architecture, channel and
+	// target release of each spec. A NotSupported error is returned if a
+	// spec uses a field which the package manager cannot express.
+	InstallSpec(specs ...packaging.PackageSpec) error
+
 	// Remove runs a *single* command that removes the given package(s).
 	Remove(packs ...string) error
 
@@ -48,6 +59,26 @@ type PackageManager interface {
 	// given package is currently installed on the system.
 	IsInstalled(pack string) bool
 
+	// PackageVersion returns the version of the given package which is
+	// currently installed on the system. A NotFound error is returned if
+	// the package is not installed.
+	PackageVersion(pack string) (string, error)
+
+	// CandidateVersion returns the version of the given package which would
+	// be installed from the currently configured repositories. A NotFound
+	// error is returned if no version of the package is available.
+	CandidateVersion(pack string) (string, error)
+
+	// VersionScheme returns the scheme with which the versions of the
+	// packages handled by the package manager are compared.
+	VersionScheme() version.Scheme
+
+	// IsInstalledAtLeast returns whether the given package is installed
+	// with a version which is the same as or newer than the given one,
+	// compared according to VersionScheme. For snaps, the version is a
+	// revision.
+	IsInstalledAtLeast(pack, minVersion string) (bool, error)
+
 	// AddRepository runs the command that adds a repository to the
 	// list of available repositories.
 	// NOTE: requires the prerequisite package whose installation command
@@ -60,29 +91,135 @@ type PackageManager interface {
 	// is done by running InstallPrerequisite().
 	RemoveRepository(repo string) error
 
+	// ListInstalled runs the command which lists all the packages
+	// currently installed on the system and returns them.
+	ListInstalled() ([]PackageInfo, error)
+
+	// ListAvailable runs the command which lists all the packages
+	// available from the currently configured repositories and returns
+	// them.
+	// NOTE: includes already installed packages.
+	ListAvailable() ([]PackageInfo, error)
+
 	// Cleanup runs the command that cleans up all orphaned packages,
 	// left-over files and previously-cached packages.
 	Cleanup() error
 
 	// GetProxySettings returns the curretly-configured package manager proxy.
+	// Its NoProxy setting lists the hosts which bypass the proxy, in the
+	// syntax of the package manager; settings written by SetProxy are read
+	// back as they were given, except for hosts the package manager cannot
+	// express or spells differently, such as "*.example.com" for zypper.
+	// yum and dnf have no setting for them, and do not write NoProxy.
 	GetProxySettings() (proxy.Settings, error)
 
-	// SetProxy runs the commands to set the given proxy parameters for the
-	// package management system.
+	// SetProxy sets the given proxy parameters for the package management
+	// system, replacing those it set before; settings without any proxies
+	// remove them. Configuration files are written by the proxyconfig
+	// package, which keeps the credentials of authenticated proxies in
+	// files only their owner can read.
 	SetProxy(settings proxy.Settings) error
 }
 
+// PackageManagerContext is the context-aware companion of PackageManager.
+// Every operation stops retrying, and kills any command which is still
+// running, once the given context is done.
+//
+// All the PackageManager implementations provided by this package also
+// implement PackageManagerContext.
+type PackageManagerContext interface {
+	// InstallPrerequisiteContext is the context-aware variant of
+	// PackageManager.InstallPrerequisite.
+	InstallPrerequisiteContext(ctx context.Context) error
+
+	// UpdateContext is the context-aware variant of PackageManager.Update.
+	UpdateContext(ctx context.Context) error
+
+	// UpgradeContext is the context-aware variant of PackageManager.Upgrade.
+	UpgradeContext(ctx context.Context) error
+
+	// InstallContext is the context-aware variant of PackageManager.Install.
+	InstallContext(ctx context.Context, packs ...string) error
+
+	// InstallSpecContext is the context-aware variant of
+	// PackageManager.InstallSpec.
+	InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error
+
+	// RemoveContext is the context-aware variant of PackageManager.Remove.
+	RemoveContext(ctx context.Context, packs ...string) error
+
+	// PurgeContext is the context-aware variant of PackageManager.Purge.
+	PurgeContext(ctx context.Context, packs ...string) error
+
+	// SearchContext is the context-aware variant of PackageManager.Search.
+	SearchContext(ctx context.Context, pack string) (bool, error)
+
+	// IsInstalledContext is the context-aware variant of
+	// PackageManager.IsInstalled.
+	IsInstalledContext(ctx context.Context, pack string) bool
+
+	// PackageVersionContext is the context-aware variant of
+	// PackageManager.PackageVersion.
+	PackageVersionContext(ctx context.Context, pack string) (string, error)
+
+	// CandidateVersionContext is the context-aware variant of
+	// PackageManager.CandidateVersion.
+	CandidateVersionContext(ctx context.Context, pack string) (string, error)
+
+	// IsInstalledAtLeastContext is the context-aware variant of
+	// PackageManager.IsInstalledAtLeast.
+	IsInstalledAtLeastContext(ctx context.Context, pack, minVersion string) (bool, error)
+
+	// AddRepositoryContext is the context-aware variant of
+	// PackageManager.AddRepository.
+	AddRepositoryContext(ctx context.Context, repo string) error
+
+	// RemoveRepositoryContext is the context-aware variant of
+	// PackageManager.RemoveRepository.
+	RemoveRepositoryContext(ctx context.Context, repo string) error
+
+	// ListInstalledContext is the context-aware variant of
+	// PackageManager.ListInstalled.
+	ListInstalledContext(ctx context.Context) ([]PackageInfo, error)
+
+	// ListAvailableContext is the context-aware variant of
+	// PackageManager.ListAvailable.
+	ListAvailableContext(ctx context.Context) ([]PackageInfo, error)
+
+	// CleanupContext is the context-aware variant of PackageManager.Cleanup.
+	CleanupContext(ctx context.Context) error
+
+	// GetProxySettingsContext is the context-aware variant of
+	// PackageManager.GetProxySettings.
+	GetProxySettingsContext(ctx context.Context) (proxy.Settings, error)
+
+	// SetProxyContext is the context-aware variant of
+	// PackageManager.SetProxy.
+	SetProxyContext(ctx context.Context, settings proxy.Settings) error
+}
+
 // NewPackageManager returns the appropriate PackageManager implementation
 // based on the provided series.
+// Unknown series fall back to apt; use distro.Detect to select the
+// PackageManager from the os-release file of the system instead.
 func NewPackageManager(series string) (PackageManager, error) {
+	return NewPackageManagerWithExecutor(series, NewExecExecutor())
+}
+
+// NewPackageManagerWithExecutor is the variant of NewPackageManager
+// returning a PackageManager which runs its commands with the given
+// Executor.
+func NewPackageManagerWithExecutor(series string, executor Executor) (PackageManager, error) {
 	// TODO (aznashwan): find a more deterministic way of filtering out
 	// release series without importing version from core.
 	switch series {
 	case "centos7":
-		return NewYumPackageManager(), nil
+		return NewYumPackageManagerWithExecutor(executor), nil
+	case "centos8", "centos9", "rocky8", "rocky9", "almalinux8", "almalinux9":
+		return NewDnfPackageManagerWithExecutor(executor), nil
 	case "opensuseleap":
-		return NewZypperPackageManager(), nil
+		return NewZypperPackageManagerWithExecutor(executor), nil
 	default:
-		return NewAptPackageManager(), nil
+		return NewAptPackageManagerWithExecutor(executor), nil
 	}
 }
@@ -5,91 +5,306 @@
 package manager
 
 import (
+	"context"
 	"fmt"
-	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/proxy"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/proxyconfig"
+	"github.com/juju/packaging/v3/version"
 )
 
 // basePackageManager is the struct which executes various
 // packaging-related operations.
 type basePackageManager struct {
-	cmder       commands.PackageCommander
-	retryable   Retryable
-	retryPolicy RetryPolicy
+	executor           Executor
+	cmder              commands.PackageCommander
+	commander          commands.Commander
+	proxyConfig        string // the proxyconfig Writer of the proxy configuration file, if any
+	retryable          Retryable
+	retryPolicy        RetryPolicy
+	parseInstalled     packageListParser // parses the output of ListInstalledCmd
+	parseAvailable     packageListParser // parses the output of ListAvailableCmd
+	parseVersion       versionParser     // parses the output of PackageVersionCmd
+	parseCandidate     versionParser     // parses the output of CandidateVersionCmd
+	specFields         specFields        // the PackageSpec fields InstallSpecCmd can express
+	versionScheme      version.Scheme    // the scheme package versions are compared with
+	parseSchemeVersion versionParser     // parses the output of PackageVersionCmd into a version of versionScheme; defaults to parseVersion
+	env                []string          // additional environment variables for the commands which are run
 }
 
 // InstallPrerequisite is defined on the PackageManager interface.
 func (pm *basePackageManager) InstallPrerequisite() error {
-	_, _, err := RunCommandWithRetry(pm.cmder.InstallPrerequisiteCmd(), pm, pm.retryPolicy, nil)
+	return pm.InstallPrerequisiteContext(context.Background())
+}
+
+// InstallPrerequisiteContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) InstallPrerequisiteContext(ctx context.Context) error {
+	_, _, err := pm.runCommand(ctx, pm.commander.InstallPrerequisite(), pm, pm.env)
 	return err
 }
 
 // Update is defined on the PackageManager interface.
 func (pm *basePackageManager) Update() error {
-	_, _, err := RunCommandWithRetry(pm.cmder.UpdateCmd(), pm, pm.retryPolicy, nil)
+	return pm.UpdateContext(context.Background())
+}
+
+// UpdateContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) UpdateContext(ctx context.Context) error {
+	_, _, err := pm.runCommand(ctx, pm.commander.Update(), pm, pm.env)
 	return err
 }
 
 // Upgrade is defined on the PackageManager interface.
 func (pm *basePackageManager) Upgrade() error {
-	_, _, err := RunCommandWithRetry(pm.cmder.UpgradeCmd(), pm, pm.retryPolicy, nil)
+	return pm.UpgradeContext(context.Background())
+}
+
+// UpgradeContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) UpgradeContext(ctx context.Context) error {
+	_, _, err := pm.runCommand(ctx, pm.commander.Upgrade(), pm, pm.env)
 	return err
 }
 
 // Install is defined on the PackageManager interface.
 func (pm *basePackageManager) Install(packs ...string) error {
-	_, _, err := RunCommandWithRetry(pm.cmder.InstallCmd(packs...), pm, pm.retryPolicy, nil)
+	return pm.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) InstallContext(ctx context.Context, packs ...string) error {
+	if err := pm.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.Install(packs...), pm, pm.env)
+	return err
+}
+
+// InstallSpec is defined on the PackageManager interface.
+func (pm *basePackageManager) InstallSpec(specs ...packaging.PackageSpec) error {
+	return pm.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := pm.specFields.validate(pm.commander, specs); err != nil {
+		return err
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.InstallSpec(specs...), pm, pm.env)
 	return err
 }
 
 // Remove is defined on the PackageManager interface.
 func (pm *basePackageManager) Remove(packs ...string) error {
-	_, _, err := RunCommandWithRetry(pm.cmder.RemoveCmd(packs...), pm, pm.retryPolicy, nil)
+	return pm.RemoveContext(context.Background(), packs...)
+}
+
+// RemoveContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) RemoveContext(ctx context.Context, packs ...string) error {
+	if err := pm.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.Remove(packs...), pm, pm.env)
 	return err
 }
 
 // Purge is defined on the PackageManager interface.
 func (pm *basePackageManager) Purge(packs ...string) error {
-	_, _, err := RunCommandWithRetry(pm.cmder.PurgeCmd(packs...), pm, pm.retryPolicy, nil)
+	return pm.PurgeContext(context.Background(), packs...)
+}
+
+// PurgeContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) PurgeContext(ctx context.Context, packs ...string) error {
+	if err := pm.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.Purge(packs...), pm, pm.env)
 	return err
 }
 
 // IsInstalled is defined on the PackageManager interface.
 func (pm *basePackageManager) IsInstalled(pack string) bool {
-	args := strings.Fields(pm.cmder.IsInstalledCmd(pack))
+	return pm.IsInstalledContext(context.Background(), pack)
+}
 
-	_, err := RunCommand(args[0], args[1:]...)
+// IsInstalledContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) IsInstalledContext(ctx context.Context, pack string) bool {
+	if pm.validatePackages(pack) != nil {
+		return false
+	}
+	_, _, err := pm.execute(ctx, pm.commander.IsInstalled(pack))
 	return err == nil
 }
 
+// PackageVersion is defined on the PackageManager interface.
+func (pm *basePackageManager) PackageVersion(pack string) (string, error) {
+	return pm.PackageVersionContext(context.Background(), pack)
+}
+
+// PackageVersionContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) PackageVersionContext(ctx context.Context, pack string) (string, error) {
+	if pm.parseVersion == nil {
+		return "", errors.NotSupportedf("querying installed package versions")
+	}
+	return pm.queryVersion(ctx, pm.commander.PackageVersion, pack, pm.parseVersion)
+}
+
+// CandidateVersion is defined on the PackageManager interface.
+func (pm *basePackageManager) CandidateVersion(pack string) (string, error) {
+	return pm.CandidateVersionContext(context.Background(), pack)
+}
+
+// CandidateVersionContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) CandidateVersionContext(ctx context.Context, pack string) (string, error) {
+	if pm.parseCandidate == nil {
+		return "", errors.NotSupportedf("querying candidate package versions")
+	}
+	return pm.queryVersion(ctx, pm.commander.CandidateVersion, pack, pm.parseCandidate)
+}
+
+// queryVersion runs the version query command of the given package and
+// parses its version out of its output.
+func (pm *basePackageManager) queryVersion(ctx context.Context, query func(string) commands.Command, pack string, parse versionParser) (string, error) {
+	if err := pm.validatePackages(pack); err != nil {
+		return "", err
+	}
+	out, code, err := pm.runCommand(ctx, query(pack), pm, pm.env)
+	if v := parse(out); v != "" {
+		return v, nil
+	}
+	// The query commands exit with a non-zero code when the package is
+	// unknown; any other failure means that they could not be run at all.
+	if err != nil && code <= 0 {
+		return "", err
+	}
+	return "", errors.NotFoundf("package %q", pack)
+}
+
+// VersionScheme is defined on the PackageManager interface.
+func (pm *basePackageManager) VersionScheme() version.Scheme {
+	return pm.versionScheme
+}
+
+// IsInstalledAtLeast is defined on the PackageManager interface.
+func (pm *basePackageManager) IsInstalledAtLeast(pack, minVersion string) (bool, error) {
+	return pm.IsInstalledAtLeastContext(context.Background(), pack, minVersion)
+}
+
+// IsInstalledAtLeastContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) IsInstalledAtLeastContext(ctx context.Context, pack, minVersion string) (bool, error) {
+	parse := pm.parseSchemeVersion
+	if parse == nil {
+		parse = pm.parseVersion
+	}
+	if pm.versionScheme == nil || parse == nil {
+		return false, errors.NotSupportedf("comparing installed package versions")
+	}
+
+	installed, err := pm.queryVersion(ctx, pm.commander.PackageVersion, pack, parse)
+	if errors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return version.AtLeast(pm.versionScheme, installed, minVersion)
+}
+
 // AddRepository is defined on the PackageManager interface.
 func (pm *basePackageManager) AddRepository(repo string) error {
-	_, _, err := RunCommandWithRetry(pm.cmder.AddRepositoryCmd(repo), pm, pm.retryPolicy, nil)
+	return pm.AddRepositoryContext(context.Background(), repo)
+}
+
+// AddRepositoryContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) AddRepositoryContext(ctx context.Context, repo string) error {
+	if err := pm.commander.ValidateAddRepository(repo); err != nil {
+		return errors.Trace(err)
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.AddRepository(repo), pm, pm.env)
 	return err
 }
 
 // RemoveRepository is defined on the PackageManager interface.
 func (pm *basePackageManager) RemoveRepository(repo string) error {
-	_, _, err := RunCommandWithRetry(pm.cmder.RemoveRepositoryCmd(repo), pm, pm.retryPolicy, nil)
+	return pm.RemoveRepositoryContext(context.Background(), repo)
+}
+
+// RemoveRepositoryContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) RemoveRepositoryContext(ctx context.Context, repo string) error {
+	if err := pm.commander.ValidateRemoveRepository(repo); err != nil {
+		return errors.Trace(err)
+	}
+	_, _, err := pm.runCommand(ctx, pm.commander.RemoveRepository(repo), pm, pm.env)
 	return err
 }
 
+// ListInstalled is defined on the PackageManager interface.
+func (pm *basePackageManager) ListInstalled() ([]PackageInfo, error) {
+	return pm.ListInstalledContext(context.Background())
+}
+
+// ListInstalledContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) ListInstalledContext(ctx context.Context) ([]PackageInfo, error) {
+	if pm.parseInstalled == nil {
+		return nil, errors.NotSupportedf("listing installed packages")
+	}
+	return pm.listPackages(ctx, pm.commander.ListInstalled(), pm.parseInstalled)
+}
+
+// ListAvailable is defined on the PackageManager interface.
+func (pm *basePackageManager) ListAvailable() ([]PackageInfo, error) {
+	return pm.ListAvailableContext(context.Background())
+}
+
+// ListAvailableContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) ListAvailableContext(ctx context.Context) ([]PackageInfo, error) {
+	if pm.parseAvailable == nil {
+		return nil, errors.NotSupportedf("listing available packages")
+	}
+	return pm.listPackages(ctx, pm.commander.ListAvailable(), pm.parseAvailable)
+}
+
+// listPackages runs the given package listing command and parses its
+// output with the given parser.
+func (pm *basePackageManager) listPackages(ctx context.Context, cmd commands.Command, parse packageListParser) ([]PackageInfo, error) {
+	out, _, err := pm.runCommand(ctx, cmd, pm, pm.env)
+	if err != nil {
+		return nil, err
+	}
+	return parse(out), nil
+}
+
 // Cleanup is defined on the PackageManager interface.
 func (pm *basePackageManager) Cleanup() error {
-	_, _, err := RunCommandWithRetry(pm.cmder.CleanupCmd(), pm, pm.retryPolicy, nil)
+	return pm.CleanupContext(context.Background())
+}
+
+// CleanupContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) CleanupContext(ctx context.Context) error {
+	_, _, err := pm.runCommand(ctx, pm.commander.Cleanup(), pm, pm.env)
 	return err
 }
 
 // SetProxy is defined on the PackageManager interface.
 func (pm *basePackageManager) SetProxy(settings proxy.Settings) error {
-	for _, cmd := range pm.cmder.SetProxyCmds(settings) {
-		args := []string{"bash", "-c", fmt.Sprintf("%q", cmd)}
-		out, err := RunCommand(args[0], args[1:]...)
+	return pm.SetProxyContext(context.Background(), settings)
+}
+
+// SetProxyContext is defined on the PackageManagerContext interface.
+func (pm *basePackageManager) SetProxyContext(ctx context.Context, settings proxy.Settings) error {
+	if pm.proxyConfig != "" {
+		w, err := proxyconfig.NewWithFS(newExecutorFS(ctx, pm.executor), pm.proxyConfig)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		return errors.Annotate(w.Set(settings), "setting proxy")
+	}
+	for _, cmd := range pm.commander.SetProxy(settings) {
+		out, _, err := pm.execute(ctx, cmd)
 		if err != nil {
-			logger.Errorf("command failed: %v\nargs: %#v\n%s", err, args, out)
+			logger.Errorf("command failed: %v\nargs: %s\n%s", err, Redact(fmt.Sprintf("%#v", cmd.Argv())), Redact(out))
 			return fmt.Errorf("command failed: %v", err)
 		}
 	}
@@ -97,6 +312,58 @@ func (pm *basePackageManager) SetProxy(settings proxy.Settings) error {
 	return nil
 }
 
+// validatePackages returns an error satisfying commands.IsValidationError
+// if any of the given packages is malformed for the package manager.
+func (pm *basePackageManager) validatePackages(packs ...string) error {
+	for _, pack := range packs {
+		if err := pm.commander.ValidatePackage(pack); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// runCommand runs the given command with the Executor of the package
+// manager and its retry policy. The environment variables are set for the
+// command in addition to those of the command itself and the Executor.
+// Commands which do nothing are not run.
+func (pm *basePackageManager) runCommand(ctx context.Context, cmd commands.Command, retryable Retryable, env []string) (output string, code int, _ error) {
+	if cmd.IsNop() {
+		return "", 0, nil
+	}
+	return runWithRetry(ctx, pm.executor, newCommand(cmd, env), retryable, pm.retryPolicy)
+}
+
+// execute runs the given command once with the Executor of the package
+// manager and returns its output along with its exit code. A command
+// exiting with a non-zero code results in an error.
+func (pm *basePackageManager) execute(ctx context.Context, cmd commands.Command) (string, int, error) {
+	return execute(ctx, pm.executor, newCommand(cmd, nil))
+}
+
+// queryProxy runs the given command, which reports the configured proxy
+// settings, once with the Executor of the package manager and returns its
+// output along with its exit code.
+func (pm *basePackageManager) queryProxy(ctx context.Context, cmd commands.Command) (string, int, error) {
+	out, code, err := pm.execute(ctx, cmd)
+	if err != nil {
+		logger.Errorf("command failed: %v\nargs: %s\n%s",
+			err, Redact(fmt.Sprintf("%#v", cmd.Argv())), Redact(out))
+		return out, code, fmt.Errorf("command failed: %v", err)
+	}
+	return out, code, nil
+}
+
+// newCommand returns the Command running the given command of a
+// Commander, with the given environment variables in addition to its own.
+func newCommand(cmd commands.Command, env []string) Command {
+	return Command{
+		Args:  cmd.Argv(),
+		Env:   append(append([]string(nil), cmd.Env...), env...),
+		Stdin: cmd.Stdin,
+	}
+}
+
 func (pm *basePackageManager) IsRetryable(code int, output string) bool {
 	if pm.retryable != nil {
 		return pm.retryable.IsRetryable(code, output)
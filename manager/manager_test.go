@@ -5,6 +5,8 @@
 package manager_test
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
@@ -13,8 +15,10 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
 )
 
 var _ = gc.Suite(&ManagerSuite{})
@@ -23,6 +27,11 @@ type ManagerSuite struct {
 	apt, snap, yum, zypper manager.PackageManager
 	testing.IsolationSuite
 	calledCommand string
+
+	// exec is the executor of the package managers below, which run
+	// no commands.
+	exec                                   *managertesting.FakeExecutor
+	fakeApt, fakeSnap, fakeYum, fakeZypper manager.PackageManager
 }
 
 func (s *ManagerSuite) SetUpSuite(c *gc.C) {
@@ -35,6 +44,11 @@ func (s *ManagerSuite) SetUpSuite(c *gc.C) {
 
 func (s *ManagerSuite) SetUpTest(c *gc.C) {
 	s.IsolationSuite.SetUpTest(c)
+	s.exec = managertesting.NewFakeExecutor()
+	s.fakeApt = manager.NewAptPackageManagerWithExecutor(s.exec)
+	s.fakeSnap = manager.NewSnapPackageManagerWithExecutor(s.exec)
+	s.fakeYum = manager.NewYumPackageManagerWithExecutor(s.exec)
+	s.fakeZypper = manager.NewZypperPackageManagerWithExecutor(s.exec)
 }
 
 func (s *ManagerSuite) TearDownTest(c *gc.C) {
@@ -79,23 +93,14 @@ var (
 	}
 )
 
-// getMockRunCommandWithRetry returns a function with the same signature as
-// RunCommandWithRetry which saves the command it receives in the provided
-// string whilst always returning no output, 0 error code and nil error.
-func getMockRunCommandWithRetry(stor *string) func(string, manager.Retryable, manager.RetryPolicy, []string) (string, int, error) {
-	return func(cmd string, _ manager.Retryable, _ manager.RetryPolicy, _ []string) (string, int, error) {
-		*stor = cmd
-		return "", 0, nil
-	}
-}
-
-// getMockRunCommand returns a function with the same signature as RunCommand
-// which saves the command it receives in the provided string whilst always
-// returning empty output and no error.
-func getMockRunCommand(stor *string) func(string, ...string) (string, error) {
-	return func(cmd string, args ...string) (string, error) {
-		*stor = strings.Join(append([]string{cmd}, args...), " ")
-		return "", nil
+// getMockCommandOutput returns a function with the same signature as
+// CommandOutput which saves the arguments of the command it receives,
+// separated by spaces, in the provided string whilst always returning no
+// output and nil error.
+func getMockCommandOutput(stor *string) func(*exec.Cmd) ([]byte, error) {
+	return func(cmd *exec.Cmd) ([]byte, error) {
+		*stor = strings.Join(cmd.Args, " ")
+		return nil, nil
 	}
 }
 
@@ -221,7 +226,9 @@ var simpleTestCases = []*simpleTestCase{
 	},
 	{
 		"Test repository addition.",
-		aptCmder.AddRepositoryCmd(testedRepoName),
+		// The repository is passed to add-apt-repository as a single
+		// argument, without the quotes of AddRepositoryCmd.
+		commands.NewAptCommander().AddRepository(testedRepoName).String(),
 		nil,
 		snapCmder.AddRepositoryCmd(testedRepoName),
 		nil,
@@ -298,8 +305,7 @@ var searchingTestCases = []*simpleTestCase{
 }
 
 func (s *ManagerSuite) TestSimpleCases(c *gc.C) {
-	s.PatchValue(&manager.RunCommand, getMockRunCommand(&s.calledCommand))
-	s.PatchValue(&manager.RunCommandWithRetry, getMockRunCommandWithRetry(&s.calledCommand))
+	s.PatchValue(&manager.CommandOutput, getMockCommandOutput(&s.calledCommand))
 
 	testCases := append(simpleTestCases, searchingTestCases...)
 	for i, testCase := range testCases {
@@ -320,10 +326,7 @@ func (s *ManagerSuite) TestSimpleCases(c *gc.C) {
 }
 
 func (s *ManagerSuite) TestSimpleErrorCases(c *gc.C) {
-	const (
-		expectedErr    = "packaging command failed: exit status 0"
-		expectedErrMsg = `E: I done failed :(`
-	)
+	const expectedErrMsg = `E: I done failed :(`
 	state := os.ProcessState{}
 	cmdError := &exec.ExitError{ProcessState: &state}
 
@@ -334,6 +337,7 @@ func (s *ManagerSuite) TestSimpleErrorCases(c *gc.C) {
 		s.PatchValue(&manager.ProcessStateSys, func(*os.ProcessState) interface{} {
 			return mockExitStatuser(i + 1)
 		})
+		expectedErr := fmt.Sprintf("packaging command failed: exit status %d", i+1)
 
 		// run for the apt PackageManager implementation:
 		_, err := testCase.operation(s.apt)
@@ -350,3 +354,118 @@ func (s *ManagerSuite) TestSimpleErrorCases(c *gc.C) {
 		c.Assert(strings.Join(cmd.Args, " "), gc.DeepEquals, testCase.expectedYumCmd)
 	}
 }
+
+func (s *ManagerSuite) TestContextCasesPassContext(c *gc.C) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "marker")
+
+	var received []context.Context
+	executor := contextExecutor(func(ctx context.Context) {
+		received = append(received, ctx)
+	})
+
+	for _, test := range []struct {
+		pacman   manager.PackageManager
+		commands int
+	}{
+		{manager.NewAptPackageManagerWithExecutor(executor), 4},
+		// The update command of snap does nothing, and is not run.
+		{manager.NewSnapPackageManagerWithExecutor(executor), 3},
+		{manager.NewYumPackageManagerWithExecutor(executor), 4},
+		{manager.NewZypperPackageManagerWithExecutor(executor), 4},
+	} {
+		ctxman, ok := test.pacman.(manager.PackageManagerContext)
+		c.Assert(ok, jc.IsTrue)
+
+		received = nil
+		c.Assert(ctxman.UpdateContext(ctx), jc.ErrorIsNil)
+		c.Assert(ctxman.InstallContext(ctx, testedPackageNames...), jc.ErrorIsNil)
+		_, err := ctxman.SearchContext(ctx, testedPackageName)
+		c.Assert(err, jc.ErrorIsNil)
+		_ = ctxman.IsInstalledContext(ctx, testedPackageName)
+
+		c.Assert(received, gc.HasLen, test.commands)
+		for _, got := range received {
+			c.Check(got.Value(ctxKey{}), gc.Equals, "marker")
+		}
+	}
+}
+
+// contextExecutor is a manager.Executor passing the context of each command
+// to a function, running no commands.
+type contextExecutor func(context.Context)
+
+// Execute is defined on the manager.Executor interface.
+func (e contextExecutor) Execute(ctx context.Context, _ manager.Command) (manager.Result, error) {
+	e(ctx)
+	return manager.Result{}, nil
+}
+
+func (s *ManagerSuite) TestInstallSpecUnsupportedField(c *gc.C) {
+	for i, test := range []struct {
+		pacman manager.PackageManager
+		spec   packaging.PackageSpec
+		err    string
+	}{{
+		pacman: s.fakeApt,
+		spec:   packaging.PackageSpec{Name: "lxd", Channel: "4.0/stable"},
+		err:    `package "lxd" channel not supported`,
+	}, {
+		pacman: s.fakeYum,
+		spec:   packaging.PackageSpec{Name: "bash", TargetRelease: "updates"},
+		err:    `package "bash" target release not supported`,
+	}, {
+		pacman: s.fakeZypper,
+		spec:   packaging.PackageSpec{Name: "bash", Channel: "stable"},
+		err:    `package "bash" channel not supported`,
+	}, {
+		pacman: s.fakeSnap,
+		spec:   packaging.PackageSpec{Name: "lxd", Architecture: "arm64"},
+		err:    `package "lxd" architecture not supported`,
+	}, {
+		pacman: s.fakeApt,
+		spec:   packaging.PackageSpec{Name: "bash", Version: "5.0; rm -rf /"},
+		err:    `package "bash" version "5.0; rm -rf /" not valid`,
+	}, {
+		pacman: s.fakeYum,
+		spec:   packaging.PackageSpec{Version: "1.0"},
+		err:    `package spec without a name not valid`,
+	}, {
+		pacman: s.fakeApt,
+		spec:   packaging.PackageSpec{Name: "-oAPT::Get::AllowUnauthenticated=1"},
+		err:    `debian package .* not valid: starts with a hyphen`,
+	}, {
+		pacman: s.fakeSnap,
+		spec:   packaging.PackageSpec{Name: "lxd", Channel: "latest/daily"},
+		err:    `snap channel .* not valid: risk "daily", .*`,
+	}} {
+		c.Logf("test %d: %+v", i, test.spec)
+		err := test.pacman.InstallSpec(test.spec)
+		c.Assert(err, gc.ErrorMatches, test.err)
+		c.Assert(s.exec.Commands(), gc.HasLen, 0)
+	}
+}
+
+func (s *ManagerSuite) TestInvalidInputRunsNoCommand(c *gc.C) {
+	for i, pacman := range []manager.PackageManager{s.fakeApt, s.fakeSnap, s.fakeYum, s.fakeZypper} {
+		c.Logf("test %d", i)
+		errs := []error{
+			pacman.Install("bash", "--allow-downgrades"),
+			pacman.Remove("bash;reboot"),
+			pacman.Purge("bash baz"),
+		}
+		if pacman != s.fakeSnap {
+			// snap takes no repositories.
+			errs = append(errs, pacman.AddRepository("$(reboot)"), pacman.RemoveRepository("--all"))
+		}
+		for _, err := range errs {
+			c.Check(err, jc.Satisfies, commands.IsValidationError)
+		}
+		_, err := pacman.Search("-v")
+		c.Check(err, jc.Satisfies, commands.IsValidationError)
+		_, err = pacman.PackageVersion("$HOME")
+		c.Check(err, jc.Satisfies, commands.IsValidationError)
+		c.Check(pacman.IsInstalled("`reboot`"), jc.IsFalse)
+		c.Check(s.exec.Commands(), gc.HasLen, 0)
+	}
+}
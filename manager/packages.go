@@ -0,0 +1,141 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+)
+
+// PackageState describes the state of a package as reported by the
+// package management system.
+type PackageState string
+
+const (
+	// PackageInstalled is the state of a package which is installed.
+	PackageInstalled PackageState = "installed"
+
+	// PackageHeld is the state of a package which is installed and held
+	// back from being upgraded.
+	PackageHeld PackageState = "held"
+
+	// PackageAvailable is the state of a package which is available for
+	// installation from the currently configured repositories.
+	PackageAvailable PackageState = "available"
+)
+
+// PackageInfo describes a single package as listed by a PackageManager.
+// Fields which the backend does not report are left empty.
+type PackageInfo struct {
+	// Name is the name of the package.
+	Name string
+
+	// Version is the version of the package.
+	Version string
+
+	// Architecture is the architecture the package was built for.
+	Architecture string
+
+	// Repository is the repository the package belongs to or, for
+	// snaps, the channel which is being tracked.
+	Repository string
+
+	// State is the state of the package on the system.
+	State PackageState
+}
+
+// packageListParser is a function which parses the output of a package
+// listing command into a list of packages.
+type packageListParser func(output string) []PackageInfo
+
+// versionParser is a function which extracts a package version from the
+// output of a version query command. It returns an empty string if the
+// output does not contain a version.
+type versionParser func(output string) string
+
+// infoField returns the value of the first "Key : value" line with the
+// given key in the output of commands such as "yum info" or "zypper info".
+func infoField(block, key string) string {
+	for _, line := range strings.Split(block, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == key {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+// parseRPMQueryVersion parses the output of the rpm query command, which
+// outputs an epoch:version-release line for each installed instance of a
+// package. The last instance listed is returned, omitting a zero epoch.
+func parseRPMQueryVersion(output string) string {
+	var version string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		// Lines such as "package foo is not installed" are not versions.
+		if line == "" || strings.ContainsAny(line, " \t") || !strings.Contains(line, ":") {
+			continue
+		}
+		version = strings.TrimPrefix(line, "0:")
+	}
+	return version
+}
+
+// specFields records which of the optional PackageSpec fields a package
+// manager is able to express in its install command.
+type specFields struct {
+	version       bool
+	architecture  bool
+	channel       bool
+	targetRelease bool
+}
+
+// validate returns an error if any of the given specs is invalid, names a
+// package which is malformed for the given commander, or uses a field
+// which the package manager cannot express.
+func (f specFields) validate(commander commands.Commander, specs []packaging.PackageSpec) error {
+	for _, spec := range specs {
+		if err := spec.Validate(); err != nil {
+			return errors.Trace(err)
+		}
+		if err := commander.ValidatePackage(spec.Name); err != nil {
+			return errors.Trace(err)
+		}
+		if spec.Channel != "" && f.channel {
+			if err := commands.ValidateSnapChannel(spec.Channel); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		fields := []struct {
+			name      string
+			set       bool
+			supported bool
+		}{
+			{"version", spec.Version != "", f.version},
+			{"architecture", spec.Architecture != "", f.architecture},
+			{"channel", spec.Channel != "", f.channel},
+			{"target release", spec.TargetRelease != "", f.targetRelease},
+		}
+		for _, field := range fields {
+			if field.set && !field.supported {
+				return errors.NotSupportedf("package %q %s", spec.Name, field.name)
+			}
+		}
+	}
+	return nil
+}
+
+// splitNameArch splits a "name.arch" style package identifier, as used by
+// rpm-based systems, into its name and architecture.
+func splitNameArch(pack string) (string, string) {
+	i := strings.LastIndex(pack, ".")
+	if i <= 0 {
+		return pack, ""
+	}
+	return pack[:i], pack[i+1:]
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
+	"github.com/juju/proxy"
+)
+
+const (
+	// PacmanExitCode is the exit code with which pacman reports all
+	// failures; whether they are retryable is decided from the command
+	// output.
+	PacmanExitCode int = 1
+)
+
+var (
+	// pacmanRetryable matches the output of pacman commands which failed
+	// due to the database being locked by another process, which leaves a
+	// db.lck file behind, or transient download failures.
+	pacmanRetryable = makeRegexpRetryable([]int{PacmanExitCode},
+		`(?i)unable to lock database`,
+		`db\.lck`,
+		`(?i)failed retrieving file`,
+		`(?i)failed to synchronize all databases`,
+	)
+
+	_ PackageManager        = (*Pacman)(nil)
+	_ PackageManagerContext = (*Pacman)(nil)
+)
+
+// Pacman is the PackageManager implementation for pacman-based systems.
+// As pacman has no proxy configuration of its own, the proxy settings given
+// to SetProxy are passed to the commands it runs through their environment,
+// and persisted in commands.PacmanProxyFilePath, which the login shells of
+// the system source.
+type Pacman struct {
+	basePackageManager
+}
+
+// NewPacmanPackageManager returns a PackageManager for pacman-based systems.
+func NewPacmanPackageManager() *Pacman {
+	return NewPacmanPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewPacmanPackageManagerWithExecutor returns a PackageManager for pacman-based
+// systems, which runs its commands with the given Executor.
+func NewPacmanPackageManagerWithExecutor(executor Executor) *Pacman {
+	manager := &Pacman{
+		basePackageManager: basePackageManager{
+			executor:       executor,
+			cmder:          commands.NewPacmanPackageCommander(),
+			commander:      commands.NewPacmanCommander(),
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parsePacmanQuery,
+			parseAvailable: parsePacmanSyncList,
+			parseVersion:   parsePacmanInfoVersion,
+			parseCandidate: parsePacmanInfoVersion,
+			specFields:     specFields{version: true, targetRelease: true},
+			// The version comparison of pacman is derived from that of
+			// rpm and uses the same epoch:version-release format.
+			versionScheme: version.RPMScheme,
+		},
+	}
+	manager.basePackageManager.retryable = manager
+	return manager
+}
+
+// Search is defined on the PackageManager interface.
+func (pacman *Pacman) Search(pack string) (bool, error) {
+	return pacman.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := pacman.validatePackages(pack); err != nil {
+		return false, err
+	}
+	out, code, err := pacman.runCommand(ctx, pacman.commander.Search(pack), pacman, pacman.env)
+
+	// pacman -Ss returns 1 without any output when it cannot find the
+	// package.
+	if code == PacmanExitCode && strings.TrimSpace(out) == "" {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Install is defined on the PackageManager interface.
+// The installed packages are upgraded along with the installation, as
+// Arch Linux does not support partial upgrades.
+func (pacman *Pacman) Install(packs ...string) error {
+	return pacman.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) InstallContext(ctx context.Context, packs ...string) error {
+	if err := pacman.validatePackages(packs...); err != nil {
+		return err
+	}
+	_, _, err := pacman.runCommand(ctx, pacman.commander.Install(packs...), pacman, pacman.env)
+	return err
+}
+
+// InstallSpec is defined on the PackageManager interface.
+// The installed packages are upgraded along with the installation, as for
+// Install.
+func (pacman *Pacman) InstallSpec(specs ...packaging.PackageSpec) error {
+	return pacman.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := pacman.specFields.validate(pacman.commander, specs); err != nil {
+		return err
+	}
+	_, _, err := pacman.runCommand(ctx, pacman.commander.InstallSpec(specs...), pacman, pacman.env)
+	return err
+}
+
+// AddRepository is defined on the PackageManager interface.
+// Repositories of pacman are sections of its configuration file, which are
+// rendered by the PackagingConfigurer; adding them is not supported here.
+func (pacman *Pacman) AddRepository(repo string) error {
+	return pacman.AddRepositoryContext(context.Background(), repo)
+}
+
+// AddRepositoryContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) AddRepositoryContext(ctx context.Context, repo string) error {
+	return errors.NotSupportedf("adding pacman repositories")
+}
+
+// RemoveRepository is defined on the PackageManager interface.
+// Repositories of pacman are sections of its configuration file; removing
+// them is not supported here.
+func (pacman *Pacman) RemoveRepository(repo string) error {
+	return pacman.RemoveRepositoryContext(context.Background(), repo)
+}
+
+// RemoveRepositoryContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) RemoveRepositoryContext(ctx context.Context, repo string) error {
+	return errors.NotSupportedf("removing pacman repositories")
+}
+
+// GetProxySettings is defined on the PackageManager interface.
+func (pacman *Pacman) GetProxySettings() (proxy.Settings, error) {
+	return pacman.GetProxySettingsContext(context.Background())
+}
+
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+// It returns the proxy settings persisted in commands.PacmanProxyFilePath.
+func (pacman *Pacman) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	return pacman.getProfileProxy(ctx, "pacman", commands.PacmanProxyFilePath)
+}
+
+// SetProxy is defined on the PackageManager interface.
+// It persists the proxy settings in commands.PacmanProxyFilePath, replacing
+// the previous ones, and passes them to all subsequent commands through
+// their environment.
+func (pacman *Pacman) SetProxy(settings proxy.Settings) error {
+	return pacman.SetProxyContext(context.Background(), settings)
+}
+
+// SetProxyContext is defined on the PackageManagerContext interface.
+func (pacman *Pacman) SetProxyContext(ctx context.Context, settings proxy.Settings) error {
+	// The file is only readable by its owner if the proxy URLs hold the
+	// credentials of authenticated proxies.
+	return pacman.setProfileProxy(ctx, "pacman", settings)
+}
+
+func (*Pacman) IsRetryable(code int, output string) bool {
+	return pacmanRetryable.IsRetryable(code, output)
+}
+
+// MaskError will mask an error using the cmd exit code and the stdout/stderr
+// output.
+func (*Pacman) MaskError(code int, output string) error {
+	// pacman reports packages which cannot be found with
+	// "error: target not found: <name>".
+	if strings.Contains(output, "target not found") {
+		return errors.New("unable to locate package")
+	}
+	return nil
+}
+
+// pacmanSyncListRE matches a line of the output of "pacman -Sl".
+var pacmanSyncListRE = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)(\s+\[installed(?::\s*(\S+))?\])?\s*$`)
+
+// parsePacmanQuery parses the output of "pacman -Q", which lists the name
+// and version of each installed package:
+//
+//	bash 5.2.015-1
+//	linux 6.5.9.arch2-1
+func parsePacmanQuery(output string) []PackageInfo {
+	var res []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		res = append(res, PackageInfo{
+			Name:    fields[0],
+			Version: fields[1],
+			State:   PackageInstalled,
+		})
+	}
+	return res
+}
+
+// parsePacmanSyncList parses the output of "pacman -Sl", which lists the
+// repository, name and version of each available package:
+//
+//	core bash 5.2.015-1 [installed]
+//	core linux 6.5.9.arch2-1 [installed: 6.5.8.arch1-1]
+//	extra zsh 5.9-4
+//
+// Packages are only reported as installed if their available version is.
+func parsePacmanSyncList(output string) []PackageInfo {
+	var res []PackageInfo
+	for _, line := range strings.Split(output, "\n") {
+		match := pacmanSyncListRE.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		state := PackageAvailable
+		if match[4] != "" && match[5] == "" {
+			state = PackageInstalled
+		}
+		res = append(res, PackageInfo{
+			Name:       match[2],
+			Version:    match[3],
+			Repository: match[1],
+			State:      state,
+		})
+	}
+	return res
+}
+
+// parsePacmanInfoVersion parses the version of a package out of the output
+// of "pacman -Qi" or "pacman -Si":
+//
+//	Repository      : core
+//	Name            : bash
+//	Version         : 5.2.015-1
+//
+// For the latter, the version in the first repository listing the package,
+// which is the one pacman would install, is returned.
+func parsePacmanInfoVersion(output string) string {
+	return infoField(output, "Version")
+}
@@ -0,0 +1,29 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"regexp"
+)
+
+// Redacted replaces the secrets removed by Redact.
+const Redacted = "REDACTED"
+
+var (
+	// userinfoRE matches the password of the user information of URLs,
+	// such as those of authenticated proxies, with or without a scheme.
+	userinfoRE = regexp.MustCompile(`(^|[\s"'=,;(\[]|://)([^\s/@:"'=,;]+):([^\s/@"']+)@`)
+
+	// passwordSettingRE matches the values of password settings, such as
+	// proxy_password=... of yum or "password ..." of apt's auth.conf.
+	passwordSettingRE = regexp.MustCompile(`(?i)(password(?:\s*[=:]\s*|\s+))("[^"]*"|\S+)`)
+)
+
+// Redact returns the given command line, output or error message with the
+// passwords of URLs and password settings replaced by Redacted, so that it
+// can be logged or returned without leaking credentials.
+func Redact(s string) string {
+	s = userinfoRE.ReplaceAllString(s, "${1}${2}:"+Redacted+"@")
+	return passwordSettingRE.ReplaceAllString(s, "${1}"+Redacted)
+}
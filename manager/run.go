@@ -5,6 +5,7 @@
 package manager
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -26,24 +27,38 @@ var (
 	Attempts = 30
 )
 
-// CommandOutput is cmd.Output. It was aliased for testing purposes.
+// CommandOutput is cmd.Output. It was aliased for testing purposes. The
+// Executor returned by NewExecExecutor runs every command with it.
 var CommandOutput = (*exec.Cmd).CombinedOutput
 
-// ProcessStateSys is ps.Sys. It was aliased for testing purposes.
+// ProcessStateSys is ps.Sys. It was aliased for testing purposes. The
+// Executor returned by NewExecExecutor reports the exit code of every
+// command with it.
 var ProcessStateSys = (*os.ProcessState).Sys
 
-// RunCommand is helper function to execute the command and gather the output.
-var RunCommand = func(command string, args ...string) (output string, err error) {
-	cmd := exec.Command(command, args...)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
-}
-
 // exitStatuser is a mini-interface for the ExitStatus() method.
 type exitStatuser interface {
 	ExitStatus() int
 }
 
+// RunCommand is helper function to execute the command and gather the output.
+//
+// Deprecated: the commands of a PackageManager are run with its Executor;
+// use an Executor directly instead.
+func RunCommand(command string, args ...string) (output string, err error) {
+	return RunCommandContext(context.Background(), command, args...)
+}
+
+// RunCommandContext is the context-aware variant of RunCommand. The command
+// is killed if the context is done before it completes.
+//
+// Deprecated: the commands of a PackageManager are run with its Executor;
+// use an Executor directly instead.
+func RunCommandContext(ctx context.Context, command string, args ...string) (output string, err error) {
+	out, _, err := execute(ctx, NewExecExecutor(), Command{Args: append([]string{command}, args...)})
+	return out, err
+}
+
 // Retryable allows the caller to define if a retry is retryable based on the
 // incoming error, command exit code or the stderr message.
 type Retryable interface {
@@ -77,59 +92,74 @@ func DefaultRetryPolicy() RetryPolicy {
 // It tries to do so for 30 times with a 10 second sleep between commands.
 // It returns the output of the command, the exit code, and an error, if one occurs,
 // logging along the way.
-// It was aliased for testing purposes.
-var RunCommandWithRetry = func(cmd string, retryable Retryable, policy RetryPolicy, envVariables []string) (output string, code int, _ error) {
+//
+// Deprecated: the commands of a PackageManager are run with its Executor;
+// use an Executor directly instead.
+func RunCommandWithRetry(cmd string, retryable Retryable, policy RetryPolicy, envVariables []string) (output string, code int, _ error) {
+	return RunCommandWithRetryContext(context.Background(), cmd, retryable, policy, envVariables)
+}
+
+// RunCommandWithRetryContext is the context-aware variant of
+// RunCommandWithRetry. No further attempts are made once the context is done,
+// and a command which is still running at that point is killed. The cause of
+// the returned error is then the context's error.
+//
+// Deprecated: the commands of a PackageManager are run with its Executor;
+// use an Executor directly instead.
+func RunCommandWithRetryContext(ctx context.Context, cmd string, retryable Retryable, policy RetryPolicy, envVariables []string) (output string, code int, _ error) {
 	// split the command for use with exec
 	args := strings.Fields(cmd)
 	if len(args) <= 1 {
 		return "", -1, errors.New(fmt.Sprintf("too few arguments: expected at least 2, got %d", len(args)))
 	}
+	return runWithRetry(ctx, NewExecExecutor(), Command{Args: args, Env: envVariables}, retryable, policy)
+}
 
-	logger.Infof("Running: %s", cmd)
+// runWithRetry runs the given command with the given executor until it
+// succeeds, fails with an error which is not retryable or the attempts of
+// the policy are exhausted. It returns the output of the command, as
+// returned by Result.Output, the exit code, and an error, if one occurs,
+// logging along the way. No further attempts are made once the context is
+// done, and a command which is still running at that point is killed. The
+// cause of the returned error is then the context's error.
+func runWithRetry(ctx context.Context, executor Executor, cmd Command, retryable Retryable, policy RetryPolicy) (output string, code int, _ error) {
+	logger.Infof("Running: %s", Redact(cmd.String()))
 
 	// Retry operation 30 times, sleeping every 10 seconds between attempts.
 	// This avoids failure in the case of something else having the dpkg lock
 	// (e.g. a charm on the machine we're deploying containers to).
 	var (
-		out      []byte
+		out      string
 		fatalErr error
 	)
 	retryErr := retry.Call(retry.CallArgs{
 		Clock:    clock.WallClock,
 		Delay:    policy.Delay,
 		Attempts: policy.Attempts,
+		Stop:     ctx.Done(),
 		NotifyFunc: func(lastError error, attempt int) {
-			logger.Infof("Retrying: %s", cmd)
+			logger.Infof("Retrying: %s", Redact(cmd.String()))
 		},
 		Func: func() error {
-			// Create the command for each attempt, because we need to
-			// call cmd.CombinedOutput only once. See http://pad.lv/1394524.
-			command := exec.Command(args[0], args[1:]...)
-			command.Env = append(os.Environ(), envVariables...)
-
 			var err error
-			out, err = CommandOutput(command)
+			out, code, err = execute(ctx, executor, cmd)
 			return errors.Trace(err)
 		},
 		IsFatalError: func(err error) bool {
-			exitError, ok := errors.Cause(err).(*exec.ExitError)
-			if !ok {
-				logger.Errorf("unexpected error type %T", err)
+			if ctx.Err() != nil {
 				return true
 			}
-			waitStatus, ok := ProcessStateSys(exitError.ProcessState).(exitStatuser)
-			if !ok {
-				logger.Errorf("unexpected process state type %T", exitError.ProcessState.Sys())
+			if _, ok := errors.Cause(err).(*exitError); !ok {
+				logger.Errorf("cannot run command: %v", Redact(err.Error()))
 				return true
 			}
 
-			code = waitStatus.ExitStatus()
-			fatal := !retryable.IsRetryable(code, string(out))
+			fatal := !retryable.IsRetryable(code, out)
 			if fatal {
 				// In order to give better error messages to the user, we
 				// sometimes want to mask the original error message.
 				if trans, ok := retryable.(ErrorTransformer); ok {
-					maskedErr := trans.MaskError(code, string(out))
+					maskedErr := trans.MaskError(code, out)
 					fatalErr = errors.Annotatef(maskedErr, "encountered fatal error")
 				}
 			}
@@ -141,13 +171,20 @@ var RunCommandWithRetry = func(cmd string, retryable Retryable, policy RetryPoli
 		retryErr = fatalErr
 	}
 
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		logger.Errorf("packaging command aborted: %v; cmd: %q; output: %s",
+			ctxErr, Redact(cmd.String()), Redact(out))
+		return out, code, errors.Annotate(ctxErr, "packaging command aborted")
+	}
+
 	if retryErr != nil {
-		logger.Errorf("packaging command failed: %v; cmd: %q; output: %s",
-			retryErr, cmd, string(out))
-		return string(out), code, errors.Errorf("packaging command failed: %v", retryErr)
+		msg := Redact(retryErr.Error())
+		logger.Errorf("packaging command failed: %s; cmd: %q; output: %s",
+			msg, Redact(cmd.String()), Redact(out))
+		return out, code, errors.Errorf("packaging command failed: %s", msg)
 	}
 
-	return string(out), 0, nil
+	return out, 0, nil
 }
 
 // regexpRetryable checks a series of regexps to see if they show up in the
@@ -5,14 +5,19 @@
 package manager_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
 )
 
 var _ = gc.Suite(&RunSuite{})
@@ -129,3 +134,123 @@ func (s *RunSuite) TestRunCommandWithRetryStopsWithFatalError(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, "packaging command failed: encountered fatal error: unable to locate package")
 	c.Check(calls, gc.Equals, 1)
 }
+
+// executorFunc is a manager.Executor running commands with a function.
+type executorFunc func(context.Context, manager.Command) (manager.Result, error)
+
+// Execute is defined on the manager.Executor interface.
+func (f executorFunc) Execute(ctx context.Context, cmd manager.Command) (manager.Result, error) {
+	return f(ctx, cmd)
+}
+
+type alwaysRetryable struct{}
+
+func (alwaysRetryable) IsRetryable(int, string) bool {
+	return true
+}
+
+func (s *RunSuite) TestRunCommandWithRetryDoesNotRetryExecutorErrors(c *gc.C) {
+	executor := managertesting.NewFakeExecutor(managertesting.Response{Err: errors.New("connection lost")})
+
+	policy := manager.RetryPolicy{Delay: testing.ShortWait, Attempts: 3}
+	cmd := manager.Command{Args: []string{"apt-get", "update"}}
+	_, code, err := manager.RunWithRetry(context.Background(), executor, cmd, alwaysRetryable{}, policy)
+	c.Check(err, gc.ErrorMatches, "packaging command failed: connection lost")
+	c.Check(code, gc.Equals, -1)
+	c.Check(executor.Commands(), gc.HasLen, 1)
+}
+
+func (s *RunSuite) TestRunCommandWithRetryContextStopsBetweenAttempts(c *gc.C) {
+	var calls int
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	executor := executorFunc(func(context.Context, manager.Command) (manager.Result, error) {
+		calls++
+		cancel()
+		return manager.Result{Code: 100}, nil
+	})
+
+	policy := manager.RetryPolicy{
+		Delay:    time.Minute,
+		Attempts: 3,
+	}
+	cmd := manager.Command{Args: []string{"apt-get", "install", "foo"}}
+	_, _, err := manager.RunWithRetry(ctx, executor, cmd, alwaysRetryable{}, policy)
+	c.Check(err, gc.ErrorMatches, "packaging command aborted: context canceled")
+	c.Check(errors.Cause(err), gc.Equals, context.Canceled)
+	c.Check(calls, gc.Equals, 1)
+}
+
+func (s *RunSuite) TestRunCommandWithRetryContextKillsCommand(c *gc.C) {
+	// The isolation suite clears $PATH, so refer to sleep directly.
+	const sleep = "/bin/sleep"
+	if _, err := os.Stat(sleep); err != nil {
+		c.Skip(sleep + " not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testing.ShortWait)
+	defer cancel()
+
+	policy := manager.RetryPolicy{
+		Delay:    time.Minute,
+		Attempts: 3,
+	}
+	start := time.Now()
+	_, _, err := manager.RunCommandWithRetryContext(ctx, sleep+" 60", alwaysRetryable{}, policy, nil)
+	c.Check(errors.Cause(err), gc.Equals, context.DeadlineExceeded)
+	c.Check(time.Since(start) < testing.LongWait, gc.Equals, true)
+}
+
+func (s *RunSuite) TestRunCommandWithRetryContextAlreadyDone(c *gc.C) {
+	executor := managertesting.NewFakeExecutor()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cmd := manager.Command{Args: []string{"apt-get", "update"}}
+	_, _, err := manager.RunWithRetry(ctx, executor, cmd, alwaysRetryable{}, manager.DefaultRetryPolicy())
+	c.Check(errors.Cause(err), gc.Equals, context.Canceled)
+	c.Check(executor.Commands(), gc.HasLen, 1)
+}
+
+func (s *RunSuite) TestExecExecutor(c *gc.C) {
+	// The isolation suite clears $PATH, so refer to sh directly.
+	const sh = "/bin/sh"
+	if _, err := os.Stat(sh); err != nil {
+		c.Skip(sh + " not available")
+	}
+	dir := c.MkDir()
+
+	res, err := manager.NewExecExecutor().Execute(context.Background(), manager.Command{
+		Args:  []string{sh, "-c", `read line; echo "$line $GREETING $PWD"; echo oops >&2; exit 3`},
+		Env:   []string{"GREETING=hello"},
+		Stdin: []byte("stdin\n"),
+		Dir:   dir,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(res.Stdout), gc.Equals, "stdin hello "+dir+"\noops\n")
+	c.Check(res.Code, gc.Equals, 3)
+}
+
+func (s *RunSuite) TestExecExecutorRunsEveryCommandWithCommandOutput(c *gc.C) {
+	var args [][]string
+	s.PatchValue(&manager.CommandOutput, func(cmd *exec.Cmd) ([]byte, error) {
+		args = append(args, cmd.Args)
+		return nil, nil
+	})
+
+	apt := manager.NewAptPackageManager()
+	err := apt.AddRepository("deb http://archive.example.com/ubuntu focal main")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(args, jc.DeepEquals, [][]string{
+		{"add-apt-repository", "--yes", "deb http://archive.example.com/ubuntu focal main"},
+	})
+}
+
+func (s *RunSuite) TestExecExecutorNotFound(c *gc.C) {
+	_, err := manager.NewExecExecutor().Execute(context.Background(), manager.Command{
+		Args: []string{"/nonexistent/command"},
+	})
+	c.Check(err, gc.ErrorMatches, ".*no such file or directory")
+}
@@ -4,14 +4,14 @@
 package manager
 
 import (
-	"fmt"
-	"io/ioutil"
-	"os"
+	"context"
 	"regexp"
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
 	"github.com/juju/proxy"
 )
 
@@ -29,12 +29,13 @@ var (
 
 	// snapProxyRe is a regexp which matches all proxy-related configuration
 	// options in the snap proxy settings output
-	snapProxyRE = regexp.MustCompile(`(?im)^proxy\.(?P<protocol>[a-z]+)\s+(?P<proxy>.+)$`)
+	snapProxyRE = regexp.MustCompile(`(?im)^proxy\.(?P<protocol>[a-z-]+)\s+(?P<proxy>.+)$`)
 
 	snapNotFoundRE = regexp.MustCompile(`(?i)error: snap "[^"]+" not found`)
 	trackingRE     = regexp.MustCompile(`(?im)tracking:\s*(.*)$`)
 
-	_ PackageManager = (*Snap)(nil)
+	_ PackageManager        = (*Snap)(nil)
+	_ PackageManagerContext = (*Snap)(nil)
 )
 
 // Snap is the PackageManager implementation for snap-based systems.
@@ -45,13 +46,28 @@ type Snap struct {
 
 // NewSnapPackageManager returns a PackageManager for snap-based systems.
 func NewSnapPackageManager() *Snap {
+	return NewSnapPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewSnapPackageManagerWithExecutor returns a PackageManager for snap-based
+// systems, which runs its commands with the given Executor.
+func NewSnapPackageManagerWithExecutor(executor Executor) *Snap {
 	return &Snap{
 		basePackageManager: basePackageManager{
-			cmder: commands.NewSnapPackageCommander(),
+			executor:  executor,
+			cmder:     commands.NewSnapPackageCommander(),
+			commander: commands.NewSnapCommander(),
 			retryPolicy: RetryPolicy{
 				Delay:    Delay,
 				Attempts: SnapAttempts,
 			},
+			// There is no way of listing all the snaps in the store.
+			parseInstalled:     parseSnapList,
+			parseVersion:       parseSnapListVersion,
+			parseCandidate:     parseSnapInfoCandidate,
+			specFields:         specFields{version: true, channel: true},
+			versionScheme:      version.SnapScheme,
+			parseSchemeVersion: parseSnapListRevision,
 		},
 		// InstallRetryable checks a series of strings, to pattern
 		// match against the cmd output to see if an install command is
@@ -65,7 +81,15 @@ func NewSnapPackageManager() *Snap {
 
 // Search is defined on the PackageManager interface.
 func (snap *Snap) Search(pack string) (bool, error) {
-	out, _, err := RunCommandWithRetry(snap.cmder.SearchCmd(pack), snap, snap.retryPolicy, nil)
+	return snap.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (snap *Snap) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := snap.validatePackages(pack); err != nil {
+		return false, err
+	}
+	out, _, err := snap.runCommand(ctx, snap.commander.Search(pack), snap, nil)
 	if strings.Contains(combinedOutput(out, err), "error: no snap found") {
 		return false, nil
 	} else if err != nil {
@@ -77,7 +101,15 @@ func (snap *Snap) Search(pack string) (bool, error) {
 
 // IsInstalled is defined on the PackageManager interface.
 func (snap *Snap) IsInstalled(pack string) bool {
-	out, _, err := RunCommandWithRetry(snap.cmder.IsInstalledCmd(pack), snap, snap.retryPolicy, nil)
+	return snap.IsInstalledContext(context.Background(), pack)
+}
+
+// IsInstalledContext is defined on the PackageManagerContext interface.
+func (snap *Snap) IsInstalledContext(ctx context.Context, pack string) bool {
+	if snap.validatePackages(pack) != nil {
+		return false
+	}
+	out, _, err := snap.runCommand(ctx, snap.commander.IsInstalled(pack), snap, nil)
 	if strings.Contains(combinedOutput(out, err), "error: no matching snaps installed") || err != nil {
 		return false
 	}
@@ -86,7 +118,15 @@ func (snap *Snap) IsInstalled(pack string) bool {
 
 // InstalledChannel returns the snap channel for an installed package.
 func (snap *Snap) InstalledChannel(pack string) string {
-	out, _, err := RunCommandWithRetry(fmt.Sprintf("snap info %s", pack), snap, snap.retryPolicy, nil)
+	return snap.InstalledChannelContext(context.Background(), pack)
+}
+
+// InstalledChannelContext is the context-aware variant of InstalledChannel.
+func (snap *Snap) InstalledChannelContext(ctx context.Context, pack string) string {
+	if snap.validatePackages(pack) != nil {
+		return ""
+	}
+	out, _, err := snap.runCommand(ctx, commands.Command{Args: []string{"snap", "info", pack}}, snap, nil)
 	combined := combinedOutput(out, err)
 	matches := trackingRE.FindAllStringSubmatch(combined, 1)
 	if len(matches) == 0 {
@@ -98,8 +138,19 @@ func (snap *Snap) InstalledChannel(pack string) string {
 
 // ChangeChannel updates the tracked channel for an installed snap.
 func (snap *Snap) ChangeChannel(pack, channel string) error {
-	cmd := fmt.Sprintf("snap refresh --channel %s %s", channel, pack)
-	out, _, err := RunCommandWithRetry(cmd, snap, snap.retryPolicy, nil)
+	return snap.ChangeChannelContext(context.Background(), pack, channel)
+}
+
+// ChangeChannelContext is the context-aware variant of ChangeChannel.
+func (snap *Snap) ChangeChannelContext(ctx context.Context, pack, channel string) error {
+	if err := snap.validatePackages(pack); err != nil {
+		return err
+	}
+	if err := commands.ValidateSnapChannel(channel); err != nil {
+		return errors.Trace(err)
+	}
+	cmd := commands.Command{Args: []string{"snap", "refresh", "--channel", channel, pack}}
+	out, _, err := snap.runCommand(ctx, cmd, snap, nil)
 	if err != nil {
 		return err
 	} else if strings.Contains(combinedOutput(out, err), "not installed") {
@@ -111,7 +162,54 @@ func (snap *Snap) ChangeChannel(pack, channel string) error {
 
 // Install is defined on the PackageManager interface.
 func (snap *Snap) Install(packs ...string) error {
-	out, _, err := RunCommandWithRetry(snap.cmder.InstallCmd(packs...), snap.installRetryable, snap.retryPolicy, nil)
+	return snap.InstallContext(context.Background(), packs...)
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (snap *Snap) InstallContext(ctx context.Context, packs ...string) error {
+	if err := snap.validatePackages(packs...); err != nil {
+		return err
+	}
+	return snap.install(ctx, snap.commander.Install(packs...))
+}
+
+// InstallSpec is defined on the PackageManager interface.
+func (snap *Snap) InstallSpec(specs ...packaging.PackageSpec) error {
+	return snap.InstallSpecContext(context.Background(), specs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+//
+// As snap only accepts the --channel and --revision options when installing
+// a single snap, a separate command is run for each spec which uses them.
+// No command is run without specs.
+func (snap *Snap) InstallSpecContext(ctx context.Context, specs ...packaging.PackageSpec) error {
+	if err := snap.specFields.validate(snap.commander, specs); err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	var plain []packaging.PackageSpec
+	for _, spec := range specs {
+		if spec.Channel == "" && spec.Version == "" {
+			plain = append(plain, spec)
+			continue
+		}
+		if err := snap.install(ctx, snap.commander.InstallSpec(spec)); err != nil {
+			return err
+		}
+	}
+	if len(plain) == 0 {
+		return nil
+	}
+	return snap.install(ctx, snap.commander.InstallSpec(plain...))
+}
+
+// install runs the given snap install command.
+func (snap *Snap) install(ctx context.Context, cmd commands.Command) error {
+	out, _, err := snap.runCommand(ctx, cmd, snap.installRetryable, nil)
 	if snapNotFoundRE.MatchString(combinedOutput(out, err)) {
 		return errors.New("unable to locate package")
 	}
@@ -120,9 +218,14 @@ func (snap *Snap) Install(packs ...string) error {
 
 // GetProxySettings is defined on the PackageManager interface.
 func (snap *Snap) GetProxySettings() (proxy.Settings, error) {
+	return snap.GetProxySettingsContext(context.Background())
+}
+
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+func (snap *Snap) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
 	var res proxy.Settings
 
-	out, _, err := RunCommandWithRetry(snap.cmder.GetProxyCmd(), snap, snap.retryPolicy, nil)
+	out, _, err := snap.runCommand(ctx, snap.commander.GetProxy(), snap, nil)
 	if strings.Contains(combinedOutput(out, err), `no "proxy" configuration option`) {
 		return res, nil
 	} else if err != nil {
@@ -137,6 +240,8 @@ func (snap *Snap) GetProxySettings() (proxy.Settings, error) {
 			res.Https = match[2]
 		case "ftp":
 			res.Ftp = match[2]
+		case "no-proxy":
+			res.NoProxy = strings.Join(commands.NoProxyHosts(match[2]), ",")
 		}
 	}
 
@@ -150,32 +255,21 @@ func (snap *Snap) GetProxySettings() (proxy.Settings, error) {
 // these need to be configured separately before invoking this method via a
 // call to SetProxy.
 func (snap *Snap) ConfigureStoreProxy(assertions, storeID string) error {
+	return snap.ConfigureStoreProxyContext(context.Background(), assertions, storeID)
+}
+
+// ConfigureStoreProxyContext is the context-aware variant of
+// ConfigureStoreProxy.
+func (snap *Snap) ConfigureStoreProxyContext(ctx context.Context, assertions, storeID string) error {
 	// Setup proxy based on the instructions from:
 	// https://docs.ubuntu.com/snap-store-proxy/en/devices
-	//
-	// Note that while the above instructions run "snap ack /dev/stdin" the
-	// code below will instead write the assertions to a temp file and pass
-	// that to snap ack. This is purely done to make testing easier.
-	assertFile, err := ioutil.TempFile("", "assertions")
-	if err != nil {
-		return errors.Annotate(err, "unable to create assertion file")
-	}
-	defer func() {
-		_ = assertFile.Close()
-		_ = os.Remove(assertFile.Name())
-	}()
-	if _, err = assertFile.WriteString(assertions); err != nil {
-		return errors.Annotate(err, "unable to write to assertion file")
-	}
-	_ = assertFile.Close()
-
-	ackCmd := fmt.Sprintf("snap ack %s", assertFile.Name())
-	if _, _, err = RunCommandWithRetry(ackCmd, snap, snap.retryPolicy, nil); err != nil {
+	ack := Command{Args: []string{"snap", "ack", "/dev/stdin"}, Stdin: []byte(assertions)}
+	if _, _, err := runWithRetry(ctx, snap.executor, ack, snap, snap.retryPolicy); err != nil {
 		return errors.Annotate(err, "failed to execute 'snap ack'")
 	}
 
-	setCmd := fmt.Sprintf("snap set system proxy.store=%s", storeID)
-	if _, _, err = RunCommandWithRetry(setCmd, snap, snap.retryPolicy, nil); err != nil {
+	setCmd := commands.Command{Args: []string{"snap", "set", "system", "proxy.store=" + storeID}}
+	if _, _, err := snap.runCommand(ctx, setCmd, snap, nil); err != nil {
 		return errors.Annotatef(err, "failed to configure snap to use store ID %q", storeID)
 	}
 
@@ -188,14 +282,170 @@ func (snap *Snap) ConfigureStoreProxy(assertions, storeID string) error {
 // separately via a call to SetProxy.
 // call to SetProxy.
 func (snap *Snap) DisableStoreProxy() error {
-	setCmd := "snap set system proxy.store="
-	if _, _, err := RunCommandWithRetry(setCmd, snap, snap.retryPolicy, nil); err != nil {
+	return snap.DisableStoreProxyContext(context.Background())
+}
+
+// DisableStoreProxyContext is the context-aware variant of DisableStoreProxy.
+func (snap *Snap) DisableStoreProxyContext(ctx context.Context) error {
+	setCmd := commands.Command{Args: []string{"snap", "set", "system", "proxy.store="}}
+	if _, _, err := snap.runCommand(ctx, setCmd, snap, nil); err != nil {
 		return errors.Annotate(err, "failed to configure snap to not use a store proxy")
 	}
 
 	return nil
 }
 
+// parseSnapList parses the output of "snap list", which looks like:
+//
+//	Name    Version   Rev   Tracking       Publisher   Notes
+//	core18  20200724  1885  latest/stable  canonical✓  base
+//
+// The tracked channel is reported as the repository of each snap.
+func parseSnapList(output string) []PackageInfo {
+	var (
+		res     []PackageInfo
+		columns map[string]int
+	)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if columns == nil {
+			if fields[0] != "Name" {
+				continue
+			}
+			columns = make(map[string]int)
+			for i, field := range fields {
+				columns[strings.ToLower(field)] = i
+			}
+			continue
+		}
+		column := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) && fields[i] != "-" {
+				return fields[i]
+			}
+			return ""
+		}
+
+		res = append(res, PackageInfo{
+			Name:       fields[0],
+			Version:    column("version"),
+			Repository: column("tracking"),
+			State:      PackageInstalled,
+		})
+	}
+	return res
+}
+
+// parseSnapListVersion parses the version of a single snap out of the
+// output of "snap list <name>".
+func parseSnapListVersion(output string) string {
+	snaps := parseSnapList(output)
+	if len(snaps) == 0 {
+		return ""
+	}
+	return snaps[0].Version
+}
+
+// parseSnapListRevision parses the revision of a single snap out of the
+// output of "snap list <name>".
+func parseSnapListRevision(output string) string {
+	var rev = -1
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if rev < 0 {
+			for i, field := range fields {
+				if fields[0] == "Name" && field == "Rev" {
+					rev = i
+				}
+			}
+			continue
+		}
+		if rev < len(fields) {
+			return fields[rev]
+		}
+		return ""
+	}
+	return ""
+}
+
+// parseSnapInfoCandidate parses the output of "snap info" and returns the
+// version published in the channel which is being tracked or, if the snap
+// is not installed, in the default channel:
+//
+//	tracking:     latest/stable
+//	channels:
+//	  latest/stable:    4.0.9   2021-12-02 (22525) 74MB -
+//	  latest/candidate: ↑
+//	  latest/beta:      4.21    2021-12-10 (22162) 78MB -
+//
+// An arrow means that the channel is closed and follows the one above it.
+func parseSnapInfoCandidate(output string) string {
+	channel := "stable"
+	if matches := trackingRE.FindStringSubmatch(output); matches != nil {
+		channel = strings.TrimSpace(matches[1])
+	}
+	channel = normaliseSnapChannel(channel)
+
+	var inChannels bool
+	var previous string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "channels:") {
+			inChannels = true
+			continue
+		}
+		if !inChannels {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			break
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		v := ""
+		if len(fields) > 0 {
+			v = fields[0]
+		}
+		if v == "↑" {
+			v = previous
+		}
+		previous = v
+
+		if normaliseSnapChannel(parts[0]) == channel {
+			if v == "–" || v == "-" {
+				return ""
+			}
+			return v
+		}
+	}
+	return ""
+}
+
+// normaliseSnapChannel returns the given snap channel in its full
+// "track/risk" form, with the implicit "latest" track removed.
+func normaliseSnapChannel(channel string) string {
+	parts := strings.Split(channel, "/")
+	switch parts[0] {
+	case "stable", "candidate", "beta", "edge":
+		parts = append([]string{"latest"}, parts...)
+	}
+	if len(parts) == 1 {
+		parts = append(parts, "stable")
+	}
+	if parts[0] == "latest" {
+		parts = parts[1:]
+	}
+	return strings.Join(parts, "/")
+}
+
 func combinedOutput(out string, err error) string {
 	res := out
 	if err != nil {
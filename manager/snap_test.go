@@ -8,13 +8,16 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/proxy"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/packaging/v3"
 	"github.com/juju/packaging/v3/commands"
 	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
 )
 
 var (
@@ -61,6 +64,12 @@ DATA...
 
 type SnapSuite struct {
 	testing.IsolationSuite
+	exec *managertesting.FakeExecutor
+}
+
+func (s *SnapSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.exec = managertesting.NewFakeExecutor()
 }
 
 func (s *SnapSuite) TestGetProxySettingsEmpty(c *gc.C) {
@@ -100,6 +109,22 @@ proxy.ftp  localhost:2121`
 	})
 }
 
+func (s *SnapSuite) TestProxySettingsRoundTripNoProxy(c *gc.C) {
+	const expected = `Key             Value
+proxy.http      localhost:8080
+proxy.no-proxy  localhost,*.example.com,10.0.0.0/8`
+	s.exec.Push(managertesting.Response{Stdout: expected})
+
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	out, err := pacman.GetProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(out, gc.Equals, proxy.Settings{
+		Http:    "localhost:8080",
+		NoProxy: "localhost,*.example.com,10.0.0.0/8",
+	})
+}
+
 func (s *SnapSuite) TestSearchForExistingPackage(c *gc.C) {
 	const expected = `name:      juju
 summary:   juju client
@@ -481,3 +506,170 @@ func (s *SnapSuite) mockExitError(code int) error {
 	})
 	return err
 }
+
+func (s *SnapSuite) TestChangeChannelInvalid(c *gc.C) {
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	err := pacman.ChangeChannel("lxd", "latest/candidate --dangerous")
+	c.Assert(err, gc.ErrorMatches, `snap channel .* not valid: contains ' '`)
+	c.Assert(err, jc.Satisfies, commands.IsValidationError)
+
+	err = pacman.ChangeChannel("--amend", "latest/candidate")
+	c.Assert(err, gc.ErrorMatches, `snap name .* not valid: starts with a hyphen`)
+	c.Assert(s.exec.Commands(), gc.HasLen, 0)
+}
+
+func (s *SnapSuite) TestListInstalled(c *gc.C) {
+	const output = `Name    Version   Rev    Tracking       Publisher   Notes
+core18  20200724  1885   latest/stable  canonical✓  base
+juju    2.9.33    20692  2.9/stable     canonical✓  classic
+mysnap  0.1       x1     -              -           -
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	commander := commands.NewSnapCommander()
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	packs, err := pacman.ListInstalled()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, commander.ListInstalled().Argv())
+	c.Assert(packs, gc.DeepEquals, []manager.PackageInfo{{
+		Name:       "core18",
+		Version:    "20200724",
+		Repository: "latest/stable",
+		State:      manager.PackageInstalled,
+	}, {
+		Name:       "juju",
+		Version:    "2.9.33",
+		Repository: "2.9/stable",
+		State:      manager.PackageInstalled,
+	}, {
+		Name:    "mysnap",
+		Version: "0.1",
+		State:   manager.PackageInstalled,
+	}})
+}
+
+func (s *SnapSuite) TestListAvailableNotSupported(c *gc.C) {
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	_, err := pacman.ListAvailable()
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *SnapSuite) TestPackageVersion(c *gc.C) {
+	const output = `Name  Version  Rev   Tracking  Publisher   Notes
+juju  2.6.6    8594  2.6       canonical✓  classic
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	commander := commands.NewSnapCommander()
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	version, err := pacman.PackageVersion("juju")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "2.6.6")
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, commander.PackageVersion("juju").Argv())
+}
+
+func (s *SnapSuite) TestPackageVersionNotInstalled(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "error: no matching snaps installed", Code: 1})
+
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	_, err := pacman.PackageVersion("foo")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SnapSuite) TestCandidateVersionTracking(c *gc.C) {
+	const output = `name:      lxd
+summary:   LXD - container and VM manager
+tracking:     4.0/stable/ubuntu-20.04
+channels:
+  latest/stable:    5.5-37534be 2022-08-27 (23537) 143MB -
+  latest/candidate: ↑
+  4.0/stable:       4.0.9-a29c6f1 2022-04-05 (22753) 71MB -
+  4.0/candidate:    ↑
+installed:          4.0.9-a29c6f1          (22753) 71MB -
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	commander := commands.NewSnapCommander()
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	_, err := pacman.CandidateVersion("lxd")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, commander.CandidateVersion("lxd").Argv())
+}
+
+func (s *SnapSuite) TestCandidateVersionClosedChannel(c *gc.C) {
+	const output = `name:      lxd
+tracking:     4.0/candidate
+channels:
+  latest/stable:    5.5-37534be 2022-08-27 (23537) 143MB -
+  4.0/stable:       4.0.9-a29c6f1 2022-04-05 (22753) 71MB -
+  4.0/candidate:    ↑
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	version, err := pacman.CandidateVersion("lxd")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "4.0.9-a29c6f1")
+}
+
+func (s *SnapSuite) TestCandidateVersionNotInstalled(c *gc.C) {
+	const output = `name:      juju
+channels:
+  stable:        2.6.6                     2019-07-31 (8594) 68MB classic
+  candidate:     ↑
+  edge:          2.7-beta1+develop-93d21f2 2019-08-19 (8756) 75MB classic
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	version, err := pacman.CandidateVersion("juju")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "2.6.6")
+}
+
+func (s *SnapSuite) TestInstallSpecRunsOneCommandPerPinnedSnap(c *gc.C) {
+	commander := commands.NewSnapCommander()
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	specs := []packaging.PackageSpec{
+		{Name: "jq"},
+		{Name: "lxd", Channel: "4.0/stable"},
+		{Name: "juju", Version: "8594"},
+		{Name: "yq"},
+	}
+	err := pacman.InstallSpec(specs...)
+	c.Assert(err, jc.ErrorIsNil)
+	cmds := s.exec.Commands()
+	c.Assert(cmds, gc.HasLen, 3)
+	c.Assert(cmds[0].Args, gc.DeepEquals, commander.InstallSpec(specs[1]).Argv())
+	c.Assert(cmds[1].Args, gc.DeepEquals, commander.InstallSpec(specs[2]).Argv())
+	c.Assert(cmds[2].Args, gc.DeepEquals, commander.InstallSpec(specs[0], specs[3]).Argv())
+}
+
+func (s *SnapSuite) TestInstallSpecWithoutSpecsRunsNoCommand(c *gc.C) {
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	err := pacman.InstallSpec()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.exec.Commands(), gc.HasLen, 0)
+}
+
+func (s *SnapSuite) TestIsInstalledAtLeastComparesRevisions(c *gc.C) {
+	const output = `Name  Version  Rev   Tracking  Publisher   Notes
+juju  2.6.6    8594  2.6       canonical✓  classic
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	pacman := manager.NewSnapPackageManagerWithExecutor(s.exec)
+	ok, err := pacman.IsInstalledAtLeast("juju", "8594")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+
+	ok, err = pacman.IsInstalledAtLeast("juju", "8756")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsFalse)
+}
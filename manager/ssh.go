@@ -0,0 +1,360 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/juju/packaging/v3/commands"
+)
+
+// DefaultSSHPort is the port on which hosts are reached by an SSHExecutor,
+// unless another one is given.
+const DefaultSSHPort = 22
+
+// SSHConfig holds the parameters with which an SSHExecutor connects to the
+// hosts and runs the commands on them.
+type SSHConfig struct {
+	// User is the user as which the executor logs in.
+	User string
+
+	// Auth are the methods with which the executor authenticates.
+	Auth []ssh.AuthMethod
+
+	// HostKeyCallback verifies the keys of the hosts; see the knownhosts
+	// package of golang.org/x/crypto/ssh.
+	HostKeyCallback ssh.HostKeyCallback
+
+	// Port is the port of hosts given without one, which is
+	// DefaultSSHPort if zero.
+	Port int
+
+	// Sudo is whether the commands are run as root through sudo, which
+	// must then not prompt for a password.
+	Sudo bool
+
+	// Timeout is the maximum time taken to connect to a host, which is
+	// unlimited if zero.
+	Timeout time.Duration
+}
+
+// Validate returns an error satisfying errors.IsNotValid if the config
+// lacks its user or host key callback.
+func (c SSHConfig) Validate() error {
+	switch {
+	case c.User == "":
+		return errors.NotValidf("SSH config without user")
+	case c.HostKeyCallback == nil:
+		return errors.NotValidf("SSH config without host key callback")
+	case c.Port < 0:
+		return errors.NotValidf("SSH port %d", c.Port)
+	}
+	return nil
+}
+
+// SSHExecutor runs commands on remote hosts over SSH. The Executor running
+// the commands on a given host is returned by Host, and is typically passed
+// to the constructor of a PackageManager, such as
+// NewAptPackageManagerWithExecutor.
+//
+// One connection is opened to each host, when running its first command,
+// and is reused by the commands which follow, until it is closed by Close
+// or drops.
+type SSHExecutor struct {
+	config SSHConfig
+
+	mu    sync.Mutex
+	conns map[string]*sshConn
+}
+
+// sshConn is the connection of an SSHExecutor to a host.
+type sshConn struct {
+	// mu serialises connecting to the host.
+	mu     sync.Mutex
+	client *ssh.Client
+}
+
+// NewSSHExecutor returns an SSHExecutor connecting to hosts with the given
+// config. An error satisfying errors.IsNotValid is returned if the config
+// is not valid.
+func NewSSHExecutor(config SSHConfig) (*SSHExecutor, error) {
+	if err := config.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if config.Port == 0 {
+		config.Port = DefaultSSHPort
+	}
+	return &SSHExecutor{
+		config: config,
+		conns:  make(map[string]*sshConn),
+	}, nil
+}
+
+// Host returns the Executor running the commands on the given host, which
+// is an address or a name, optionally followed by a port.
+func (e *SSHExecutor) Host(host string) Executor {
+	addr := host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		addr = net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(e.config.Port))
+	}
+	return sshHostExecutor{executor: e, addr: addr}
+}
+
+// Close closes the connections to all the hosts. Further commands open
+// new connections.
+func (e *SSHExecutor) Close() error {
+	e.mu.Lock()
+	conns := e.conns
+	e.conns = make(map[string]*sshConn)
+	e.mu.Unlock()
+
+	var lastErr error
+	for _, conn := range conns {
+		conn.mu.Lock()
+		if conn.client != nil {
+			if err := conn.client.Close(); err != nil {
+				lastErr = err
+			}
+			conn.client = nil
+		}
+		conn.mu.Unlock()
+	}
+	return errors.Trace(lastErr)
+}
+
+// client returns the client connected to the given address, connecting to
+// it if needed.
+func (e *SSHExecutor) client(ctx context.Context, addr string) (*ssh.Client, error) {
+	e.mu.Lock()
+	conn, ok := e.conns[addr]
+	if !ok {
+		conn = &sshConn{}
+		e.conns[addr] = conn
+	}
+	e.mu.Unlock()
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.client != nil {
+		return conn.client, nil
+	}
+	client, err := e.dial(ctx, addr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	conn.client = client
+	go func() {
+		// Forget the connection once it drops, so that the next command
+		// reconnects.
+		_ = client.Wait()
+		e.forget(addr, client)
+	}()
+	return client, nil
+}
+
+// forget discards the given client connected to the given address, if it
+// still is the client of the connection to that address.
+func (e *SSHExecutor) forget(addr string, client *ssh.Client) {
+	e.mu.Lock()
+	conn, ok := e.conns[addr]
+	e.mu.Unlock()
+	if !ok {
+		return
+	}
+	conn.mu.Lock()
+	if conn.client == client {
+		conn.client = nil
+	}
+	conn.mu.Unlock()
+	_ = client.Close()
+}
+
+// dial connects to the given address and authenticates.
+func (e *SSHExecutor) dial(ctx context.Context, addr string) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: e.config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errors.Annotatef(err, "connecting to %s", addr)
+	}
+	// Bound the handshake by the context and timeout too.
+	var deadline time.Time
+	if e.config.Timeout > 0 {
+		deadline = time.Now().Add(e.config.Timeout)
+	}
+	if ctxDeadline, ok := ctx.Deadline(); ok && (deadline.IsZero() || ctxDeadline.Before(deadline)) {
+		deadline = ctxDeadline
+	}
+	_ = netConn.SetDeadline(deadline)
+
+	conn, chans, reqs, err := ssh.NewClientConn(netConn, addr, &ssh.ClientConfig{
+		User:            e.config.User,
+		Auth:            e.config.Auth,
+		HostKeyCallback: e.config.HostKeyCallback,
+	})
+	if err != nil {
+		_ = netConn.Close()
+		return nil, errors.Annotatef(err, "connecting to %s", addr)
+	}
+	_ = netConn.SetDeadline(time.Time{})
+	return ssh.NewClient(conn, chans, reqs), nil
+}
+
+// session returns a new session on the connection to the given address.
+// A connection which is found to have dropped is replaced once.
+func (e *SSHExecutor) session(ctx context.Context, addr string) (*ssh.Session, error) {
+	for attempt := 0; ; attempt++ {
+		client, err := e.client(ctx, addr)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		session, err := client.NewSession()
+		if err == nil {
+			return session, nil
+		}
+		e.forget(addr, client)
+		if attempt > 0 {
+			return nil, errors.Annotatef(err, "opening session on %s", addr)
+		}
+	}
+}
+
+// sshHostExecutor is the Executor running commands on a host with an
+// SSHExecutor.
+type sshHostExecutor struct {
+	executor *SSHExecutor
+	addr     string
+}
+
+// Execute is defined on the Executor interface.
+func (e sshHostExecutor) Execute(ctx context.Context, cmd Command) (Result, error) {
+	if len(cmd.Args) == 0 {
+		return Result{}, errors.NotValidf("empty command")
+	}
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	session, err := e.executor.session(ctx, e.addr)
+	if err != nil {
+		return Result{}, errors.Trace(err)
+	}
+	defer func() { _ = session.Close() }()
+
+	// The environment is passed over stdin unless sshd accepts it, as
+	// sshd only accepts the variables it is configured to, and sudo
+	// resets the environment anyway.
+	env := cmd.Env
+	if !e.executor.config.Sudo && setenv(session, env) {
+		env = nil
+	}
+	stdin := cmd.Stdin
+	if len(env) > 0 {
+		preamble, err := envPreamble(env)
+		if err != nil {
+			return Result{}, errors.Trace(err)
+		}
+		stdin = append(preamble, cmd.Stdin...)
+	}
+	if stdin != nil {
+		session.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	session.Stdout, session.Stderr = &stdout, &stderr
+	if err := session.Start(e.remoteCommand(cmd, len(env) > 0)); err != nil {
+		return Result{}, errors.Annotatef(err, "running command on %s", e.addr)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Many sshd ignore signals, so closing the session is what ends
+		// the command, and the cancellation is reported without waiting
+		// for it to exit. Its output is discarded, as it may still be
+		// being written.
+		_ = session.Signal(ssh.SIGKILL)
+		_ = session.Close()
+		return Result{}, ctx.Err()
+	}
+
+	res := Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if exitErr, ok := err.(*ssh.ExitError); ok && exitErr.Signal() == "" {
+		res.Code = exitErr.ExitStatus()
+		return res, nil
+	}
+	if err != nil {
+		return res, errors.Annotatef(err, "running command on %s", e.addr)
+	}
+	return res, nil
+}
+
+// setenv sets the given environment variables for the command of the
+// given session, and returns whether sshd accepted all of them; see
+// AcceptEnv in sshd_config(5).
+func setenv(session *ssh.Session, env []string) bool {
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		if err := session.Setenv(key, value); err != nil {
+			return false
+		}
+	}
+	return len(env) > 0
+}
+
+// envPreamble returns the lines preceding the stdin of a command which
+// are read by envScript, setting the given environment variables. An
+// error satisfying errors.IsNotValid is returned if a variable cannot be
+// written on a line.
+func envPreamble(env []string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, kv := range env {
+		if key, _, ok := strings.Cut(kv, "="); !ok || key == "" || strings.ContainsAny(kv, "\n\x00") {
+			return nil, errors.NotValidf("environment variable %q", kv)
+		}
+		buf.WriteString(kv + "\n")
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// envScript exports the environment variables given on the lines of its
+// stdin up to the first empty one, and then runs its arguments with the
+// rest of its stdin. The read builtin reads no further than the line it
+// returns from a pipe.
+const envScript = `while IFS= read -r kv && [ -n "$kv" ]; do export "$kv" || exit 126; done; exec "$@"`
+
+// remoteCommand returns the shell command line running the given command
+// on the host, after sudo if configured. The command is run by envScript,
+// after sudo which resets the environment, if its environment variables
+// are passed over stdin, so that their values, such as the credentials of
+// proxies, are not visible in the command lines of the host.
+func (e sshHostExecutor) remoteCommand(cmd Command, envOnStdin bool) string {
+	var args []string
+	if e.executor.config.Sudo {
+		args = append(args, "sudo", "-n", "--")
+	}
+	if envOnStdin {
+		args = append(args, "sh", "-c", envScript, "sh")
+	}
+	args = append(args, cmd.Args...)
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = commands.ShellQuote(arg)
+	}
+	line := strings.Join(quoted, " ")
+	if cmd.Dir != "" {
+		line = "cd " + commands.ShellQuote(cmd.Dir) + " && " + line
+	}
+	return line
+}
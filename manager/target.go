@@ -0,0 +1,193 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package manager
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// TargetKind is the way in which commands are run inside a Target.
+type TargetKind string
+
+const (
+	// Chroot runs the commands with chroot(8) in the root directory of
+	// the target.
+	Chroot TargetKind = "chroot"
+
+	// Nspawn runs the commands with systemd-nspawn(1) in the root
+	// directory of the target.
+	Nspawn TargetKind = "systemd-nspawn"
+
+	// LXC runs the commands with "lxc exec" in the container of the
+	// target.
+	LXC TargetKind = "lxc"
+
+	// Docker runs the commands with "docker exec" in the container of the
+	// target.
+	Docker TargetKind = "docker"
+)
+
+// Target is a system, other than the one an Executor runs commands on,
+// whose packages are managed, such as the root filesystem of an image
+// being built or a container.
+//
+// The commands of a PackageManager running in the target see its files,
+// so that the configuration files they act on, such as
+// commands.AptConfFilePath, are those of the target. The files the
+// package managers edit directly, such as the apk repositories file, are
+// those below Root instead, when the target is run on the local system, or
+// are otherwise edited with commands run inside the target. The configuration of the target is written and
+// read by passing Root to the proxyconfig and reconcile packages, and to
+// the repository parsers of the config package.
+type Target struct {
+	// Kind is the way in which commands are run inside the target.
+	Kind TargetKind
+
+	// Root is the directory holding the root filesystem of the target.
+	// It is required for Chroot and Nspawn. For LXC and Docker, it is
+	// optional, and only used to edit files of the target directly.
+	Root string
+
+	// Container is the name of the container, for LXC and Docker.
+	Container string
+}
+
+// Validate returns an error satisfying errors.IsNotValid if the target
+// lacks its root or container, or is of an unknown kind.
+func (t Target) Validate() error {
+	if t.Root != "" && !filepath.IsAbs(t.Root) {
+		return errors.NotValidf("target root %q not absolute", t.Root)
+	}
+	switch t.Kind {
+	case Chroot, Nspawn:
+		if t.Root == "" {
+			return errors.NotValidf("%s target without root", t.Kind)
+		}
+	case LXC, Docker:
+		if t.Container == "" {
+			return errors.NotValidf("%s target without container", t.Kind)
+		}
+	default:
+		return errors.NotValidf("target kind %q", t.Kind)
+	}
+	return nil
+}
+
+// HostPath returns the path, on the system commands are run on, of the
+// given absolute path on the target, or an empty path if the target has
+// no Root.
+func (t Target) HostPath(p string) string {
+	if t.Root == "" {
+		return ""
+	}
+	return filepath.Join(t.Root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+// Wrap returns the command running the given command inside the target.
+//
+// systemd-nspawn, lxc and docker take the values of the environment
+// variables given by name only from their own environment, which keeps
+// them, and any proxy credentials among them, off their command line.
+func (t Target) Wrap(cmd Command) Command {
+	wrapped := Command{Stdin: cmd.Stdin}
+	switch t.Kind {
+	case Chroot:
+		// chroot keeps the environment, but always changes to the root
+		// directory of the target.
+		wrapped.Args = []string{"chroot", t.Root}
+		if cmd.Dir != "" {
+			wrapped.Args = append(wrapped.Args, "sh", "-c", `cd "$1" && shift && exec "$@"`, "sh", cmd.Dir)
+		}
+		wrapped.Env = cmd.Env
+	case Nspawn:
+		wrapped.Args = []string{"systemd-nspawn", "--quiet", "--pipe", "--directory=" + t.Root}
+		for _, env := range cmd.Env {
+			wrapped.Args = append(wrapped.Args, "--setenv="+envName(env))
+		}
+		wrapped.Env = cmd.Env
+		if cmd.Dir != "" {
+			wrapped.Args = append(wrapped.Args, "--chdir="+cmd.Dir)
+		}
+		wrapped.Args = append(wrapped.Args, "--")
+	case LXC:
+		wrapped.Args = []string{"lxc", "exec", t.Container}
+		for _, env := range cmd.Env {
+			wrapped.Args = append(wrapped.Args, "--env", envName(env))
+		}
+		wrapped.Env = cmd.Env
+		if cmd.Dir != "" {
+			wrapped.Args = append(wrapped.Args, "--cwd", cmd.Dir)
+		}
+		wrapped.Args = append(wrapped.Args, "--")
+	case Docker:
+		wrapped.Args = []string{"docker", "exec"}
+		if cmd.Stdin != nil {
+			wrapped.Args = append(wrapped.Args, "--interactive")
+		}
+		for _, env := range cmd.Env {
+			wrapped.Args = append(wrapped.Args, "--env", envName(env))
+		}
+		wrapped.Env = cmd.Env
+		if cmd.Dir != "" {
+			wrapped.Args = append(wrapped.Args, "--workdir", cmd.Dir)
+		}
+		wrapped.Args = append(wrapped.Args, t.Container)
+	}
+	wrapped.Args = append(wrapped.Args, cmd.Args...)
+	return wrapped
+}
+
+// envName returns the name of the given "key=value" environment variable.
+func envName(env string) string {
+	name, _, _ := strings.Cut(env, "=")
+	return name
+}
+
+// NewTargetExecutor returns an Executor which runs the commands inside the
+// given target, with the given Executor. A PackageManager constructed with
+// it manages the packages of the target. An error satisfying
+// errors.IsNotValid is returned if the target is not valid.
+func NewTargetExecutor(target Target, executor Executor) (Executor, error) {
+	if err := target.Validate(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return targetExecutor{target: target, executor: executor}, nil
+}
+
+// targetExecutor is the Executor running commands inside a Target.
+type targetExecutor struct {
+	target   Target
+	executor Executor
+}
+
+// Execute is defined on the Executor interface.
+func (e targetExecutor) Execute(ctx context.Context, cmd Command) (Result, error) {
+	if len(cmd.Args) == 0 {
+		return Result{}, errors.NotValidf("empty command")
+	}
+	return e.executor.Execute(ctx, e.target.Wrap(cmd))
+}
+
+// hostPath returns the path of the given path on the target system which
+// is edited directly by a PackageManager running its commands with the
+// given Executor, or an empty path if it cannot be, as the commands are not
+// run on the local system.
+func hostPath(executor Executor, p string) string {
+	switch e := executor.(type) {
+	case execExecutor:
+		return p
+	case targetExecutor:
+		// The Root of the target is a directory of the system the inner
+		// executor runs commands on.
+		if _, ok := e.executor.(execExecutor); ok {
+			return e.target.HostPath(p)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/juju/packaging/v3/manager"
+)
+
+// Response is the scripted reply of a FakeExecutor to a command.
+type Response struct {
+	// Stdout and Stderr are the output of the command.
+	Stdout string
+	Stderr string
+
+	// Code is the exit code of the command.
+	Code int
+
+	// Err, if set, is returned instead of a result, as if the command
+	// could not be run at all.
+	Err error
+}
+
+// FakeExecutor is a manager.Executor which runs no commands. It records
+// the commands it is asked to run and replies to them with the scripted
+// responses, in order. The last response is repeated once the others are
+// used up, and commands succeed without output if there are none. It is
+// safe for concurrent use.
+type FakeExecutor struct {
+	mu        sync.Mutex
+	responses []Response
+	last      *Response
+	commands  []manager.Command
+}
+
+// NewFakeExecutor returns a FakeExecutor replying with the given
+// responses.
+func NewFakeExecutor(responses ...Response) *FakeExecutor {
+	return &FakeExecutor{responses: responses}
+}
+
+// Push appends the given responses to those the executor replies with.
+func (e *FakeExecutor) Push(responses ...Response) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.responses = append(e.responses, responses...)
+}
+
+// Execute is defined on the manager.Executor interface.
+func (e *FakeExecutor) Execute(ctx context.Context, cmd manager.Command) (manager.Result, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.commands = append(e.commands, cmd)
+	if err := ctx.Err(); err != nil {
+		return manager.Result{}, err
+	}
+	if len(e.responses) > 0 {
+		e.last, e.responses = &e.responses[0], e.responses[1:]
+	}
+	if e.last == nil {
+		return manager.Result{}, nil
+	}
+	resp := *e.last
+	if resp.Err != nil {
+		return manager.Result{}, resp.Err
+	}
+	return manager.Result{
+		Stdout: []byte(resp.Stdout),
+		Stderr: []byte(resp.Stderr),
+		Code:   resp.Code,
+	}, nil
+}
+
+// Commands returns the commands the executor was asked to run.
+func (e *FakeExecutor) Commands() []manager.Command {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]manager.Command(nil), e.commands...)
+}
+
+// CommandLines returns the arguments of each of the commands the executor
+// was asked to run, separated by spaces.
+func (e *FakeExecutor) CommandLines() []string {
+	var res []string
+	for _, cmd := range e.Commands() {
+		res = append(res, cmd.String())
+	}
+	return res
+}
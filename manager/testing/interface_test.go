@@ -10,3 +10,4 @@ import (
 )
 
 var _ manager.PackageManager = &testing.MockPackageManager{}
+var _ manager.PackageManagerContext = &testing.MockPackageManager{}
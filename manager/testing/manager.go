@@ -6,11 +6,19 @@
 // interface which always returns positive outcomes and a nil error.
 package testing
 
-import "github.com/juju/proxy"
+import (
+	"context"
+
+	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/manager"
+	"github.com/juju/packaging/v3/version"
+)
 
 // MockPackageManager is a struct which always returns a positive outcome,
 // constant ProxySettings and a nil error.
-// It satisfies the PackageManager interface.
+// It satisfies the PackageManager and PackageManagerContext interfaces.
 type MockPackageManager struct {
 }
 
@@ -34,6 +42,11 @@ func (pm *MockPackageManager) Install(...string) error {
 	return nil
 }
 
+// InstallSpec is defined on the PackageManager interface.
+func (pm *MockPackageManager) InstallSpec(...packaging.PackageSpec) error {
+	return nil
+}
+
 // Remove is defined on the PackageManager interface.
 func (pm *MockPackageManager) Remove(...string) error {
 	return nil
@@ -54,6 +67,26 @@ func (pm *MockPackageManager) IsInstalled(string) bool {
 	return true
 }
 
+// PackageVersion is defined on the PackageManager interface.
+func (pm *MockPackageManager) PackageVersion(string) (string, error) {
+	return "1.0", nil
+}
+
+// CandidateVersion is defined on the PackageManager interface.
+func (pm *MockPackageManager) CandidateVersion(string) (string, error) {
+	return "1.0", nil
+}
+
+// VersionScheme is defined on the PackageManager interface.
+func (pm *MockPackageManager) VersionScheme() version.Scheme {
+	return version.DebianScheme
+}
+
+// IsInstalledAtLeast is defined on the PackageManager interface.
+func (pm *MockPackageManager) IsInstalledAtLeast(string, string) (bool, error) {
+	return true, nil
+}
+
 // AddRepository is defined on the PackageManager interface.
 func (pm *MockPackageManager) AddRepository(string) error {
 	return nil
@@ -64,6 +97,16 @@ func (pm *MockPackageManager) RemoveRepository(string) error {
 	return nil
 }
 
+// ListInstalled is defined on the PackageManager interface.
+func (pm *MockPackageManager) ListInstalled() ([]manager.PackageInfo, error) {
+	return nil, nil
+}
+
+// ListAvailable is defined on the PackageManager interface.
+func (pm *MockPackageManager) ListAvailable() ([]manager.PackageInfo, error) {
+	return nil, nil
+}
+
 // Cleanup is defined on the PackageManager interface.
 func (pm *MockPackageManager) Cleanup() error {
 	return nil
@@ -84,3 +127,98 @@ func (pm *MockPackageManager) GetProxySettings() (proxy.Settings, error) {
 func (pm *MockPackageManager) SetProxy(proxy.Settings) error {
 	return nil
 }
+
+// InstallPrerequisiteContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) InstallPrerequisiteContext(context.Context) error {
+	return pm.InstallPrerequisite()
+}
+
+// UpdateContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) UpdateContext(context.Context) error {
+	return pm.Update()
+}
+
+// UpgradeContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) UpgradeContext(context.Context) error {
+	return pm.Upgrade()
+}
+
+// InstallContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) InstallContext(_ context.Context, packs ...string) error {
+	return pm.Install(packs...)
+}
+
+// InstallSpecContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) InstallSpecContext(_ context.Context, specs ...packaging.PackageSpec) error {
+	return pm.InstallSpec(specs...)
+}
+
+// RemoveContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) RemoveContext(_ context.Context, packs ...string) error {
+	return pm.Remove(packs...)
+}
+
+// PurgeContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) PurgeContext(_ context.Context, packs ...string) error {
+	return pm.Purge(packs...)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) SearchContext(_ context.Context, pack string) (bool, error) {
+	return pm.Search(pack)
+}
+
+// IsInstalledContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) IsInstalledContext(_ context.Context, pack string) bool {
+	return pm.IsInstalled(pack)
+}
+
+// PackageVersionContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) PackageVersionContext(_ context.Context, pack string) (string, error) {
+	return pm.PackageVersion(pack)
+}
+
+// CandidateVersionContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) CandidateVersionContext(_ context.Context, pack string) (string, error) {
+	return pm.CandidateVersion(pack)
+}
+
+// IsInstalledAtLeastContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) IsInstalledAtLeastContext(_ context.Context, pack, minVersion string) (bool, error) {
+	return pm.IsInstalledAtLeast(pack, minVersion)
+}
+
+// AddRepositoryContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) AddRepositoryContext(_ context.Context, repo string) error {
+	return pm.AddRepository(repo)
+}
+
+// RemoveRepositoryContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) RemoveRepositoryContext(_ context.Context, repo string) error {
+	return pm.RemoveRepository(repo)
+}
+
+// ListInstalledContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) ListInstalledContext(context.Context) ([]manager.PackageInfo, error) {
+	return pm.ListInstalled()
+}
+
+// ListAvailableContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) ListAvailableContext(context.Context) ([]manager.PackageInfo, error) {
+	return pm.ListAvailable()
+}
+
+// CleanupContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) CleanupContext(context.Context) error {
+	return pm.Cleanup()
+}
+
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) GetProxySettingsContext(context.Context) (proxy.Settings, error) {
+	return pm.GetProxySettings()
+}
+
+// SetProxyContext is defined on the PackageManagerContext interface.
+func (pm *MockPackageManager) SetProxyContext(_ context.Context, settings proxy.Settings) error {
+	return pm.SetProxy(settings)
+}
@@ -5,11 +5,11 @@
 package manager
 
 import (
-	"fmt"
-	"os/exec"
+	"context"
 	"strings"
 
 	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
 	"github.com/juju/proxy"
 )
 
@@ -25,10 +25,25 @@ type yum struct {
 
 // NewYumPackageManager returns a PackageManager for yum-based systems.
 func NewYumPackageManager() PackageManager {
+	return NewYumPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewYumPackageManagerWithExecutor returns a PackageManager for yum-based
+// systems, which runs its commands with the given Executor.
+func NewYumPackageManagerWithExecutor(executor Executor) PackageManager {
 	manager := &yum{
 		basePackageManager: basePackageManager{
-			cmder:       commands.NewYumPackageCommander(),
-			retryPolicy: DefaultRetryPolicy(),
+			executor:       executor,
+			cmder:          commands.NewYumPackageCommander(),
+			commander:      commands.NewYumCommander(),
+			proxyConfig:    "yum",
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parseYumList,
+			parseAvailable: parseYumList,
+			parseVersion:   parseRPMQueryVersion,
+			parseCandidate: parseYumInfoCandidate,
+			specFields:     specFields{version: true, architecture: true},
+			versionScheme:  version.RPMScheme,
 		},
 	}
 	manager.basePackageManager.retryable = manager
@@ -37,7 +52,15 @@ func NewYumPackageManager() PackageManager {
 
 // Search is defined on the PackageManager interface.
 func (yum *yum) Search(pack string) (bool, error) {
-	_, code, err := RunCommandWithRetry(yum.cmder.SearchCmd(pack), yum, yum.retryPolicy, nil)
+	return yum.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (yum *yum) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := yum.validatePackages(pack); err != nil {
+		return false, err
+	}
+	_, code, err := yum.runCommand(ctx, yum.commander.Search(pack), yum, nil)
 
 	// yum list package returns 1 when it cannot find the package.
 	if code == 1 {
@@ -48,23 +71,19 @@ func (yum *yum) Search(pack string) (bool, error) {
 
 // GetProxySettings is defined on the PackageManager interface.
 func (yum *yum) GetProxySettings() (proxy.Settings, error) {
-	var res proxy.Settings
-
-	args := strings.Fields(yum.cmder.GetProxyCmd())
-	if len(args) <= 1 {
-		return proxy.Settings{}, fmt.Errorf("expected at least 2 arguments, got %d %v", len(args), args)
-	}
+	return yum.GetProxySettingsContext(context.Background())
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
-	out, err := CommandOutput(cmd)
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+func (yum *yum) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	var res proxy.Settings
 
+	out, _, err := yum.queryProxy(ctx, yum.commander.GetProxy())
 	if err != nil {
-		logger.Errorf("command failed: %v\nargs: %#v\n%s",
-			err, args, string(out))
-		return res, fmt.Errorf("command failed: %v", err)
+		return res, err
 	}
 
-	for _, match := range strings.Split(string(out), "\n") {
+	for _, match := range strings.Split(out, "\n") {
 		fields := strings.Split(match, "=")
 		if len(fields) != 2 {
 			continue
@@ -82,6 +101,94 @@ func (yum *yum) GetProxySettings() (proxy.Settings, error) {
 	return res, nil
 }
 
+// parseYumList parses the output of "yum list", which looks like:
+//
+//	Installed Packages
+//	bash.x86_64                     4.2.46-34.el7           @anaconda
+//	a-package-with-a-very-long-name.noarch
+//	                                1.0-1.el7               @epel
+//	Available Packages
+//	zsh.x86_64                      5.0.2-34.el7_8.2        updates
+//
+// Entries whose name is too long for the first column are wrapped onto
+// the following line.
+func parseYumList(output string) []PackageInfo {
+	var (
+		res     []PackageInfo
+		state   = PackageInstalled
+		pending []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		switch strings.TrimSpace(line) {
+		case "Installed Packages", "Extra Packages":
+			state, pending = PackageInstalled, nil
+			continue
+		case "Available Packages":
+			state, pending = PackageAvailable, nil
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Package entries always start with a "name.arch" identifier;
+		// anything else is informational output from yum itself.
+		if len(pending) == 0 && (!strings.Contains(fields[0], ".") || strings.HasSuffix(fields[0], ":")) {
+			continue
+		}
+		pending = append(pending, fields...)
+		if len(pending) < 3 {
+			continue
+		}
+
+		name, arch := splitNameArch(pending[0])
+		res = append(res, PackageInfo{
+			Name:         name,
+			Version:      pending[1],
+			Architecture: arch,
+			Repository:   strings.TrimPrefix(pending[2], "@"),
+			State:        state,
+		})
+		pending = nil
+	}
+	return res
+}
+
+// parseYumInfoCandidate parses the output of "yum info", which lists the
+// installed version of a package followed by any newer versions which are
+// available:
+//
+//	Installed Packages
+//	Name        : bash
+//	Version     : 4.2.46
+//	Release     : 34.el7
+//
+//	Available Packages
+//	Name        : bash
+//	Epoch       : 1
+//	Version     : 4.2.46
+//	Release     : 35.el7_9
+//
+// The first available version is preferred over the installed one.
+func parseYumInfoCandidate(output string) string {
+	block := output
+	if i := strings.Index(output, "Available Packages"); i >= 0 {
+		block = output[i:]
+	}
+	v, release := infoField(block, "Version"), infoField(block, "Release")
+	if v == "" {
+		return ""
+	}
+	if release != "" {
+		v += "-" + release
+	}
+	if epoch := infoField(block, "Epoch"); epoch != "" && epoch != "0" {
+		v = epoch + ":" + v
+	}
+	return v
+}
+
 func (*yum) IsRetryable(code int, output string) bool {
 	return code == YumExitCode
 }
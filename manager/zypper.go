@@ -4,13 +4,12 @@
 package manager
 
 import (
-	"bytes"
-	"fmt"
-	"os/exec"
+	"context"
 	"regexp"
 	"strings"
 
 	"github.com/juju/packaging/v3/commands"
+	"github.com/juju/packaging/v3/version"
 	"github.com/juju/proxy"
 )
 
@@ -23,20 +22,44 @@ type zypper struct {
 	basePackageManager
 }
 
+// NewZypperPackageManager returns a PackageManager for zypper-based systems.
 func NewZypperPackageManager() PackageManager {
+	return NewZypperPackageManagerWithExecutor(NewExecExecutor())
+}
+
+// NewZypperPackageManagerWithExecutor returns a PackageManager for
+// zypper-based systems, which runs its commands with the given Executor.
+func NewZypperPackageManagerWithExecutor(executor Executor) PackageManager {
 	// Note: this does not override the default retrier, as Zypper doesn't have
 	// any retryable CLI command codes.
 	return &zypper{
 		basePackageManager{
-			cmder:       commands.NewZypperPackageCommander(),
-			retryPolicy: DefaultRetryPolicy(),
+			executor:       executor,
+			cmder:          commands.NewZypperPackageCommander(),
+			commander:      commands.NewZypperCommander(),
+			proxyConfig:    "zypper",
+			retryPolicy:    DefaultRetryPolicy(),
+			parseInstalled: parseZypperPackages,
+			parseAvailable: parseZypperPackages,
+			parseVersion:   parseRPMQueryVersion,
+			parseCandidate: parseZypperInfoCandidate,
+			specFields:     specFields{version: true, architecture: true},
+			versionScheme:  version.RPMScheme,
 		},
 	}
 }
 
 // Search is defined on the PackageManager interface.
 func (zypper *zypper) Search(pack string) (bool, error) {
-	_, code, err := RunCommandWithRetry(zypper.cmder.SearchCmd(pack), zypper, zypper.retryPolicy, nil)
+	return zypper.SearchContext(context.Background(), pack)
+}
+
+// SearchContext is defined on the PackageManagerContext interface.
+func (zypper *zypper) SearchContext(ctx context.Context, pack string) (bool, error) {
+	if err := zypper.validatePackages(pack); err != nil {
+		return false, err
+	}
+	_, code, err := zypper.runCommand(ctx, zypper.commander.Search(pack), zypper, nil)
 
 	// zypper search returns 104 when it cannot find the package.
 	if code == 104 {
@@ -47,33 +70,100 @@ func (zypper *zypper) Search(pack string) (bool, error) {
 
 // GetProxySettings is defined on the PackageManager interface.
 func (zypper *zypper) GetProxySettings() (proxy.Settings, error) {
-	var res proxy.Settings
-
-	args := strings.Fields(zypper.cmder.GetProxyCmd())
-	if len(args) <= 1 {
-		return proxy.Settings{}, fmt.Errorf("expected at least 2 arguments, got %d %v", len(args), args)
-	}
+	return zypper.GetProxySettingsContext(context.Background())
+}
 
-	cmd := exec.Command(args[0], args[1:]...)
-	out, err := CommandOutput(cmd)
+// GetProxySettingsContext is defined on the PackageManagerContext interface.
+func (zypper *zypper) GetProxySettingsContext(ctx context.Context) (proxy.Settings, error) {
+	var res proxy.Settings
 
+	out, _, err := zypper.queryProxy(ctx, zypper.commander.GetProxy())
 	if err != nil {
-		logger.Errorf("command failed: %v\nargs: %#v\n%s",
-			err, args, string(out))
-		return res, fmt.Errorf("command failed: %v", err)
+		return res, err
 	}
 
-	output := string(bytes.Join(zypperProxyRE.FindAll(out, -1), []byte("\n")))
+	output := strings.Join(zypperProxyRE.FindAllString(out, -1), "\n")
 	for _, match := range zypperProxyRE.FindAllStringSubmatch(output, -1) {
+		value := strings.Trim(strings.TrimSpace(match[2]), `"`)
 		switch strings.ToLower(match[1]) {
 		case "http_proxy":
-			res.Http = match[2]
+			res.Http = value
 		case "https_proxy":
-			res.Https = match[2]
+			res.Https = value
 		case "ftp_proxy":
-			res.Ftp = match[2]
+			res.Ftp = value
+		case "no_proxy":
+			res.NoProxy = strings.Join(commands.NoProxyHosts(value), ",")
 		}
 	}
 
 	return res, nil
 }
+
+// parseZypperInfoCandidate parses the candidate version of a package out of
+// the output of "zypper info", which reports the best available version:
+//
+//	Information for package bash:
+//	-----------------------------
+//	Repository     : repo-update
+//	Name           : bash
+//	Version        : 4.4-lp152.9.13
+func parseZypperInfoCandidate(output string) string {
+	return infoField(output, "Version")
+}
+
+// parseZypperPackages parses the table output of "zypper packages", which
+// looks like:
+//
+//	S  | Repository | Name | Version    | Arch
+//	---+------------+------+------------+-------
+//	i+ | repo-oss   | bash | 4.4-9.10.1 | x86_64
+//	v  | repo-oss   | zsh  | 5.6-7.5.1  | x86_64
+func parseZypperPackages(output string) []PackageInfo {
+	var (
+		res     []PackageInfo
+		columns map[string]int
+	)
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.Contains(line, "|") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		// The first row of the table is the header, which tells us the
+		// position of each column.
+		if columns == nil {
+			columns = make(map[string]int)
+			for i, field := range fields {
+				columns[strings.ToLower(field)] = i
+			}
+			continue
+		}
+		column := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) {
+				return fields[i]
+			}
+			return ""
+		}
+
+		name := column("name")
+		if name == "" {
+			continue
+		}
+		state := PackageAvailable
+		if strings.HasPrefix(column("s"), "i") {
+			state = PackageInstalled
+		}
+		res = append(res, PackageInfo{
+			Name:         name,
+			Version:      column("version"),
+			Architecture: column("arch"),
+			Repository:   column("repository"),
+			State:        state,
+		})
+	}
+	return res
+}
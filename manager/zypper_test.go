@@ -4,8 +4,6 @@
 package manager_test
 
 import (
-	"strings"
-
 	"github.com/juju/proxy"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
@@ -13,24 +11,29 @@ import (
 
 	"github.com/juju/packaging/v3/commands"
 	"github.com/juju/packaging/v3/manager"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
 )
 
 var _ = gc.Suite(&ZypperSuite{})
 
 type ZypperSuite struct {
 	testing.IsolationSuite
-	paccmder commands.PackageCommander
-	pacman   manager.PackageManager
+	paccmder  commands.PackageCommander
+	commander commands.Commander
+	exec      *managertesting.FakeExecutor
+	pacman    manager.PackageManager
 }
 
 func (s *ZypperSuite) SetUpSuite(c *gc.C) {
 	s.IsolationSuite.SetUpSuite(c)
 	s.paccmder = commands.NewZypperPackageCommander()
-	s.pacman = manager.NewZypperPackageManager()
+	s.commander = commands.NewZypperCommander()
 }
 
 func (s *ZypperSuite) SetUpTest(c *gc.C) {
 	s.IsolationSuite.SetUpTest(c)
+	s.exec = managertesting.NewFakeExecutor()
+	s.pacman = manager.NewZypperPackageManagerWithExecutor(s.exec)
 }
 
 func (s *ZypperSuite) TearDownTest(c *gc.C) {
@@ -42,13 +45,13 @@ func (s *ZypperSuite) TearDownSuite(c *gc.C) {
 }
 
 func (s *ZypperSuite) TestGetProxySettingsEmpty(c *gc.C) {
-	cmdChan := s.HookCommandOutput(&manager.CommandOutput, []byte{}, nil)
+	s.exec.Push(managertesting.Response{})
 
 	out, err := s.pacman.GetProxySettings()
 	c.Assert(err, jc.ErrorIsNil)
 
-	cmd := <-cmdChan
-	c.Assert(cmd.Args, gc.DeepEquals, strings.Fields(s.paccmder.GetProxyCmd()))
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.GetProxy().Argv())
 	c.Assert(out, gc.Equals, proxy.Settings{})
 }
 
@@ -58,13 +61,13 @@ http_proxy = 10.0.3.1:3142
 https_proxy = false
 magic_proxy = narnia
 ftp_proxy = none`
-	cmdChan := s.HookCommandOutput(&manager.CommandOutput, []byte(expected), nil)
+	s.exec.Push(managertesting.Response{Stdout: expected})
 
 	out, err := s.pacman.GetProxySettings()
 	c.Assert(err, gc.IsNil)
 
-	cmd := <-cmdChan
-	c.Assert(cmd.Args, gc.DeepEquals, strings.Fields(s.paccmder.GetProxyCmd()))
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.GetProxy().Argv())
 
 	c.Assert(out, gc.Equals, proxy.Settings{
 		Http:  "10.0.3.1:3142",
@@ -73,6 +76,20 @@ ftp_proxy = none`
 	})
 }
 
+func (s *ZypperSuite) TestGetProxySettingsNoProxy(c *gc.C) {
+	const expected = `HTTP_PROXY="10.0.3.1:3142"
+NO_PROXY="localhost, 127.0.0.1"`
+	s.exec.Push(managertesting.Response{Stdout: expected})
+
+	out, err := s.pacman.GetProxySettings()
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(out, gc.Equals, proxy.Settings{
+		Http:    "10.0.3.1:3142",
+		NoProxy: "localhost,127.0.0.1",
+	})
+}
+
 func (s *ZypperSuite) TestProxySettingsRoundTrip(c *gc.C) {
 	initial := proxy.Settings{
 		Http:  "some-proxy.local:8080",
@@ -81,13 +98,131 @@ func (s *ZypperSuite) TestProxySettingsRoundTrip(c *gc.C) {
 	}
 
 	expected := s.paccmder.ProxyConfigContents(initial)
-	cmdChan := s.HookCommandOutput(&manager.CommandOutput, []byte(expected), nil)
+	s.exec.Push(managertesting.Response{Stdout: expected})
 
 	result, err := s.pacman.GetProxySettings()
 	c.Assert(err, gc.IsNil)
 
-	cmd := <-cmdChan
-	c.Assert(cmd.Args, gc.DeepEquals, strings.Fields(s.paccmder.GetProxyCmd()))
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.GetProxy().Argv())
 
 	c.Assert(result, gc.Equals, initial)
 }
+
+func (s *ZypperSuite) TestProxySettingsRoundTripNoProxy(c *gc.C) {
+	initial := proxy.Settings{
+		Http:    "some-proxy.local:8080",
+		Https:   "some-secure-proxy.local:9696",
+		NoProxy: "localhost,.example.com,10.0.0.0/8",
+	}
+
+	expected := s.paccmder.ProxyConfigContents(initial)
+	s.exec.Push(managertesting.Response{Stdout: expected})
+
+	result, err := s.pacman.GetProxySettings()
+	c.Assert(err, gc.IsNil)
+
+	c.Assert(result, gc.Equals, initial)
+}
+
+func (s *ZypperSuite) TestListInstalled(c *gc.C) {
+	const output = `S  | Repository     | Name      | Version           | Arch
+---+----------------+-----------+-------------------+-------
+i+ | repo-oss       | bash      | 4.4-lp152.9.10    | x86_64
+i  | repo-update    | libzypp   | 17.25.6-lp152.2.1 | x86_64
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	packs, err := s.pacman.ListInstalled()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.ListInstalled().Argv())
+	c.Assert(packs, gc.DeepEquals, []manager.PackageInfo{{
+		Name:         "bash",
+		Version:      "4.4-lp152.9.10",
+		Architecture: "x86_64",
+		Repository:   "repo-oss",
+		State:        manager.PackageInstalled,
+	}, {
+		Name:         "libzypp",
+		Version:      "17.25.6-lp152.2.1",
+		Architecture: "x86_64",
+		Repository:   "repo-update",
+		State:        manager.PackageInstalled,
+	}})
+}
+
+func (s *ZypperSuite) TestListAvailable(c *gc.C) {
+	const output = `S  | Repository     | Name      | Version           | Arch
+---+----------------+-----------+-------------------+-------
+v  | repo-update    | bash      | 4.4-lp152.9.13    | x86_64
+i+ | repo-oss       | bash      | 4.4-lp152.9.10    | x86_64
+   | repo-oss       | zsh       | 5.6-lp152.4.3     | x86_64
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	packs, err := s.pacman.ListAvailable()
+	c.Assert(err, jc.ErrorIsNil)
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.ListAvailable().Argv())
+	c.Assert(packs, gc.DeepEquals, []manager.PackageInfo{{
+		Name:         "bash",
+		Version:      "4.4-lp152.9.13",
+		Architecture: "x86_64",
+		Repository:   "repo-update",
+		State:        manager.PackageAvailable,
+	}, {
+		Name:         "bash",
+		Version:      "4.4-lp152.9.10",
+		Architecture: "x86_64",
+		Repository:   "repo-oss",
+		State:        manager.PackageInstalled,
+	}, {
+		Name:         "zsh",
+		Version:      "5.6-lp152.4.3",
+		Architecture: "x86_64",
+		Repository:   "repo-oss",
+		State:        manager.PackageAvailable,
+	}})
+}
+
+func (s *ZypperSuite) TestPackageVersion(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "0:4.4-lp152.9.10\n"})
+
+	version, err := s.pacman.PackageVersion("bash")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "4.4-lp152.9.10")
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.PackageVersion("bash").Argv())
+}
+
+func (s *ZypperSuite) TestCandidateVersion(c *gc.C) {
+	const output = `Information for package bash:
+-----------------------------
+Repository     : repo-update
+Name           : bash
+Version        : 4.4-lp152.9.13
+Arch           : x86_64
+Vendor         : openSUSE
+Installed      : Yes
+Status         : out-of-date (version 4.4-lp152.9.10 installed)
+`
+	s.exec.Push(managertesting.Response{Stdout: output})
+
+	version, err := s.pacman.CandidateVersion("bash")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(version, gc.Equals, "4.4-lp152.9.13")
+
+	cmd := s.exec.Commands()[0]
+	c.Assert(cmd.Args, gc.DeepEquals, s.commander.CandidateVersion("bash").Argv())
+}
+
+func (s *ZypperSuite) TestCandidateVersionNotFound(c *gc.C) {
+	s.exec.Push(managertesting.Response{Stdout: "package 'foo' not found.\n"})
+
+	_, err := s.pacman.CandidateVersion("foo")
+	c.Assert(err, gc.ErrorMatches, `package "foo" not found`)
+}
@@ -5,7 +5,12 @@
 package packaging
 
 import (
+	"path"
+	"regexp"
+	"strings"
 	"text/template"
+
+	"github.com/juju/errors"
 )
 
 // PackagePreferences is a set of packaging preferences associated to a
@@ -13,11 +18,13 @@ import (
 // On apt-based systems, they are apt_preferences(5) compatible preferences for an
 // apt source. It can be used to override the default priority for the source.
 // Path where the file will be created (usually in /etc/apt/preferences.d/).
+// Several preferences with the same Path are rendered as the stanzas of a
+// single file.
 type PackagePreferences struct {
 	Path        string // the file the prefs will be written at
 	Explanation string // a short explanation for the preference
-	Package     string // the name of the package the preference applies to
-	Pin         string // a pin on a certain source
+	Package     string // the packages the preference applies to, see MatchesPackage
+	Pin         string // a pin on a certain source, see Pin
 	Priority    int    // the priority of that source
 }
 
@@ -26,3 +33,128 @@ type PackagePreferences struct {
 func (p *PackagePreferences) RenderPreferenceFile(fileTemplate *template.Template) (string, error) {
 	return renderTemplate(fileTemplate, p)
 }
+
+// PackagePatterns returns the patterns of the Package field, which is a
+// space separated list of package names, glob patterns such as "lxd*" and
+// regular expressions enclosed in slashes such as "/^lxd(-client)?$/", as
+// understood by apt. An empty Package field matches all packages.
+func (p *PackagePreferences) PackagePatterns() []string {
+	patterns := strings.Fields(p.Package)
+	if len(patterns) == 0 {
+		return []string{"*"}
+	}
+	return patterns
+}
+
+// MatchesPackage returns whether the preferences apply to the package of
+// the given name. An error satisfying errors.IsNotValid is returned for
+// malformed patterns.
+func (p *PackagePreferences) MatchesPackage(name string) (bool, error) {
+	for _, pattern := range p.PackagePatterns() {
+		matched, err := matchPackagePattern(pattern, name)
+		if err != nil {
+			return false, err
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchPackagePattern returns whether the given package name matches the
+// given name, glob pattern or regular expression enclosed in slashes.
+func matchPackagePattern(pattern, name string) (bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, errors.NotValidf("package regular expression %q", pattern)
+		}
+		return re.MatchString(name), nil
+	}
+	matched, err := path.Match(pattern, name)
+	if err != nil {
+		return false, errors.NotValidf("package pattern %q", pattern)
+	}
+	return matched, nil
+}
+
+// PinType is the type of a Pin.
+type PinType string
+
+const (
+	// PinRelease pins the packages of the releases with the properties
+	// of a ReleasePin.
+	PinRelease PinType = "release"
+
+	// PinOrigin pins the packages from a host name.
+	PinOrigin PinType = "origin"
+
+	// PinVersion pins the versions of packages matching a glob pattern.
+	PinVersion PinType = "version"
+)
+
+// ReleasePin selects releases by the fields of their Release files. Empty
+// fields match any release.
+type ReleasePin struct {
+	Archive      string // the archive or suite, such as "jammy-updates" (a=)
+	Codename     string // the codename, such as "jammy" (n=)
+	Version      string // the release version, such as "22.04" (v=)
+	Origin       string // the origin, such as "Ubuntu" (o=)
+	Label        string // the label, such as "Ubuntu" (l=)
+	Component    string // the component, such as "main" (c=)
+	Architecture string // the architecture, such as "amd64" (b=)
+}
+
+// Pin is the structured form of the Pin field of PackagePreferences, as
+// described in apt_preferences(5). Its String method returns the value of
+// the field, which ParseAptPin of the config package parses again.
+type Pin struct {
+	// Type is the type of the pin.
+	Type PinType
+
+	// Release are the properties of the pinned releases of release pins.
+	Release ReleasePin
+
+	// Origin is the host name of origin pins. It is empty for locally
+	// installed packages.
+	Origin string
+
+	// Version is the glob pattern of the pinned versions of version pins,
+	// such as "1.2*".
+	Version string
+}
+
+// String returns the pin in the format of apt_preferences(5).
+func (p Pin) String() string {
+	switch p.Type {
+	case PinOrigin:
+		if p.Origin == "" {
+			return `origin ""`
+		}
+		return "origin " + p.Origin
+	case PinVersion:
+		return "version " + p.Version
+	}
+
+	var fields []string
+	for _, field := range []struct {
+		key, value string
+	}{
+		{"a", p.Release.Archive},
+		{"n", p.Release.Codename},
+		{"v", p.Release.Version},
+		{"o", p.Release.Origin},
+		{"l", p.Release.Label},
+		{"c", p.Release.Component},
+		{"b", p.Release.Architecture},
+	} {
+		if field.value != "" {
+			fields = append(fields, field.key+"="+field.value)
+		}
+	}
+	if len(fields) == 0 {
+		return "release *"
+	}
+	return "release " + strings.Join(fields, ",")
+}
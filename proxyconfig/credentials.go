@@ -0,0 +1,135 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package proxyconfig
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3/commands"
+)
+
+const (
+	// AptProxyAuthFile is the auth.conf(5) file in which the credentials
+	// of authenticated proxies are written for apt.
+	AptProxyAuthFile = "/etc/apt/auth.conf.d/90-juju-proxy.conf"
+
+	// ZypperProxyCredentialsFile is the file in which the credentials of
+	// an authenticated proxy are written for zypper.
+	ZypperProxyCredentialsFile = commands.OpenSUSECredentialsDir + "/juju-proxy"
+
+	// secretPerm is the mode of the files holding credentials.
+	secretPerm = 0600
+)
+
+// credentialsStyle is how a package manager is given the credentials of
+// authenticated proxies.
+type credentialsStyle int
+
+const (
+	// urlCredentials leaves the credentials in the proxy URLs, and
+	// restricts the permissions of the configuration file instead.
+	urlCredentials credentialsStyle = iota
+
+	// aptAuthCredentials writes the credentials to AptProxyAuthFile.
+	aptAuthCredentials
+
+	// optionCredentials writes the credentials as the proxy_username and
+	// proxy_password options of yum and dnf, and restricts the
+	// permissions of the configuration file.
+	optionCredentials
+
+	// zypperCredentials writes the credentials to
+	// ZypperProxyCredentialsFile.
+	zypperCredentials
+)
+
+// credentials are the user information of a proxy URL.
+type credentials struct {
+	host     string
+	username string
+	password string
+}
+
+// splitCredentials returns the given settings with the user information
+// removed from their proxy URLs, along with the credentials of each proxy
+// which had any.
+func splitCredentials(settings proxy.Settings) (proxy.Settings, []credentials) {
+	var creds []credentials
+	for _, p := range []*string{&settings.Http, &settings.Https, &settings.Ftp} {
+		var c *credentials
+		*p, c = splitURLCredentials(*p)
+		if c == nil {
+			continue
+		}
+		found := false
+		for _, existing := range creds {
+			found = found || existing == *c
+		}
+		if !found {
+			creds = append(creds, *c)
+		}
+	}
+	return settings, creds
+}
+
+// splitURLCredentials returns the given proxy URL, which may lack a
+// scheme, without its user information, along with the credentials it
+// held, if any.
+func splitURLCredentials(proxyURL string) (string, *credentials) {
+	if !strings.Contains(proxyURL, "@") {
+		return proxyURL, nil
+	}
+	raw, prefixed := proxyURL, false
+	if !strings.Contains(raw, "://") {
+		raw, prefixed = "http://"+raw, true
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return proxyURL, nil
+	}
+	password, _ := u.User.Password()
+	c := &credentials{host: u.Host, username: u.User.Username(), password: password}
+	u.User = nil
+	res := u.String()
+	if prefixed {
+		res = strings.TrimPrefix(res, "http://")
+	}
+	return res, c
+}
+
+// single returns the only credentials of the given ones, for package
+// managers which can only be given one set of credentials. An error
+// satisfying errors.IsNotSupported is returned if the proxies have
+// different credentials.
+func single(creds []credentials) (*credentials, error) {
+	if len(creds) == 0 {
+		return nil, nil
+	}
+	for _, c := range creds[1:] {
+		if c.username != creds[0].username || c.password != creds[0].password {
+			return nil, errors.NotSupportedf("proxies with different credentials")
+		}
+	}
+	return &creds[0], nil
+}
+
+// renderAptAuth renders the given credentials in the auth.conf(5) format.
+func renderAptAuth(creds []credentials) string {
+	var sb strings.Builder
+	for _, c := range creds {
+		fmt.Fprintf(&sb, "machine %s login %s password %s\n", c.host, c.username, c.password)
+	}
+	return sb.String()
+}
+
+// renderZypperCredentials renders the given credentials in the format of
+// the credentials files of zypper.
+func renderZypperCredentials(c credentials) string {
+	return fmt.Sprintf("username=%s\npassword=%s\n", c.username, c.password)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package proxyconfig
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3/internal/fsutil"
+)
+
+// FS is the filesystem of the target system, in which a Writer manages the
+// configuration files. Paths are absolute paths on the target system.
+type FS interface {
+	// ReadFile returns the contents and the permissions of the file at
+	// the given path. An error satisfying errors.IsNotFound is returned
+	// if it does not exist.
+	ReadFile(p string) ([]byte, os.FileMode, error)
+
+	// WriteFile atomically replaces the file at the given path with one
+	// with the given contents and permissions, creating its directory if
+	// needed.
+	WriteFile(p string, data []byte, perm os.FileMode) error
+
+	// Remove removes the file at the given path, if it exists.
+	Remove(p string) error
+}
+
+// NewRootFS returns the FS of the filesystem tree below the given root
+// directory of the local system, which is "/" for the running system.
+func NewRootFS(root string) FS {
+	return rootFS{root: root}
+}
+
+// rootFS is the FS of a filesystem tree of the local system.
+type rootFS struct {
+	root string
+}
+
+// hostPath returns the path below the root of the given path.
+func (fs rootFS) hostPath(p string) string {
+	return filepath.Join(fs.root, filepath.FromSlash(p))
+}
+
+// ReadFile is defined on the FS interface.
+func (fs rootFS) ReadFile(p string) ([]byte, os.FileMode, error) {
+	f, err := os.Open(fs.hostPath(p))
+	if os.IsNotExist(err) {
+		return nil, 0, errors.NotFoundf("%s", p)
+	} else if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, errors.Trace(err)
+	}
+	data, err := io.ReadAll(f)
+	return data, info.Mode().Perm(), errors.Trace(err)
+}
+
+// WriteFile is defined on the FS interface.
+func (fs rootFS) WriteFile(p string, data []byte, perm os.FileMode) error {
+	return errors.Trace(fsutil.WriteFileAtomic(fs.hostPath(p), data, perm))
+}
+
+// Remove is defined on the FS interface.
+func (fs rootFS) Remove(p string) error {
+	err := os.Remove(fs.hostPath(p))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Trace(err)
+	}
+	return nil
+}
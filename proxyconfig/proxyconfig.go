@@ -0,0 +1,446 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package proxyconfig writes the proxy settings of package managers to
+// their configuration files natively, rather than by appending to them with
+// shell commands. Repeated writes replace the previous settings instead of
+// accumulating them, settings can be removed again, and in configuration
+// files which are shared with other settings only a block of lines managed
+// by this package is touched.
+package proxyconfig
+
+import (
+	"os"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/proxy"
+
+	"github.com/juju/packaging/v3/commands"
+)
+
+const (
+	// BeginMarker is the line which starts the managed block of settings
+	// in shared configuration files.
+	BeginMarker = "# BEGIN juju proxy settings"
+
+	// EndMarker is the line which ends the managed block of settings in
+	// shared configuration files.
+	EndMarker = "# END juju proxy settings"
+)
+
+// Writer writes the proxy settings of a package manager to its
+// configuration file in a filesystem tree.
+type Writer struct {
+	fs    FS
+	path  string
+	cmder commands.PackageCommander
+
+	// shared is true for configuration files which hold other settings
+	// as well, in which only the managed block is touched.
+	shared bool
+
+	// section is the INI section the managed block belongs in, if any.
+	section string
+
+	// prologue are the lines preceding the proxy settings in the managed
+	// block.
+	prologue []string
+
+	// credentials is how the credentials of authenticated proxies are
+	// written.
+	credentials credentialsStyle
+}
+
+// NewAptWriter returns a Writer which manages commands.AptConfFilePath
+// below the given root, which is "/" for the running system.
+func NewAptWriter(root string) *Writer {
+	return newAptWriter(NewRootFS(root))
+}
+
+// newAptWriter returns the Writer of apt which manages its configuration
+// in the given FS.
+func newAptWriter(fs FS) *Writer {
+	return &Writer{
+		fs:          fs,
+		path:        commands.AptConfFilePath,
+		cmder:       commands.NewAptPackageCommander(),
+		credentials: aptAuthCredentials,
+	}
+}
+
+// NewYumWriter returns a Writer which manages a block of the main section
+// of commands.YumConfigFilePath below the given root, which is "/" for the
+// running system.
+func NewYumWriter(root string) *Writer {
+	return newYumWriter(NewRootFS(root))
+}
+
+// newYumWriter returns the Writer of yum which manages its configuration
+// in the given FS.
+func newYumWriter(fs FS) *Writer {
+	return &Writer{
+		fs:          fs,
+		path:        commands.YumConfigFilePath,
+		cmder:       commands.NewYumPackageCommander(),
+		shared:      true,
+		section:     "main",
+		credentials: optionCredentials,
+	}
+}
+
+// NewDnfWriter returns a Writer which manages a block of the main section
+// of commands.DnfConfigFilePath below the given root, which is "/" for the
+// running system.
+func NewDnfWriter(root string) *Writer {
+	return newDnfWriter(NewRootFS(root))
+}
+
+// newDnfWriter returns the Writer of dnf which manages its configuration
+// in the given FS.
+func newDnfWriter(fs FS) *Writer {
+	return &Writer{
+		fs:          fs,
+		path:        commands.DnfConfigFilePath,
+		cmder:       commands.NewDnfPackageCommander(),
+		shared:      true,
+		section:     "main",
+		credentials: optionCredentials,
+	}
+}
+
+// NewZypperWriter returns a Writer which manages a block of
+// commands.OpenSUSEProxy below the given root, which is "/" for the
+// running system. The block enables the proxy, overriding the
+// PROXY_ENABLED setting preceding it, so that removing the block restores
+// the original settings.
+func NewZypperWriter(root string) *Writer {
+	return newZypperWriter(NewRootFS(root))
+}
+
+// newZypperWriter returns the Writer of zypper which manages its configuration
+// in the given FS.
+func newZypperWriter(fs FS) *Writer {
+	return &Writer{
+		fs:          fs,
+		path:        commands.OpenSUSEProxy,
+		cmder:       commands.NewZypperPackageCommander(),
+		shared:      true,
+		prologue:    []string{`PROXY_ENABLED="yes"`},
+		credentials: zypperCredentials,
+	}
+}
+
+// NewApkWriter returns a Writer which manages commands.ApkProxyFilePath
+// below the given root, which is "/" for the running system.
+func NewApkWriter(root string) *Writer {
+	return newApkWriter(NewRootFS(root))
+}
+
+// newApkWriter returns the Writer of apk which manages its configuration
+// in the given FS.
+func newApkWriter(fs FS) *Writer {
+	return &Writer{
+		fs:    fs,
+		path:  commands.ApkProxyFilePath,
+		cmder: commands.NewApkPackageCommander(),
+	}
+}
+
+// NewPacmanWriter returns a Writer which manages
+// commands.PacmanProxyFilePath below the given root, which is "/" for the
+// running system.
+func NewPacmanWriter(root string) *Writer {
+	return newPacmanWriter(NewRootFS(root))
+}
+
+// newPacmanWriter returns the Writer of pacman which manages its configuration
+// in the given FS.
+func newPacmanWriter(fs FS) *Writer {
+	return &Writer{
+		fs:    fs,
+		path:  commands.PacmanProxyFilePath,
+		cmder: commands.NewPacmanPackageCommander(),
+	}
+}
+
+// New returns the Writer for the package manager with the given name, such
+// as "apt" or "dnf", which manages its configuration below the given root.
+// An error satisfying errors.IsNotSupported is returned for package
+// managers without proxy configuration files, such as snap.
+func New(root, name string) (*Writer, error) {
+	return NewWithFS(NewRootFS(root), name)
+}
+
+// NewWithFS returns the Writer for the package manager with the given
+// name, as New does, which manages its configuration in the given FS.
+func NewWithFS(fs FS, name string) (*Writer, error) {
+	switch name {
+	case "apt":
+		return newAptWriter(fs), nil
+	case "yum":
+		return newYumWriter(fs), nil
+	case "dnf":
+		return newDnfWriter(fs), nil
+	case "zypper":
+		return newZypperWriter(fs), nil
+	case "apk":
+		return newApkWriter(fs), nil
+	case "pacman":
+		return newPacmanWriter(fs), nil
+	}
+	return nil, errors.NotSupportedf("proxy configuration files for %q", name)
+}
+
+// Path returns the path on the target system of the configuration file
+// managed by the Writer.
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Set replaces the proxy settings in the managed file with the given
+// ones. Settings without any proxies are the same as calling Unset. The
+// file is only rewritten if its contents change, and atomically so.
+//
+// The credentials of authenticated proxies are not written to the managed
+// file if the package manager can be given them otherwise: they are written
+// to AptProxyAuthFile for apt and ZypperProxyCredentialsFile for zypper,
+// which only their owner can read. For yum and dnf, they are written as
+// options instead, whereas apk and pacman need them in the proxy URLs; the
+// managed file is then restricted to its owner. yum, dnf and zypper only
+// support one set of credentials for all proxies; an error satisfying
+// errors.IsNotSupported is returned for different ones.
+func (w *Writer) Set(settings proxy.Settings) error {
+	stripped, creds := splitCredentials(settings)
+	if w.credentials == urlCredentials {
+		stripped = settings
+	}
+	contents := w.cmder.ProxyConfigContents(stripped)
+	if contents == "" {
+		return w.Unset()
+	}
+	lines := strings.Split(contents, "\n")
+
+	var secrets string
+	restrict := false
+	switch w.credentials {
+	case urlCredentials:
+		restrict = len(creds) > 0
+	case aptAuthCredentials:
+		secrets = renderAptAuth(creds)
+	case optionCredentials:
+		c, err := single(creds)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if c != nil {
+			lines = append(lines, "proxy_username="+c.username, "proxy_password="+c.password)
+			restrict = true
+		}
+	case zypperCredentials:
+		c, err := single(creds)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if c != nil {
+			secrets = renderZypperCredentials(*c)
+		}
+	}
+
+	// The credentials are written first, so that the proxies never refer
+	// to credentials which are not there yet.
+	if err := w.writeSecrets(secrets); err != nil {
+		return errors.Trace(err)
+	}
+	if !w.shared {
+		return errors.Trace(w.write(strings.Join(lines, "\n")+"\n", restrict))
+	}
+
+	existing, err := w.read()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	updated, err := w.replaceBlock(existing, lines)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(w.write(updated, restrict))
+}
+
+// Unset removes the proxy settings, and the credentials of authenticated
+// proxies, from the managed file, or the whole file if it is not shared
+// with other settings.
+func (w *Writer) Unset() error {
+	if err := w.unset(); err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(w.writeSecrets(""))
+}
+
+// unset removes the proxy settings from the managed file.
+func (w *Writer) unset() error {
+	if !w.shared {
+		return errors.Trace(w.fs.Remove(w.path))
+	}
+
+	existing, err := w.read()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if existing == "" {
+		return nil
+	}
+	updated, err := w.replaceBlock(existing, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(w.write(updated, false))
+}
+
+// writeSecrets writes the given credentials to the credentials file of the
+// package manager, if it has one, or removes the file if there are none.
+func (w *Writer) writeSecrets(secrets string) error {
+	var p string
+	switch w.credentials {
+	case aptAuthCredentials:
+		p = AptProxyAuthFile
+	case zypperCredentials:
+		p = ZypperProxyCredentialsFile
+	default:
+		return nil
+	}
+	return errors.Trace(writeIfChanged(w.fs, p, secrets, true))
+}
+
+// read returns the contents of the managed file, which are empty if it
+// does not exist.
+func (w *Writer) read() (string, error) {
+	data, _, err := w.fs.ReadFile(w.path)
+	if errors.IsNotFound(err) {
+		return "", nil
+	}
+	return string(data), errors.Trace(err)
+}
+
+// replaceBlock returns the given contents of a shared file with its
+// managed block replaced by one with the given settings, or removed if
+// there are none. A new block is inserted at the end of the section of
+// the Writer, or at the end of the file.
+func (w *Writer) replaceBlock(contents string, settings []string) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(contents, "\n"), "\n")
+	if contents == "" {
+		lines = nil
+	}
+
+	// Remove the current block.
+	begin, end := -1, -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case BeginMarker:
+			if begin >= 0 {
+				return "", errors.NotValidf("%s with nested %q", w.path, BeginMarker)
+			}
+			begin = i
+		case EndMarker:
+			if begin < 0 || end >= 0 {
+				return "", errors.NotValidf("%s with unexpected %q", w.path, EndMarker)
+			}
+			end = i
+		}
+	}
+	if begin >= 0 && end < 0 {
+		return "", errors.NotValidf("%s without %q", w.path, EndMarker)
+	}
+	insertAt := -1
+	if begin >= 0 {
+		lines = append(lines[:begin], lines[end+1:]...)
+		insertAt = begin
+	}
+
+	if len(settings) > 0 {
+		block := append([]string{BeginMarker}, w.prologue...)
+		if insertAt < 0 {
+			var found bool
+			insertAt, found = w.sectionEnd(lines)
+			if !found && w.section != "" {
+				block = append(block, "["+w.section+"]")
+			}
+		}
+		block = append(append(block, settings...), EndMarker)
+		lines = append(lines[:insertAt], append(block, lines[insertAt:]...)...)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// sectionEnd returns the index of the line after the last non-blank line
+// of the section of the Writer, and whether the section exists. The end
+// of the file is returned for Writers without a section, and if the
+// section does not exist.
+func (w *Writer) sectionEnd(lines []string) (int, bool) {
+	end := func(from, to int) int {
+		for to > from && strings.TrimSpace(lines[to-1]) == "" {
+			to--
+		}
+		return to
+	}
+	if w.section == "" {
+		return end(0, len(lines)), false
+	}
+
+	start := -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		if start >= 0 {
+			return end(start, i), true
+		}
+		if strings.TrimSpace(line[1:len(line)-1]) == w.section {
+			start = i + 1
+		}
+	}
+	if start >= 0 {
+		return end(start, len(lines)), true
+	}
+	return end(0, len(lines)), false
+}
+
+// write atomically replaces the managed file with the given contents,
+// see writeIfChanged.
+func (w *Writer) write(contents string, restrict bool) error {
+	return errors.Trace(writeIfChanged(w.fs, w.path, contents, restrict))
+}
+
+// writeIfChanged atomically replaces the file at the given path of the
+// given FS with the given contents, unless it already has them, keeping
+// the permissions of an existing file unless they are to be restricted to
+// its owner. The file is removed if the contents are empty.
+func writeIfChanged(fs FS, p, contents string, restrict bool) error {
+	perm := os.FileMode(0644)
+	existing, existingPerm, err := fs.ReadFile(p)
+	switch {
+	case err == nil:
+		perm = existingPerm
+		if restrict {
+			perm &= secretPerm
+		}
+		if string(existing) == contents && perm == existingPerm {
+			return nil
+		}
+	case !errors.IsNotFound(err):
+		return errors.Trace(err)
+	case contents == "":
+		return nil
+	case restrict:
+		perm = secretPerm
+	}
+	if contents == "" {
+		return errors.Trace(fs.Remove(p))
+	}
+	return errors.Trace(fs.WriteFile(p, []byte(contents), perm))
+}
@@ -0,0 +1,422 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package reconcile brings the package sources and preferences configured
+// on a system in line with a desired set of them. Each source and set of
+// preferences is managed as a file of its own, which is only rewritten if
+// its contents change, so that reconciling repeatedly is idempotent.
+package reconcile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/distro"
+	"github.com/juju/packaging/v3/internal/fsutil"
+	"github.com/juju/packaging/v3/keyring"
+	"github.com/juju/packaging/v3/manager"
+)
+
+// ManifestPath is the path, on the target system, of the file listing the
+// files and keys managed by reconciliation. Only the files and keys listed
+// in it are ever removed.
+var ManifestPath = "/var/lib/juju/packaging/reconcile.manifest"
+
+// State is a set of package sources and preferences.
+type State struct {
+	// Sources are the package sources. The Key of a source without
+	// SignedBy is installed with the keyring package and referenced by
+	// the source. The credentials of a source are written to a file of
+	// their own where the backend supports it, see
+	// config.CredentialsRenderer, or else into the source file, which is
+	// then readable by root only.
+	Sources []packaging.PackageSource
+
+	// Preferences are the package preferences, which are written to their
+	// Path, with several preferences of the same Path as its stanzas. On
+	// rpm-based systems, they are applied to the sources they pin instead,
+	// see config.PreferencesApplier.
+	Preferences []packaging.PackagePreferences
+}
+
+// Op is an operation on a file managed by a Reconciler.
+type Op string
+
+const (
+	// Add creates a file.
+	Add Op = "add"
+
+	// Update replaces the contents of an existing file.
+	Update Op = "update"
+
+	// Remove removes a file which is no longer desired.
+	Remove Op = "remove"
+)
+
+// Action is a change to a file managed by a Reconciler.
+type Action struct {
+	// Op is the operation on the file.
+	Op Op
+
+	// Path is the path of the file on the target system.
+	Path string
+
+	// Contents are the new contents of the file, which are empty for
+	// removals and keys.
+	Contents string
+
+	// Secret is true for files holding credentials, which are readable
+	// by root only.
+	Secret bool
+
+	// keyName is the name of the key which is installed or removed by the
+	// action, if any.
+	keyName string
+
+	// key is the key to install.
+	key []byte
+}
+
+// Reconciler reconciles the package sources and preferences of the
+// filesystem tree at a root, which is "/" for the running system.
+type Reconciler struct {
+	root       string
+	configurer config.PackagingConfigurer
+	manager    manager.PackageManager
+	keyring    *keyring.Keyring
+	sourcesDir string
+	sourceExt  string
+	render     func(packaging.PackageSource) (string, error)
+}
+
+// New returns a Reconciler for the given backend and the filesystem tree at
+// the given root. An error satisfying errors.IsNotSupported is returned for
+// backends whose sources are not kept in files of their own.
+func New(root string, backend distro.Backend) (*Reconciler, error) {
+	r := &Reconciler{
+		root:       root,
+		configurer: backend.Configurer,
+		manager:    backend.Manager,
+		render:     backend.Configurer.RenderSource,
+	}
+	switch backend.Name {
+	case "apt":
+		renderer, ok := backend.Configurer.(config.Deb822SourceRenderer)
+		if !ok {
+			return nil, errors.NotSupportedf("reconciling apt sources without deb822 rendering")
+		}
+		r.sourcesDir, r.sourceExt = config.AptSourcesDirectory, ".sources"
+		r.render = renderer.RenderDeb822Source
+		r.keyring = keyring.NewAptKeyring(root)
+	case "yum", "dnf":
+		r.sourcesDir, r.sourceExt = config.YumReposDir, ".repo"
+		r.keyring = keyring.NewRPMKeyring(root)
+	case "zypper":
+		r.sourcesDir, r.sourceExt = config.ZypperSourcesDir, ".repo"
+		r.keyring = keyring.NewRPMKeyring(root)
+	default:
+		return nil, errors.NotSupportedf("reconciling %s sources", backend.Name)
+	}
+	return r, nil
+}
+
+// hostPath returns the path below the root of the given path on the target
+// system.
+func (r *Reconciler) hostPath(p string) string {
+	return filepath.Join(r.root, filepath.FromSlash(p))
+}
+
+// Plan returns the actions which bring the configured sources and
+// preferences in line with the desired ones, without applying them.
+func (r *Reconciler) Plan(desired State) ([]Action, error) {
+	want, err := r.desiredActions(desired)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	managed, err := r.readManifest()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var res []Action
+	wanted := make(map[string]bool)
+	for _, action := range want {
+		wanted[manifestEntry(action)] = true
+		op, err := r.diff(action)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if op != "" {
+			action.Op = op
+			res = append(res, action)
+		}
+	}
+	for _, entry := range managed {
+		if wanted[entry] {
+			continue
+		}
+		action := Action{Op: Remove}
+		if name, ok := strings.CutPrefix(entry, "key "); ok {
+			action.keyName, action.Path = name, r.keyring.Path(name)
+		} else {
+			action.Path = strings.TrimPrefix(entry, "file ")
+		}
+		res = append(res, action)
+	}
+	return res, nil
+}
+
+// Apply applies the actions returned by Plan, and updates the package
+// lists if any source or key changed. It returns the applied actions.
+func (r *Reconciler) Apply(ctx context.Context, desired State) ([]Action, error) {
+	actions, err := r.Plan(desired)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(actions) == 0 {
+		return nil, nil
+	}
+
+	managed, err := r.readManifest()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entries := make(map[string]bool)
+	for _, entry := range managed {
+		entries[entry] = true
+	}
+
+	sourcesChanged := false
+	for i, action := range actions {
+		if err := ctx.Err(); err != nil {
+			return actions[:i], err
+		}
+		if err := r.apply(action); err != nil {
+			return actions[:i], errors.Annotatef(err, "%s %s", action.Op, action.Path)
+		}
+		entries[manifestEntry(action)] = action.Op != Remove
+		if err := r.writeManifest(entries); err != nil {
+			return actions[:i+1], errors.Trace(err)
+		}
+		sourcesChanged = sourcesChanged || action.keyName != "" || action.Secret || path.Dir(action.Path) == r.sourcesDir
+	}
+
+	if !sourcesChanged {
+		return actions, nil
+	}
+	if err := r.update(ctx); err != nil {
+		return actions, errors.Annotate(err, "updating package lists")
+	}
+	return actions, nil
+}
+
+// update updates the package lists with the PackageManager.
+func (r *Reconciler) update(ctx context.Context) error {
+	if pm, ok := r.manager.(manager.PackageManagerContext); ok {
+		return pm.UpdateContext(ctx)
+	}
+	return r.manager.Update()
+}
+
+// desiredActions returns the actions which write all the desired files and
+// install all the desired keys, regardless of what is configured.
+func (r *Reconciler) desiredActions(desired State) ([]Action, error) {
+	applier, applyPrefs := r.configurer.(config.PreferencesApplier)
+	credentials, renderCredentials := r.configurer.(config.CredentialsRenderer)
+
+	var res []Action
+	seen := make(map[string]string)
+	for _, src := range desired.Sources {
+		if applyPrefs {
+			var err error
+			if src, err = applier.ApplyPreferences(src, desired.Preferences...); err != nil {
+				return nil, errors.Annotatef(err, "applying preferences to source %q", src.Name)
+			}
+		}
+		name := keyring.SourceKeyName(src)
+		if other, ok := seen[name]; ok {
+			return nil, errors.NotValidf("sources %q and %q with the same file name", other, src.Name)
+		}
+		seen[name] = src.Name
+
+		if src.Key != "" && src.SignedBy == "" {
+			res = append(res, Action{
+				Path:    r.keyring.Path(name),
+				keyName: name,
+				key:     []byte(src.Key),
+			})
+			src.SignedBy = r.keyring.Path(name)
+		}
+		secret := false
+		if renderCredentials {
+			p, contents, err := credentials.RenderCredentials(src)
+			if err != nil {
+				return nil, errors.Annotatef(err, "rendering credentials of source %q", src.Name)
+			}
+			if p != "" {
+				res = append(res, Action{Path: p, Contents: contents, Secret: true})
+			}
+		} else {
+			username, password := src.Credentials()
+			secret = username != "" || password != ""
+		}
+		contents, err := r.render(src)
+		if err != nil {
+			return nil, errors.Annotatef(err, "rendering source %q", src.Name)
+		}
+		res = append(res, Action{
+			Path:     path.Join(r.sourcesDir, name+r.sourceExt),
+			Contents: contents,
+			Secret:   secret,
+		})
+	}
+
+	if applyPrefs {
+		return res, nil
+	}
+	// Preferences with the same path are rendered as the stanzas of a
+	// single file.
+	var paths []string
+	stanzas := make(map[string][]string)
+	for _, prefs := range desired.Preferences {
+		if !path.IsAbs(prefs.Path) {
+			return nil, errors.NotValidf("preferences path %q", prefs.Path)
+		}
+		contents, err := r.configurer.RenderPreferences(prefs)
+		if err != nil {
+			return nil, errors.Annotatef(err, "rendering preferences %q", prefs.Path)
+		}
+		if contents == "" {
+			continue
+		}
+		p := path.Clean(prefs.Path)
+		if _, ok := stanzas[p]; !ok {
+			paths = append(paths, p)
+		}
+		stanzas[p] = append(stanzas[p], contents)
+	}
+	for _, p := range paths {
+		res = append(res, Action{Path: p, Contents: strings.Join(stanzas[p], "\n")})
+	}
+	return res, nil
+}
+
+// diff returns the operation which brings the target of the given action
+// in line with it, or an empty operation if it already is. Secret files
+// which are readable by others than root are updated as well.
+func (r *Reconciler) diff(action Action) (Op, error) {
+	if action.keyName != "" {
+		installed, err := r.keyring.IsInstalled(action.keyName, action.key)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		if installed {
+			return "", nil
+		}
+	}
+
+	existing, err := os.ReadFile(r.hostPath(action.Path))
+	switch {
+	case os.IsNotExist(err):
+		return Add, nil
+	case err != nil:
+		return "", errors.Trace(err)
+	case action.keyName != "":
+		return Update, nil
+	case string(existing) != action.Contents:
+		return Update, nil
+	case !action.Secret:
+		return "", nil
+	}
+	info, err := os.Stat(r.hostPath(action.Path))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return Update, nil
+	}
+	return "", nil
+}
+
+// apply applies the given action.
+func (r *Reconciler) apply(action Action) error {
+	switch {
+	case action.keyName != "" && action.Op == Remove:
+		err := r.keyring.Remove(action.keyName)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Trace(err)
+	case action.keyName != "":
+		_, err := r.keyring.Install(action.keyName, action.key)
+		return errors.Trace(err)
+	case action.Op == Remove:
+		if err := os.Remove(r.hostPath(action.Path)); err != nil && !os.IsNotExist(err) {
+			return errors.Trace(err)
+		}
+		return nil
+	}
+	perm := os.FileMode(0644)
+	if action.Secret {
+		perm = 0600
+	}
+	return errors.Trace(fsutil.WriteFileAtomic(r.hostPath(action.Path), []byte(action.Contents), perm))
+}
+
+// manifestEntry returns the manifest entry of the target of the given
+// action.
+func manifestEntry(action Action) string {
+	if action.keyName != "" {
+		return "key " + action.keyName
+	}
+	return "file " + action.Path
+}
+
+// readManifest returns the sorted entries of the manifest.
+func (r *Reconciler) readManifest() ([]string, error) {
+	f, err := os.Open(r.hostPath(ManifestPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+
+	var res []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if entry := strings.TrimSpace(scanner.Text()); entry != "" && !strings.HasPrefix(entry, "#") {
+			res = append(res, entry)
+		}
+	}
+	sort.Strings(res)
+	return res, errors.Trace(scanner.Err())
+}
+
+// writeManifest replaces the manifest with one listing the given entries
+// which are set to true.
+func (r *Reconciler) writeManifest(entries map[string]bool) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Files and keys managed by Juju\n")
+	var sorted []string
+	for entry, ok := range entries {
+		if ok {
+			sorted = append(sorted, entry)
+		}
+	}
+	sort.Strings(sorted)
+	for _, entry := range sorted {
+		buf.WriteString(entry + "\n")
+	}
+	return errors.Trace(fsutil.WriteFileAtomic(r.hostPath(ManifestPath), buf.Bytes(), 0644))
+}
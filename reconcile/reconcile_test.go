@@ -0,0 +1,357 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package reconcile_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3"
+	"github.com/juju/packaging/v3/config"
+	"github.com/juju/packaging/v3/distro"
+	managertesting "github.com/juju/packaging/v3/manager/testing"
+	"github.com/juju/packaging/v3/reconcile"
+)
+
+var _ = gc.Suite(&ReconcileSuite{})
+
+type ReconcileSuite struct {
+	root string
+	pm   *countingManager
+}
+
+// countingManager is a PackageManager counting its updates.
+type countingManager struct {
+	managertesting.MockPackageManager
+	updates int
+}
+
+func (pm *countingManager) Update() error {
+	pm.updates++
+	return nil
+}
+
+func (pm *countingManager) UpdateContext(context.Context) error {
+	return pm.Update()
+}
+
+const testKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mQANBGp1anUtdGVzdC1rZXk=
+=CApW
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+var (
+	jujuSource = packaging.PackageSource{
+		Name:       "juju",
+		URL:        "http://juju.example.com/ubuntu",
+		Suites:     []string{"jammy"},
+		Components: []string{"main"},
+		Key:        testKey,
+	}
+	otherSource = packaging.PackageSource{
+		Name:     "Other Source",
+		URL:      "http://other.example.com/ubuntu",
+		Suites:   []string{"jammy"},
+		SignedBy: "/usr/share/keyrings/other.gpg",
+	}
+	jujuPrefs = packaging.PackagePreferences{
+		Path:        "/etc/apt/preferences.d/juju",
+		Explanation: "prefer juju",
+		Package:     "*",
+		Pin:         "origin juju.example.com",
+		Priority:    600,
+	}
+)
+
+func (s *ReconcileSuite) SetUpTest(c *gc.C) {
+	s.root = c.MkDir()
+	s.pm = &countingManager{}
+}
+
+func (s *ReconcileSuite) newReconciler(c *gc.C, name string, configurer config.PackagingConfigurer) *reconcile.Reconciler {
+	r, err := reconcile.New(s.root, distro.Backend{
+		Name:       name,
+		Manager:    s.pm,
+		Configurer: configurer,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return r
+}
+
+func (s *ReconcileSuite) readFile(c *gc.C, p string) string {
+	data, err := os.ReadFile(filepath.Join(s.root, p))
+	c.Assert(err, jc.ErrorIsNil)
+	return string(data)
+}
+
+func actionSummary(actions []reconcile.Action) []string {
+	var res []string
+	for _, action := range actions {
+		res = append(res, string(action.Op)+" "+action.Path)
+	}
+	return res
+}
+
+func (s *ReconcileSuite) TestApplyIsIdempotent(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+	desired := reconcile.State{
+		Sources:     []packaging.PackageSource{jujuSource, otherSource},
+		Preferences: []packaging.PackagePreferences{jujuPrefs},
+	}
+
+	actions, err := r.Apply(context.Background(), desired)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"add /usr/share/keyrings/juju.gpg",
+		"add /etc/apt/sources.list.d/juju.sources",
+		"add /etc/apt/sources.list.d/other-source.sources",
+		"add /etc/apt/preferences.d/juju",
+	})
+	c.Assert(s.pm.updates, gc.Equals, 1)
+	c.Assert(s.readFile(c, "etc/apt/sources.list.d/juju.sources"), gc.Equals, `
+# juju (added by Juju)
+Types: deb
+URIs: http://juju.example.com/ubuntu
+Suites: jammy
+Components: main
+Signed-By: /usr/share/keyrings/juju.gpg
+`[1:])
+	c.Assert(s.readFile(c, "etc/apt/preferences.d/juju"), gc.Matches, "(?s)Explanation: prefer juju\n.*")
+
+	// Applying the same state again changes nothing.
+	actions, err = r.Apply(context.Background(), desired)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions, gc.HasLen, 0)
+	c.Assert(s.pm.updates, gc.Equals, 1)
+}
+
+func (s *ReconcileSuite) TestApplyUpdatesAndRemoves(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+	_, err := r.Apply(context.Background(), reconcile.State{
+		Sources:     []packaging.PackageSource{jujuSource, otherSource},
+		Preferences: []packaging.PackagePreferences{jujuPrefs},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// Files which were not added by the reconciler are left alone.
+	unmanaged := filepath.Join(s.root, "etc", "apt", "sources.list.d", "ubuntu.sources")
+	c.Assert(os.WriteFile(unmanaged, []byte("Types: deb\n"), 0644), jc.ErrorIsNil)
+
+	changed := otherSource
+	changed.Suites = []string{"noble"}
+	actions, err := r.Apply(context.Background(), reconcile.State{
+		Sources: []packaging.PackageSource{changed},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"update /etc/apt/sources.list.d/other-source.sources",
+		"remove /etc/apt/preferences.d/juju",
+		"remove /etc/apt/sources.list.d/juju.sources",
+		"remove /usr/share/keyrings/juju.gpg",
+	})
+	c.Assert(s.pm.updates, gc.Equals, 2)
+	c.Assert(s.readFile(c, "etc/apt/sources.list.d/other-source.sources"), gc.Matches, "(?s).*Suites: noble\n.*")
+
+	for _, p := range []string{"etc/apt/preferences.d/juju", "etc/apt/sources.list.d/juju.sources", "usr/share/keyrings/juju.gpg"} {
+		_, err := os.Stat(filepath.Join(s.root, p))
+		c.Check(os.IsNotExist(err), jc.IsTrue, gc.Commentf("%s", p))
+	}
+	_, err = os.Stat(unmanaged)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ReconcileSuite) TestApplyPreferencesOnlyDoesNotUpdate(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+
+	actions, err := r.Apply(context.Background(), reconcile.State{
+		Preferences: []packaging.PackagePreferences{jujuPrefs},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actions, gc.HasLen, 1)
+	c.Assert(s.pm.updates, gc.Equals, 0)
+}
+
+func (s *ReconcileSuite) TestPreferencesStanzas(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+	excluded := packaging.PackagePreferences{
+		Path:     jujuPrefs.Path,
+		Package:  "juju-db",
+		Pin:      jujuPrefs.Pin,
+		Priority: -1,
+	}
+
+	actions, err := r.Plan(reconcile.State{
+		Preferences: []packaging.PackagePreferences{jujuPrefs, excluded},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{"add /etc/apt/preferences.d/juju"})
+
+	prefs, err := config.ParseAptPreferences(actions[0].Contents)
+	c.Assert(err, jc.ErrorIsNil)
+	// The parsed stanzas do not know the file they were read from.
+	expected := []packaging.PackagePreferences{jujuPrefs, excluded}
+	for i := range expected {
+		expected[i].Path = ""
+	}
+	c.Assert(prefs, gc.DeepEquals, expected)
+}
+
+func (s *ReconcileSuite) TestPlanDoesNotApply(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+
+	actions, err := r.Plan(reconcile.State{Sources: []packaging.PackageSource{otherSource}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{"add /etc/apt/sources.list.d/other-source.sources"})
+	c.Assert(actions[0].Contents, gc.Matches, "(?s)# Other Source \\(added by Juju\\)\n.*")
+
+	entries, err := os.ReadDir(s.root)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entries, gc.HasLen, 0)
+}
+
+func (s *ReconcileSuite) TestYumRepositories(c *gc.C) {
+	r := s.newReconciler(c, "dnf", config.NewYumPackagingConfigurer())
+	src := packaging.PackageSource{
+		Name: "juju",
+		URL:  "https://juju.example.com/el9",
+		Key:  testKey,
+	}
+
+	actions, err := r.Apply(context.Background(), reconcile.State{
+		Sources:     []packaging.PackageSource{src},
+		Preferences: []packaging.PackagePreferences{jujuPrefs},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// yum has no preferences files.
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"add /etc/pki/rpm-gpg/RPM-GPG-KEY-juju",
+		"add /etc/yum.repos.d/juju.repo",
+	})
+	c.Assert(s.readFile(c, "etc/yum.repos.d/juju.repo"), gc.Matches,
+		"(?s).*gpgkey=file:///etc/pki/rpm-gpg/RPM-GPG-KEY-juju\n.*")
+}
+
+func (s *ReconcileSuite) fileMode(c *gc.C, p string) os.FileMode {
+	info, err := os.Stat(filepath.Join(s.root, p))
+	c.Assert(err, jc.ErrorIsNil)
+	return info.Mode().Perm()
+}
+
+func (s *ReconcileSuite) TestAptCredentials(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+	src := otherSource
+	src.Username, src.Password = "juju", "s3cret"
+	desired := reconcile.State{Sources: []packaging.PackageSource{src}}
+
+	actions, err := r.Apply(context.Background(), desired)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"add /etc/apt/auth.conf.d/other-source.conf",
+		"add /etc/apt/sources.list.d/other-source.sources",
+	})
+	c.Assert(s.readFile(c, "etc/apt/auth.conf.d/other-source.conf"), gc.Equals,
+		"machine other.example.com/ubuntu\nlogin juju\npassword s3cret\n")
+	c.Assert(s.fileMode(c, "etc/apt/auth.conf.d/other-source.conf"), gc.Equals, os.FileMode(0600))
+	c.Assert(s.readFile(c, "etc/apt/sources.list.d/other-source.sources"), gc.Not(jc.Contains), "s3cret")
+	c.Assert(s.fileMode(c, "etc/apt/sources.list.d/other-source.sources"), gc.Equals, os.FileMode(0644))
+
+	// Credentials readable by others are locked down again.
+	err = os.Chmod(filepath.Join(s.root, "etc/apt/auth.conf.d/other-source.conf"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	actions, err = r.Apply(context.Background(), desired)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{"update /etc/apt/auth.conf.d/other-source.conf"})
+	c.Assert(s.fileMode(c, "etc/apt/auth.conf.d/other-source.conf"), gc.Equals, os.FileMode(0600))
+
+	// Removing the source removes its credentials.
+	actions, err = r.Apply(context.Background(), reconcile.State{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"remove /etc/apt/auth.conf.d/other-source.conf",
+		"remove /etc/apt/sources.list.d/other-source.sources",
+	})
+}
+
+func (s *ReconcileSuite) TestYumCredentials(c *gc.C) {
+	r := s.newReconciler(c, "yum", config.NewYumPackagingConfigurer())
+	src := packaging.PackageSource{
+		Name:     "juju",
+		URL:      "https://juju.example.com/el9",
+		Username: "juju",
+		Password: "s3cret",
+	}
+
+	actions, err := r.Apply(context.Background(), reconcile.State{Sources: []packaging.PackageSource{src}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{"add /etc/yum.repos.d/juju.repo"})
+	c.Assert(s.readFile(c, "etc/yum.repos.d/juju.repo"), jc.Contains, "username=juju\npassword=s3cret\n")
+	c.Assert(s.fileMode(c, "etc/yum.repos.d/juju.repo"), gc.Equals, os.FileMode(0600))
+}
+
+func (s *ReconcileSuite) TestYumRepositoryPreferences(c *gc.C) {
+	r := s.newReconciler(c, "yum", config.NewYumPackagingConfigurer())
+	src := packaging.PackageSource{
+		Name: "juju",
+		URL:  "https://juju.example.com/el9",
+	}
+	prefs := []packaging.PackagePreferences{{
+		Package:  "*",
+		Pin:      "origin juju",
+		Priority: 600,
+	}, {
+		Package:  "mongodb*",
+		Pin:      "origin juju",
+		Priority: -1,
+	}}
+
+	actions, err := r.Apply(context.Background(), reconcile.State{
+		Sources:     []packaging.PackageSource{src},
+		Preferences: prefs,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actionSummary(actions), gc.DeepEquals, []string{
+		"add /etc/yum.repos.d/juju.repo",
+	})
+	c.Assert(s.readFile(c, "etc/yum.repos.d/juju.repo"), gc.Matches,
+		"(?s).*\npriority=89\nexclude=mongodb\\*\nenabled=1\n")
+
+	// Preferences which cannot be expressed are rejected.
+	_, err = r.Plan(reconcile.State{
+		Sources: []packaging.PackageSource{src},
+		Preferences: []packaging.PackagePreferences{{
+			Package: "lxd", Pin: "origin juju", Priority: 900,
+		}},
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *ReconcileSuite) TestInvalidState(c *gc.C) {
+	r := s.newReconciler(c, "apt", config.NewAptPackagingConfigurer())
+
+	_, err := r.Plan(reconcile.State{Sources: []packaging.PackageSource{otherSource, otherSource}})
+	c.Assert(err, gc.ErrorMatches, `sources "Other Source" and "Other Source" with the same file name not valid`)
+
+	_, err = r.Plan(reconcile.State{Sources: []packaging.PackageSource{{Name: "legacy", URL: "http://legacy"}}})
+	c.Assert(err, gc.ErrorMatches, `rendering source "legacy": apt source "legacy" without suites not valid`)
+
+	prefs := jujuPrefs
+	prefs.Path = "relative"
+	_, err = r.Plan(reconcile.State{Preferences: []packaging.PackagePreferences{prefs}})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ReconcileSuite) TestNewNotSupported(c *gc.C) {
+	_, err := reconcile.New(s.root, distro.Backend{
+		Name:       "apk",
+		Manager:    s.pm,
+		Configurer: config.NewApkPackagingConfigurer(),
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
@@ -7,6 +7,8 @@
 package packaging
 
 import (
+	"net/url"
+	"strings"
 	"text/template"
 )
 
@@ -15,6 +17,91 @@ type PackageSource struct {
 	Name string `yaml:"-"`
 	URL  string `yaml:"source"`
 	Key  string `yaml:"key,omitempty"`
+
+	// The following fields are mostly used by apt, where they make up the
+	// entries of a sources file. Sources without Suites are rendered in
+	// the legacy format, which leaves the suite to be filled in by the
+	// caller.
+
+	// Types are the types of archives of the source, "deb" and/or
+	// "deb-src". They default to "deb".
+	Types []string `yaml:"types,omitempty"`
+
+	// Suites are the suites, such as "jammy" or "jammy-updates", of the
+	// source. A suite ending in a slash is the path of a flat repository,
+	// which has no Components.
+	Suites []string `yaml:"suites,omitempty"`
+
+	// Components are the components, such as "main", of the source.
+	Components []string `yaml:"components,omitempty"`
+
+	// Architectures restricts the source to the given architectures.
+	Architectures []string `yaml:"architectures,omitempty"`
+
+	// SignedBy is the path of the keyring, the fingerprint of the key or
+	// the armored key which the source has to be signed with. It is also
+	// rendered as the gpgkey of yum and zypper repositories, in which case
+	// it must be the path of the key. See the keyring package for
+	// installing the Key of a source and setting SignedBy accordingly.
+	SignedBy string `yaml:"signed-by,omitempty"`
+
+	// Disabled is true for sources which are configured, but not used.
+	Disabled bool `yaml:"disabled,omitempty"`
+
+	// Priority is the priority of the source relative to other sources,
+	// as understood by yum and zypper, where lower values take precedence.
+	// Zero stands for the default priority of the package manager.
+	Priority int `yaml:"priority,omitempty"`
+
+	// IncludePackages restricts the packages yum installs from the source
+	// to those matching the given globs.
+	IncludePackages []string `yaml:"include-packages,omitempty"`
+
+	// ExcludePackages are globs of packages yum never installs from the
+	// source.
+	ExcludePackages []string `yaml:"exclude-packages,omitempty"`
+
+	// Username and Password authenticate the package manager with a
+	// private source. A token is given as the Password, with whichever
+	// Username the source expects along with it. Credentials given as the
+	// userinfo of the URL are used if both are empty. The credentials are
+	// never rendered into the URL of the source, see the
+	// config.CredentialsRenderer interface.
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+}
+
+// Credentials returns the username and password of the source, which are
+// taken from the userinfo of its URL if Username and Password are empty.
+func (s *PackageSource) Credentials() (username, password string) {
+	if s.Username != "" || s.Password != "" {
+		return s.Username, s.Password
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil || u.User == nil {
+		return "", ""
+	}
+	password, _ = u.User.Password()
+	return u.User.Username(), password
+}
+
+// BaseName returns the name of the source reduced to the characters which
+// are safe in file names, for naming the files written for the source.
+func (s *PackageSource) BaseName() string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, s.Name)
+	name = strings.Trim(name, ".-_")
+	if name == "" {
+		return "juju"
+	}
+	return name
 }
 
 // KeyFileName returns the name of this source's keyfile.
@@ -0,0 +1,60 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package packaging
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// PackageSpec describes a package to be installed along with the optional
+// constraints on which build of it is to be installed. It is rendered into
+// the syntax of each package management system by the PackageCommander.
+type PackageSpec struct {
+	// Name is the name of the package.
+	Name string
+
+	// Version is the version of the package to install. For snaps, it is
+	// the revision to install.
+	Version string
+
+	// Architecture is the architecture of the package to install.
+	Architecture string
+
+	// Channel is the channel to install the package from. It is only
+	// relevant for snaps.
+	Channel string
+
+	// TargetRelease is the release (e.g. "focal-backports") to install the
+	// package from. It is only relevant for apt and, as the repository to
+	// install the package from, for apk and pacman.
+	TargetRelease string
+}
+
+// specUnsafeChars are the characters which may not appear in the fields of
+// a PackageSpec, as they would split or otherwise alter the shell command
+// the spec is rendered into.
+const specUnsafeChars = " \t\n\r;&|<>`$'\"\\"
+
+// Validate returns an error if the spec does not name a package or any of
+// its fields could not be passed as a single command line argument.
+func (s PackageSpec) Validate() error {
+	if s.Name == "" {
+		return errors.NotValidf("package spec without a name")
+	}
+	fields := []struct{ name, value string }{
+		{"name", s.Name},
+		{"version", s.Version},
+		{"architecture", s.Architecture},
+		{"channel", s.Channel},
+		{"target release", s.TargetRelease},
+	}
+	for _, field := range fields {
+		if strings.ContainsAny(field.value, specUnsafeChars) {
+			return errors.NotValidf("package %q %s %q", s.Name, field.name, field.value)
+		}
+	}
+	return nil
+}
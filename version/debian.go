@@ -0,0 +1,164 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// DebianVersion is a version of a deb package, in the
+// [epoch:]upstream_version[-debian_revision] format described by the
+// Debian Policy Manual.
+type DebianVersion struct {
+	// Epoch is the epoch of the version, which is zero if omitted.
+	Epoch int
+
+	// Upstream is the upstream version.
+	Upstream string
+
+	// Revision is the Debian revision, which is empty for native packages.
+	Revision string
+}
+
+// ParseDebian parses the given deb package version.
+func ParseDebian(v string) (DebianVersion, error) {
+	var res DebianVersion
+	s := strings.TrimSpace(v)
+
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		epoch, err := strconv.Atoi(s[:i])
+		if err != nil || epoch < 0 {
+			return res, errors.NotValidf("deb version %q epoch", v)
+		}
+		res.Epoch = epoch
+		s = s[i+1:]
+	}
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		res.Revision = s[i+1:]
+		s = s[:i]
+		if !validDebianPart(res.Revision, "+.~") {
+			return res, errors.NotValidf("deb version %q revision", v)
+		}
+	}
+	res.Upstream = s
+	if res.Upstream == "" || !isDigit(res.Upstream[0]) || !validDebianPart(res.Upstream, "+.~-:") {
+		return res, errors.NotValidf("deb version %q", v)
+	}
+	return res, nil
+}
+
+// validDebianPart returns whether the given version part is non-empty and
+// only consists of alphanumerics and the given punctuation.
+func validDebianPart(part, punct string) bool {
+	if part == "" {
+		return false
+	}
+	for i := 0; i < len(part); i++ {
+		c := part[i]
+		if !isDigit(c) && !isAlpha(c) && strings.IndexByte(punct, c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the version in the format accepted by ParseDebian.
+func (v DebianVersion) String() string {
+	var s string
+	if v.Epoch != 0 {
+		s = strconv.Itoa(v.Epoch) + ":"
+	}
+	s += v.Upstream
+	if v.Revision != "" {
+		s += "-" + v.Revision
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, the same
+// as or after other, following the ordering used by dpkg.
+func (v DebianVersion) Compare(other DebianVersion) int {
+	if v.Epoch != other.Epoch {
+		return sign(v.Epoch - other.Epoch)
+	}
+	if res := debianVerRevCmp(v.Upstream, other.Upstream); res != 0 {
+		return res
+	}
+	return debianVerRevCmp(v.Revision, other.Revision)
+}
+
+// debianOrder returns the weight of a character in a non-digit part of a
+// deb version: a tilde sorts before anything, even the end of the part,
+// and letters sort before all other punctuation.
+func debianOrder(s string, i int) int {
+	switch {
+	case i >= len(s) || isDigit(s[i]):
+		return 0
+	case isAlpha(s[i]):
+		return int(s[i])
+	case s[i] == '~':
+		return -1
+	}
+	return int(s[i]) + 256
+}
+
+// debianVerRevCmp compares the upstream version or Debian revision parts
+// of two deb versions, by alternately comparing their non-digit parts
+// lexically and their digit parts numerically.
+func debianVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := debianOrder(a, i), debianOrder(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// debianScheme is the Scheme for deb package versions.
+type debianScheme struct{}
+
+// Compare is defined on the Scheme interface.
+func (debianScheme) Compare(a, b string) (int, error) {
+	va, err := ParseDebian(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseDebian(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/version"
+)
+
+var _ = gc.Suite(&DebianSuite{})
+
+type DebianSuite struct{}
+
+var debianCompareTests = []struct {
+	a, b     string
+	expected int
+}{
+	{"1.0", "1.0", 0},
+	{"1.0", "1.1", -1},
+	{"1.2.3", "1.2", 1},
+	{"2.30", "2.4", 1},
+	{"1.001", "1.1", 0},
+	{"1.0.0", "1.0", 1},
+	{"1.0~rc1", "1.0", -1},
+	{"1.0~rc1", "1.0~rc2", -1},
+	{"1.0~~", "1.0~~a", -1},
+	{"1.0~~a", "1.0~", -1},
+	{"1.0~", "1.0", -1},
+	{"1.0", "1.0a", -1},
+	{"1.0a", "1.0+", -1},
+	{"1.0+dfsg", "1.0", 1},
+	{"1:1.0", "2.0", 1},
+	{"0:1.0", "1.0", 0},
+	{"1.0-1", "1.0-2", -1},
+	{"1.0-1ubuntu1", "1.0-1", 1},
+	{"1.0-0", "1.0", 0},
+	{"1.0-1~bpo1", "1.0-1", -1},
+	{"5.0-6ubuntu1.2", "5.0-6ubuntu1.1", 1},
+	{"2.31-0ubuntu9.9", "2.31-0ubuntu9.10", -1},
+	{"1.2-3-4", "1.2-3-5", -1},
+	{"1:2.3:4-5", "1:2.3:4-5", 0},
+}
+
+func (s *DebianSuite) TestCompare(c *gc.C) {
+	for i, test := range debianCompareTests {
+		c.Logf("test %d: %q vs %q", i, test.a, test.b)
+
+		res, err := version.DebianScheme.Compare(test.a, test.b)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, test.expected)
+
+		res, err = version.DebianScheme.Compare(test.b, test.a)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, -test.expected)
+	}
+}
+
+func (s *DebianSuite) TestParse(c *gc.C) {
+	v, err := version.ParseDebian("1:2.30-0ubuntu9.9")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, jc.DeepEquals, version.DebianVersion{
+		Epoch:    1,
+		Upstream: "2.30",
+		Revision: "0ubuntu9.9",
+	})
+	c.Assert(v.String(), gc.Equals, "1:2.30-0ubuntu9.9")
+
+	v, err = version.ParseDebian("1.0+dfsg")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, jc.DeepEquals, version.DebianVersion{Upstream: "1.0+dfsg"})
+	c.Assert(v.String(), gc.Equals, "1.0+dfsg")
+}
+
+func (s *DebianSuite) TestParseInvalid(c *gc.C) {
+	for i, v := range []string{
+		"",
+		"1:",
+		"a:1.0",
+		"-1:1.0",
+		"abc",
+		"1.0-",
+		"1.0 1",
+		"1.0-1_2",
+	} {
+		c.Logf("test %d: %q", i, v)
+		_, err := version.ParseDebian(v)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *DebianSuite) TestAtLeast(c *gc.C) {
+	ok, err := version.AtLeast(version.DebianScheme, "5.0-6ubuntu1.2", "5.0-6ubuntu1.2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsTrue)
+
+	ok, err = version.AtLeast(version.DebianScheme, "5.0~rc1", "5.0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ok, jc.IsFalse)
+
+	_, err = version.AtLeast(version.DebianScheme, "5.0", "")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -0,0 +1,207 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// RPMVersion is a version of an rpm package, in the
+// [epoch:]version[-release] format.
+type RPMVersion struct {
+	// Epoch is the epoch of the version, which is zero if omitted.
+	Epoch int
+
+	// Version is the version of the packaged software.
+	Version string
+
+	// Release is the release of the package, which may be omitted when
+	// the version is used as a constraint.
+	Release string
+}
+
+// ParseRPM parses the given rpm package version.
+func ParseRPM(v string) (RPMVersion, error) {
+	var res RPMVersion
+	s := strings.TrimSpace(v)
+
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		epoch, err := strconv.Atoi(s[:i])
+		if err != nil || epoch < 0 {
+			return res, errors.NotValidf("rpm version %q epoch", v)
+		}
+		res.Epoch = epoch
+		s = s[i+1:]
+	}
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		res.Release = s[i+1:]
+		s = s[:i]
+		if res.Release == "" {
+			return res, errors.NotValidf("rpm version %q release", v)
+		}
+	}
+	res.Version = s
+	if res.Version == "" || strings.ContainsAny(v, " \t\n") {
+		return res, errors.NotValidf("rpm version %q", v)
+	}
+	return res, nil
+}
+
+// String returns the version in the format accepted by ParseRPM.
+func (v RPMVersion) String() string {
+	var s string
+	if v.Epoch != 0 {
+		s = strconv.Itoa(v.Epoch) + ":"
+	}
+	s += v.Version
+	if v.Release != "" {
+		s += "-" + v.Release
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, the same
+// as or after other, following the ordering used by rpm. As with rpm, the
+// releases are only compared if both versions have one.
+func (v RPMVersion) Compare(other RPMVersion) int {
+	if v.Epoch != other.Epoch {
+		return sign(v.Epoch - other.Epoch)
+	}
+	if res := rpmvercmp(v.Version, other.Version); res != 0 {
+		return res
+	}
+	if v.Release == "" || other.Release == "" {
+		return 0
+	}
+	return rpmvercmp(v.Release, other.Release)
+}
+
+// rpmvercmp compares two version or release strings in the same way as
+// the function of the same name in rpm: the strings are split into
+// alternating alphabetic and numeric segments, separators are ignored, a
+// tilde sorts before anything and a caret sorts after the end of a string
+// but before anything else.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	isSegment := func(c byte) bool {
+		return isDigit(c) || isAlpha(c) || c == '~' || c == '^'
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isSegment(a[i]) {
+			i++
+		}
+		for j < len(b) && !isSegment(b[j]) {
+			j++
+		}
+
+		// A tilde sorts before everything else.
+		ta, tb := i < len(a) && a[i] == '~', j < len(b) && b[j] == '~'
+		if ta || tb {
+			if !ta {
+				return 1
+			}
+			if !tb {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// A caret sorts after the end of the string, but before anything
+		// else.
+		ca, cb := i < len(a) && a[i] == '^', j < len(b) && b[j] == '^'
+		if ca || cb {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if !ca {
+				return 1
+			}
+			if !cb {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		// Grab the next completely numeric or alphabetic segment of both
+		// strings; segments of a different type sort numeric first.
+		si, sj := i, j
+		numeric := isDigit(a[i])
+		if numeric {
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isAlpha(a[i]) {
+				i++
+			}
+			for j < len(b) && isAlpha(b[j]) {
+				j++
+			}
+		}
+		segA, segB := a[si:i], b[sj:j]
+		if segB == "" {
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+
+		if numeric {
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return sign(len(segA) - len(segB))
+			}
+		}
+		if res := strings.Compare(segA, segB); res != 0 {
+			return res
+		}
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i < len(a):
+		return 1
+	}
+	return -1
+}
+
+// rpmScheme is the Scheme for rpm package versions.
+type rpmScheme struct{}
+
+// Compare is defined on the Scheme interface.
+func (rpmScheme) Compare(a, b string) (int, error) {
+	va, err := ParseRPM(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := ParseRPM(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
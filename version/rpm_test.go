@@ -0,0 +1,96 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/version"
+)
+
+var _ = gc.Suite(&RPMSuite{})
+
+type RPMSuite struct{}
+
+// rpmCompareTests are taken from the rpmvercmp conformance tests of rpm.
+var rpmCompareTests = []struct {
+	a, b     string
+	expected int
+}{
+	{"1.0", "1.0", 0},
+	{"1.0", "2.0", -1},
+	{"2.0.1", "2.0", 1},
+	{"2.0.1a", "2.0.1", 1},
+	{"5.5p1", "5.5p2", -1},
+	{"5.5p10", "5.5p1", 1},
+	{"10xyz", "10.1xyz", -1},
+	{"xyz10", "xyz10.1", -1},
+	{"xyz.4", "8", -1},
+	{"2.0a", "2.0.a", 0},
+	{"6.0.rc1", "6.0", 1},
+	{"10b2", "10a1", 1},
+	{"1_0", "1.0", 0},
+	{"1.0010", "1.9", 1},
+	{"1.05", "1.5", 0},
+	{"1.0~rc1", "1.0", -1},
+	{"1.0~rc1", "1.0~rc2", -1},
+	{"1.0~rc1~git123", "1.0~rc1", -1},
+	{"1.0^", "1.0", 1},
+	{"1.0^git1", "1.0^git2", -1},
+	{"1.0^git1", "1.01", -1},
+	{"1.0^20160101", "1.0.1", -1},
+	{"1.0~rc1^git1", "1.0~rc1", 1},
+	{"1.0^git1~pre", "1.0^git1", -1},
+	{"1:1.0-1", "2.0-1", 1},
+	{"0:1.0-1", "1.0-1", 0},
+	{"1.0-1.el7", "1.0-2.el7", -1},
+	{"4.2.46-34.el7", "4.2.46-35.el7_9", -1},
+	{"1.0", "1.0-5", 0},
+}
+
+func (s *RPMSuite) TestCompare(c *gc.C) {
+	for i, test := range rpmCompareTests {
+		c.Logf("test %d: %q vs %q", i, test.a, test.b)
+
+		res, err := version.RPMScheme.Compare(test.a, test.b)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, test.expected)
+
+		res, err = version.RPMScheme.Compare(test.b, test.a)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, -test.expected)
+	}
+}
+
+func (s *RPMSuite) TestParse(c *gc.C) {
+	v, err := version.ParseRPM("1:4.2.46-35.el7_9")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, jc.DeepEquals, version.RPMVersion{
+		Epoch:   1,
+		Version: "4.2.46",
+		Release: "35.el7_9",
+	})
+	c.Assert(v.String(), gc.Equals, "1:4.2.46-35.el7_9")
+
+	v, err = version.ParseRPM("4.2.46")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(v, jc.DeepEquals, version.RPMVersion{Version: "4.2.46"})
+}
+
+func (s *RPMSuite) TestParseInvalid(c *gc.C) {
+	for i, v := range []string{
+		"",
+		"1:",
+		"a:1.0",
+		"1.0-",
+		"-1",
+		"1.0 1",
+	} {
+		c.Logf("test %d: %q", i, v)
+		_, err := version.ParseRPM(v)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
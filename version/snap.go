@@ -0,0 +1,73 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// SnapRevision is a revision of a snap. Revisions of snaps from the store
+// are positive integers, whereas revisions of locally installed snaps are
+// an "x" followed by a positive integer.
+type SnapRevision struct {
+	// N is the number of the revision.
+	N int
+
+	// Local is true for revisions of locally installed snaps.
+	Local bool
+}
+
+// ParseSnapRevision parses the given snap revision.
+func ParseSnapRevision(v string) (SnapRevision, error) {
+	var res SnapRevision
+	s := strings.TrimSpace(v)
+	if strings.HasPrefix(s, "x") {
+		res.Local = true
+		s = s[1:]
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 || strings.HasPrefix(s, "+") {
+		return res, errors.NotValidf("snap revision %q", v)
+	}
+	res.N = n
+	return res, nil
+}
+
+// String returns the revision in the format accepted by ParseSnapRevision.
+func (r SnapRevision) String() string {
+	if r.Local {
+		return "x" + strconv.Itoa(r.N)
+	}
+	return strconv.Itoa(r.N)
+}
+
+// Compare returns -1, 0 or 1 depending on whether r is older than, the
+// same as or newer than other. Revisions of local snaps and store snaps
+// are unrelated, so an error satisfying errors.IsNotValid is returned when
+// comparing one with the other.
+func (r SnapRevision) Compare(other SnapRevision) (int, error) {
+	if r.Local != other.Local {
+		return 0, errors.NotValidf("comparing local snap revision with store snap revision")
+	}
+	return sign(r.N - other.N), nil
+}
+
+// snapScheme is the Scheme for snap revisions.
+type snapScheme struct{}
+
+// Compare is defined on the Scheme interface.
+func (snapScheme) Compare(a, b string) (int, error) {
+	ra, err := ParseSnapRevision(a)
+	if err != nil {
+		return 0, err
+	}
+	rb, err := ParseSnapRevision(b)
+	if err != nil {
+		return 0, err
+	}
+	return ra.Compare(rb)
+}
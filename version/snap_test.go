@@ -0,0 +1,65 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package version_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/packaging/v3/version"
+)
+
+var _ = gc.Suite(&SnapSuite{})
+
+type SnapSuite struct{}
+
+func (s *SnapSuite) TestCompare(c *gc.C) {
+	for i, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1", "1", 0},
+		{"1", "2", -1},
+		{"10", "9", 1},
+		{"x1", "x2", -1},
+		{"x10", "x10", 0},
+	} {
+		c.Logf("test %d: %q vs %q", i, test.a, test.b)
+
+		res, err := version.SnapScheme.Compare(test.a, test.b)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, test.expected)
+
+		res, err = version.SnapScheme.Compare(test.b, test.a)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(res, gc.Equals, -test.expected)
+	}
+}
+
+func (s *SnapSuite) TestCompareLocalWithStore(c *gc.C) {
+	_, err := version.SnapScheme.Compare("x1", "8594")
+	c.Assert(err, gc.ErrorMatches, "comparing local snap revision with store snap revision not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *SnapSuite) TestParse(c *gc.C) {
+	r, err := version.ParseSnapRevision("8594")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(r, jc.DeepEquals, version.SnapRevision{N: 8594})
+	c.Assert(r.String(), gc.Equals, "8594")
+
+	r, err = version.ParseSnapRevision("x3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(r, jc.DeepEquals, version.SnapRevision{N: 3, Local: true})
+	c.Assert(r.String(), gc.Equals, "x3")
+}
+
+func (s *SnapSuite) TestParseInvalid(c *gc.C) {
+	for i, v := range []string{"", "0", "-1", "+1", "x", "x0", "abc", "2.6.6"} {
+		c.Logf("test %d: %q", i, v)
+		_, err := version.ParseSnapRevision(v)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package version parses and compares package versions in the formats used
+// by the various package management systems, without shelling out to tools
+// such as dpkg or rpm.
+package version
+
+// Scheme compares versions in the format of a particular package
+// management system.
+type Scheme interface {
+	// Compare returns -1, 0 or 1 depending on whether version a sorts
+	// before, the same as or after version b. An error satisfying
+	// errors.IsNotValid is returned if either version cannot be parsed.
+	Compare(a, b string) (int, error)
+}
+
+var (
+	// DebianScheme compares versions of deb packages.
+	DebianScheme Scheme = debianScheme{}
+
+	// RPMScheme compares versions of rpm packages.
+	RPMScheme Scheme = rpmScheme{}
+
+	// SnapScheme compares snap revisions.
+	SnapScheme Scheme = snapScheme{}
+)
+
+// AtLeast returns whether version v sorts the same as or after min
+// according to the given scheme.
+func AtLeast(scheme Scheme, v, min string) (bool, error) {
+	res, err := scheme.Compare(v, min)
+	if err != nil {
+		return false, err
+	}
+	return res >= 0, nil
+}
+
+// sign normalises the result of a comparison to -1, 0 or 1.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// isDigit returns whether the given byte is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isAlpha returns whether the given byte is an ASCII letter.
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}